@@ -31,6 +31,35 @@ const (
 	Unknown Result = "unknown"
 )
 
+// FailureReason classifies why a probe did not succeed.
+type FailureReason string
+
+const (
+	// ReasonConnectionFailed means the target could not be reached.
+	ReasonConnectionFailed FailureReason = "ConnectionFailed"
+	// ReasonTLSHandshakeFailed means the TLS handshake or certificate verification failed.
+	ReasonTLSHandshakeFailed FailureReason = "TLSHandshakeFailed"
+	// ReasonEmptyResponse means the target accepted the connection, but closed it without sending a response.
+	ReasonEmptyResponse FailureReason = "EmptyResponse"
+	// ReasonRedirectLoop means the target redirected back to a URL the probe already requested.
+	ReasonRedirectLoop FailureReason = "RedirectLoop"
+	// ReasonMalformedRedirect means the target responded with a redirect status, but without a Location header.
+	ReasonMalformedRedirect FailureReason = "MalformedRedirect"
+	// ReasonBodyReadFailed means the response body could not be read.
+	ReasonBodyReadFailed FailureReason = "BodyReadFailed"
+	// ReasonUnexpectedStatus means the target responded with an unsuccessful status.
+	ReasonUnexpectedStatus FailureReason = "UnexpectedStatus"
+	// ReasonAssertionFailed means the target responded, but a configured assertion did not hold.
+	ReasonAssertionFailed FailureReason = "AssertionFailed"
+	// ReasonAuthenticationFailed means the probe could not authenticate, or the target rejected its credentials.
+	ReasonAuthenticationFailed FailureReason = "AuthenticationFailed"
+)
+
+// ErrorClassifier maps an error that ends a probe, such as a failed connect, to the result and
+// failure reason of the probe. It lets users override the default mapping of the probers, e.g. to
+// report a refused connection as Warning. It must be safe for concurrent use.
+type ErrorClassifier func(err error) (Result, FailureReason)
+
 const (
 	DefaultProbeTimeout = time.Minute * 5
 )
@@ -0,0 +1,85 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// ErrLocalPortsInUse is returned by the dialer of a LocalPortRange when no port of the range is free.
+// Probes report it as Unknown, as it says nothing about the target.
+var ErrLocalPortsInUse = errors.New("all local ports are in use")
+
+// LocalPortRange binds the connections of a probe to a local TCP port from First to Last, inclusive,
+// e.g. for stateful firewall rules keyed off the client source port.
+type LocalPortRange struct {
+	First int
+	// Last defaults to First, for a single port.
+	Last int
+}
+
+// Dialer returns a dialer that binds to the first free port of the range. The other settings of d are kept.
+func (r *LocalPortRange) Dialer(d *net.Dialer) *BoundDialer {
+	b := &BoundDialer{ports: *r}
+	if d != nil {
+		b.dialer = *d
+	}
+	return b
+}
+
+func (r *LocalPortRange) String() string {
+	if r.Last <= r.First {
+		return fmt.Sprintf("local port %d", r.First)
+	}
+	return fmt.Sprintf("local ports %d-%d", r.First, r.Last)
+}
+
+// BoundDialer dials from a port of a LocalPortRange.
+type BoundDialer struct {
+	ports  LocalPortRange
+	dialer net.Dialer
+}
+
+// Dial connects to addr from the first free port of the range.
+func (b *BoundDialer) Dial(network, addr string) (net.Conn, error) {
+	return b.DialContext(context.Background(), network, addr)
+}
+
+// DialContext connects to addr from the first free port of the range. Ports that are in use, or
+// still bound to a previous connection to addr, are skipped.
+func (b *BoundDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	last := b.ports.Last
+	if last < b.ports.First {
+		last = b.ports.First
+	}
+	for port := b.ports.First; port <= last; port++ {
+		d := b.dialer
+		d.LocalAddr = &net.TCPAddr{Port: port}
+		conn, err := d.DialContext(ctx, network, addr)
+		if err == nil {
+			return conn, nil
+		}
+		if !errors.Is(err, syscall.EADDRINUSE) && !errors.Is(err, syscall.EADDRNOTAVAIL) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrLocalPortsInUse, &b.ports)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+
+	"golang.org/x/net/proxy"
+)
+
+// SOCKS5Proxy routes the connections of a probe through a SOCKS5 proxy.
+type SOCKS5Proxy struct {
+	// Address is the host:port of the proxy.
+	Address string
+	// Username and Password are the optional proxy credentials.
+	Username string
+	Password string
+}
+
+// Dialer returns a dialer that connects through the proxy.
+// forward is used to reach the proxy itself; nil means a plain net.Dialer.
+func (p *SOCKS5Proxy) Dialer(forward proxy.Dialer) (proxy.ContextDialer, error) {
+	var auth *proxy.Auth
+	if p.Username != "" || p.Password != "" {
+		auth = &proxy.Auth{User: p.Username, Password: p.Password}
+	}
+	if forward == nil {
+		forward = &net.Dialer{}
+	}
+	d, err := proxy.SOCKS5("tcp", p.Address, auth, forward)
+	if err != nil {
+		return nil, err
+	}
+	cd, ok := d.(proxy.ContextDialer)
+	if !ok {
+		return nil, fmt.Errorf("socks5 dialer for %s does not support contexts", p.Address)
+	}
+	return cd, nil
+}
+
+// String implements fmt.Stringer so that the password is redacted if the proxy is ever printed.
+func (p *SOCKS5Proxy) String() string {
+	if p.Username == "" && p.Password == "" {
+		return fmt.Sprintf("socks5://%s", p.Address)
+	}
+	return fmt.Sprintf("socks5://%s:<redacted>@%s", p.Username, p.Address)
+}
+
+// HTTPProxy routes the requests of a probe through an HTTP forward proxy.
+// HTTPS targets are tunneled with CONNECT.
+type HTTPProxy struct {
+	// URL is the address of the proxy, e.g. http://proxy.example.com:3128.
+	URL *url.URL
+	// Username and Password are the optional proxy credentials. They are sent as
+	// Basic Proxy-Authorization and take precedence over credentials in URL.
+	Username string
+	Password string
+}
+
+// ProxyURL returns the proxy URL including the credentials, as expected by http.ProxyURL.
+func (p *HTTPProxy) ProxyURL() *url.URL {
+	u := *p.URL
+	if p.Username != "" || p.Password != "" {
+		u.User = url.UserPassword(p.Username, p.Password)
+	}
+	return &u
+}
+
+// String implements fmt.Stringer so that the password is redacted if the proxy is ever printed.
+func (p *HTTPProxy) String() string {
+	if p.URL == nil {
+		return ""
+	}
+	return p.ProxyURL().Redacted()
+}
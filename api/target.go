@@ -0,0 +1,119 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+)
+
+// Target holds the union of the inputs needed by the different probe kinds.
+// Each kind only reads the fields it cares about.
+type Target struct {
+	// URL is the endpoint for HTTP probes.
+	URL *url.URL
+	// Headers are the request headers for HTTP probes.
+	Headers http.Header
+	// SensitiveHeaders are the names of Headers whose values are secrets, e.g. expanded from environment
+	// variables. HTTP probes redact their values in logs and in the transcripts of Options.Recorder.
+	SensitiveHeaders []string
+	// Form is the url encoded form sent by HTTP POST probes.
+	Form url.Values
+	// Body is the raw request body sent by HTTP POST probes.
+	Body string
+	// Trailers are sent after the body of HTTP POST probes. They override the trailers of the HTTP prober when set.
+	Trailers http.Header
+	// SigningKey and SignatureHeader sign the body of HTTP POST probes with an HMAC-SHA256. They override
+	// the signer of the HTTP prober when SigningKey is set. SigningKey is never logged.
+	SigningKey      []byte
+	SignatureHeader string
+	// SigningKeySecret references the key of a Secret in the namespace of Pod that holds the SigningKey.
+	// probe.Prober reads it for every probe, caching the Secret for a short time.
+	SigningKeySecret *core.SecretKeySelector
+	// ExpectStatusText must match the status line of the response to HTTP probes. It overrides the one of
+	// the HTTP prober when set.
+	ExpectStatusText *regexp.Regexp
+	// ForbidResponseHeaders are the headers the response to HTTP probes must not carry, in addition to those
+	// forbidden by the HTTP prober.
+	ForbidResponseHeaders []string
+	// ExpectBodySHA256 is the hex encoded SHA-256 the body of the response to HTTP probes must have.
+	// It overrides the one of the HTTP prober when set.
+	ExpectBodySHA256 string
+	// CloseConnection sends the requests of HTTP probes with "Connection: close", whatever the options
+	// of the HTTP prober.
+	CloseConnection bool
+	// ExpectServerContains is text the Server header of the response to HTTP probes must contain. It overrides
+	// the one of the HTTP prober when set.
+	ExpectServerContains string
+	// ExpectCharset is the charset the response to HTTP probes must declare. It overrides the one of the
+	// HTTP prober when set. RequireValidUTF8 requires a valid UTF-8 body, whatever the options of the HTTP prober.
+	ExpectCharset    string
+	RequireValidUTF8 bool
+
+	// Host and Port are the address for TCP and gRPC probes. DNS probes resolve Host.
+	Host string
+	Port int
+	// Service is the service name checked by gRPC health probes. Empty checks the server as a whole.
+	Service string
+	// Protocol selects the check of the application protocol that TCP probes run over their connection,
+	// e.g. "redis". Username and Password authenticate it. They override the options of the TCP prober when set.
+	Protocol string
+	Username string
+	Password string
+	// PasswordSecret references the key of a Secret in the namespace of Pod that holds the Password.
+	// probe.Prober reads it for every probe, caching the Secret for a short time.
+	PasswordSecret *core.SecretKeySelector
+
+	// Config, Pod, ContainerName and Command are used by exec probes.
+	// HTTP probes use Pod to render templated assertions. For all kinds, Pod is the
+	// object that probe.Prober records Events against.
+	Config        *rest.Config
+	Pod           *core.Pod
+	ContainerName string
+	Command       []string
+
+	// Timeout bounds the duration of a single probe.
+	Timeout time.Duration
+}
+
+// Prober is implemented by every probe kind so that they can be handled polymorphically.
+type Prober interface {
+	Probe(ctx context.Context, target Target) (Result, string, error)
+}
+
+// minTimeout is the timeout of a probe whose context deadline has already passed. The probers treat zero
+// as no timeout, and negative timeouts are not valid for net and net/http, so the probe gets a timeout that
+// expires right away instead.
+const minTimeout = time.Millisecond
+
+// TimeoutFor returns the timeout to use for target, shortened to the deadline of ctx if that comes first.
+// If the deadline has already passed, it returns a tiny positive timeout, so that the probe times out at once.
+func TimeoutFor(ctx context.Context, target Target) time.Duration {
+	timeout := target.Timeout
+	if deadline, ok := ctx.Deadline(); ok {
+		if remaining := time.Until(deadline); timeout <= 0 || remaining < timeout {
+			timeout = max(remaining, minTimeout)
+		}
+	}
+	return timeout
+}
@@ -0,0 +1,50 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTimeoutFor(t *testing.T) {
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	soon, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	tests := map[string]struct {
+		ctx     context.Context
+		timeout time.Duration
+		min     time.Duration
+		max     time.Duration
+	}{
+		"no deadline":          {context.Background(), time.Second, time.Second, time.Second},
+		"no timeout":           {context.Background(), 0, 0, 0},
+		"timeout first":        {soon, time.Second, time.Second, time.Second},
+		"deadline first":       {soon, time.Hour, time.Second, time.Minute},
+		"deadline, no timeout": {soon, 0, time.Second, time.Minute},
+		"deadline passed":      {expired, time.Second, minTimeout, minTimeout},
+	}
+	for name, tt := range tests {
+		got := TimeoutFor(tt.ctx, Target{Timeout: tt.timeout})
+		if got < tt.min || got > tt.max {
+			t.Errorf("%s: expected a timeout in [%v, %v], got %v", name, tt.min, tt.max, got)
+		}
+	}
+}
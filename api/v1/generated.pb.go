@@ -71,10 +71,66 @@ func (m *FormEntry) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_FormEntry proto.InternalMessageInfo
 
+func (m *HMACSignature) Reset()      { *m = HMACSignature{} }
+func (*HMACSignature) ProtoMessage() {}
+func (*HMACSignature) Descriptor() ([]byte, []int) {
+	return fileDescriptor_90c9649438138bbb, []int{1}
+}
+func (m *HMACSignature) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *HMACSignature) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	b = b[:cap(b)]
+	n, err := m.MarshalToSizedBuffer(b)
+	if err != nil {
+		return nil, err
+	}
+	return b[:n], nil
+}
+func (m *HMACSignature) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HMACSignature.Merge(m, src)
+}
+func (m *HMACSignature) XXX_Size() int {
+	return m.Size()
+}
+func (m *HMACSignature) XXX_DiscardUnknown() {
+	xxx_messageInfo_HMACSignature.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HMACSignature proto.InternalMessageInfo
+
+func (m *HTTPGetOptions) Reset()      { *m = HTTPGetOptions{} }
+func (*HTTPGetOptions) ProtoMessage() {}
+func (*HTTPGetOptions) Descriptor() ([]byte, []int) {
+	return fileDescriptor_90c9649438138bbb, []int{2}
+}
+func (m *HTTPGetOptions) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *HTTPGetOptions) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	b = b[:cap(b)]
+	n, err := m.MarshalToSizedBuffer(b)
+	if err != nil {
+		return nil, err
+	}
+	return b[:n], nil
+}
+func (m *HTTPGetOptions) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HTTPGetOptions.Merge(m, src)
+}
+func (m *HTTPGetOptions) XXX_Size() int {
+	return m.Size()
+}
+func (m *HTTPGetOptions) XXX_DiscardUnknown() {
+	xxx_messageInfo_HTTPGetOptions.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_HTTPGetOptions proto.InternalMessageInfo
+
 func (m *HTTPPostAction) Reset()      { *m = HTTPPostAction{} }
 func (*HTTPPostAction) ProtoMessage() {}
 func (*HTTPPostAction) Descriptor() ([]byte, []int) {
-	return fileDescriptor_90c9649438138bbb, []int{1}
+	return fileDescriptor_90c9649438138bbb, []int{3}
 }
 func (m *HTTPPostAction) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -102,7 +158,7 @@ var xxx_messageInfo_HTTPPostAction proto.InternalMessageInfo
 func (m *Handler) Reset()      { *m = Handler{} }
 func (*Handler) ProtoMessage() {}
 func (*Handler) Descriptor() ([]byte, []int) {
-	return fileDescriptor_90c9649438138bbb, []int{2}
+	return fileDescriptor_90c9649438138bbb, []int{4}
 }
 func (m *Handler) XXX_Unmarshal(b []byte) error {
 	return m.Unmarshal(b)
@@ -127,10 +183,70 @@ func (m *Handler) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_Handler proto.InternalMessageInfo
 
+func (m *TCPCredentials) Reset()      { *m = TCPCredentials{} }
+func (*TCPCredentials) ProtoMessage() {}
+func (*TCPCredentials) Descriptor() ([]byte, []int) {
+	return fileDescriptor_90c9649438138bbb, []int{5}
+}
+func (m *TCPCredentials) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TCPCredentials) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	b = b[:cap(b)]
+	n, err := m.MarshalToSizedBuffer(b)
+	if err != nil {
+		return nil, err
+	}
+	return b[:n], nil
+}
+func (m *TCPCredentials) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TCPCredentials.Merge(m, src)
+}
+func (m *TCPCredentials) XXX_Size() int {
+	return m.Size()
+}
+func (m *TCPCredentials) XXX_DiscardUnknown() {
+	xxx_messageInfo_TCPCredentials.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TCPCredentials proto.InternalMessageInfo
+
+func (m *TCPSocketOptions) Reset()      { *m = TCPSocketOptions{} }
+func (*TCPSocketOptions) ProtoMessage() {}
+func (*TCPSocketOptions) Descriptor() ([]byte, []int) {
+	return fileDescriptor_90c9649438138bbb, []int{6}
+}
+func (m *TCPSocketOptions) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *TCPSocketOptions) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	b = b[:cap(b)]
+	n, err := m.MarshalToSizedBuffer(b)
+	if err != nil {
+		return nil, err
+	}
+	return b[:n], nil
+}
+func (m *TCPSocketOptions) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_TCPSocketOptions.Merge(m, src)
+}
+func (m *TCPSocketOptions) XXX_Size() int {
+	return m.Size()
+}
+func (m *TCPSocketOptions) XXX_DiscardUnknown() {
+	xxx_messageInfo_TCPSocketOptions.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_TCPSocketOptions proto.InternalMessageInfo
+
 func init() {
 	proto.RegisterType((*FormEntry)(nil), "kmodules.xyz.prober.api.v1.FormEntry")
+	proto.RegisterType((*HMACSignature)(nil), "kmodules.xyz.prober.api.v1.HMACSignature")
+	proto.RegisterType((*HTTPGetOptions)(nil), "kmodules.xyz.prober.api.v1.HTTPGetOptions")
 	proto.RegisterType((*HTTPPostAction)(nil), "kmodules.xyz.prober.api.v1.HTTPPostAction")
 	proto.RegisterType((*Handler)(nil), "kmodules.xyz.prober.api.v1.Handler")
+	proto.RegisterType((*TCPCredentials)(nil), "kmodules.xyz.prober.api.v1.TCPCredentials")
+	proto.RegisterType((*TCPSocketOptions)(nil), "kmodules.xyz.prober.api.v1.TCPSocketOptions")
 }
 
 func init() {
@@ -138,47 +254,78 @@ func init() {
 }
 
 var fileDescriptor_90c9649438138bbb = []byte{
-	// 630 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x54, 0xc1, 0x6e, 0xd3, 0x40,
-	0x10, 0x8d, 0x9b, 0x34, 0x69, 0xd6, 0x2d, 0x87, 0x45, 0x48, 0x56, 0x04, 0x76, 0x08, 0x42, 0x8a,
-	0x2a, 0xb1, 0xa6, 0xe1, 0x82, 0x04, 0x07, 0x70, 0xd5, 0x36, 0x15, 0x52, 0x89, 0xb6, 0x29, 0x07,
-	0x6e, 0x8e, 0xb3, 0xb5, 0xad, 0xc4, 0x5e, 0x6b, 0xbd, 0x89, 0x6a, 0x4e, 0x7c, 0x02, 0xdf, 0xc2,
-	0x57, 0xf4, 0xd8, 0x63, 0x4f, 0x16, 0x35, 0xff, 0xc0, 0x81, 0x13, 0xda, 0xb5, 0x93, 0x38, 0xd0,
-	0x70, 0xf3, 0xbe, 0x79, 0xf3, 0x26, 0x6f, 0xde, 0x28, 0x60, 0x7f, 0x12, 0xd0, 0xf1, 0x6c, 0x4a,
-	0x62, 0x74, 0x95, 0x7c, 0x31, 0x23, 0x46, 0x47, 0x84, 0x99, 0x76, 0xe4, 0x9b, 0xf3, 0x03, 0xd3,
-	0x25, 0x21, 0x61, 0x36, 0x27, 0x63, 0x14, 0x31, 0xca, 0x29, 0x6c, 0x95, 0xb9, 0x28, 0xe7, 0x22,
-	0x3b, 0xf2, 0xd1, 0xfc, 0xa0, 0xf5, 0xc2, 0xf5, 0xb9, 0x37, 0x1b, 0x21, 0x87, 0x06, 0xa6, 0x4b,
-	0x5d, 0x6a, 0xca, 0x96, 0xd1, 0xec, 0x52, 0xbe, 0xe4, 0x43, 0x7e, 0xe5, 0x52, 0xad, 0xce, 0xe4,
-	0x75, 0x8c, 0x7c, 0x2a, 0x27, 0x39, 0x94, 0x91, 0x7b, 0xc6, 0xb5, 0x5e, 0xad, 0x38, 0x81, 0xed,
-	0x78, 0x7e, 0x48, 0x58, 0x62, 0x46, 0x13, 0xd7, 0x9c, 0x71, 0x7f, 0x6a, 0xfa, 0x21, 0x8f, 0x39,
-	0xfb, 0xbb, 0xa9, 0x73, 0x06, 0x9a, 0xc7, 0x94, 0x05, 0x47, 0x21, 0x67, 0x09, 0x7c, 0x02, 0xaa,
-	0x13, 0x92, 0x68, 0x4a, 0x5b, 0xe9, 0x36, 0x2d, 0xf5, 0x3a, 0x35, 0x2a, 0x59, 0x6a, 0x54, 0x3f,
-	0x90, 0x04, 0x0b, 0x1c, 0x76, 0x40, 0x7d, 0x6e, 0x4f, 0x67, 0x24, 0xd6, 0xb6, 0xda, 0xd5, 0x6e,
-	0xd3, 0x02, 0x59, 0x6a, 0xd4, 0x3f, 0x49, 0x04, 0x17, 0x95, 0xce, 0xf7, 0x2a, 0x78, 0xd0, 0x1f,
-	0x0e, 0x07, 0x03, 0x1a, 0xf3, 0xf7, 0x0e, 0xf7, 0x69, 0x08, 0xdb, 0xa0, 0x16, 0xd9, 0xdc, 0x2b,
-	0x64, 0x77, 0x0b, 0xd9, 0xda, 0xc0, 0xe6, 0x1e, 0x96, 0x15, 0x88, 0x41, 0x2d, 0xa2, 0x8c, 0x6b,
-	0x5b, 0x6d, 0xa5, 0xab, 0xf6, 0x5e, 0xa2, 0xdc, 0x08, 0x2a, 0x1b, 0x41, 0xd1, 0xc4, 0x45, 0xc2,
-	0x08, 0xca, 0x8d, 0xa0, 0xd3, 0x90, 0x7f, 0x64, 0xe7, 0x9c, 0xf9, 0xa1, 0x5b, 0xd2, 0xa4, 0x8c,
-	0x63, 0xa9, 0x25, 0xa6, 0x7a, 0x34, 0xe6, 0x5a, 0x75, 0x7d, 0x6a, 0x9f, 0xc6, 0x1c, 0xcb, 0x0a,
-	0x3c, 0x06, 0xf5, 0xd8, 0xf1, 0x48, 0x40, 0xb4, 0x9a, 0xe4, 0xa0, 0x82, 0x53, 0x3f, 0x97, 0xe8,
-	0xef, 0xd4, 0x78, 0xfc, 0xef, 0xd6, 0xd1, 0x05, 0x3e, 0xcd, 0xeb, 0xb8, 0xe8, 0x86, 0x17, 0x40,
-	0xf5, 0x38, 0x8f, 0xfa, 0xc4, 0x1e, 0x13, 0x16, 0x6b, 0xdb, 0xed, 0x6a, 0x57, 0xed, 0xe9, 0x25,
-	0x13, 0x48, 0xf4, 0xa2, 0xf9, 0x01, 0x12, 0x8b, 0xc9, 0x69, 0xd6, 0xc3, 0x62, 0x98, 0xba, 0xc2,
-	0x62, 0x5c, 0xd6, 0x11, 0x06, 0x46, 0x74, 0x9c, 0x68, 0xf5, 0x75, 0x03, 0x16, 0x1d, 0x27, 0x58,
-	0x56, 0xe0, 0x09, 0xa8, 0x5d, 0x52, 0x16, 0x68, 0x0d, 0x39, 0xf1, 0x39, 0xda, 0x7c, 0x6e, 0x68,
-	0x99, 0xf1, 0x4a, 0x48, 0x40, 0x58, 0x0a, 0x74, 0x7e, 0x6d, 0x81, 0x46, 0xdf, 0x0e, 0xc7, 0x53,
-	0xc2, 0xe0, 0x5b, 0x50, 0x23, 0x57, 0xc4, 0x91, 0x69, 0x6d, 0xb0, 0x71, 0x74, 0x45, 0x9c, 0x3c,
-	0x5b, 0x6b, 0x47, 0x28, 0x89, 0x37, 0x96, 0x5d, 0xb0, 0x0f, 0x1a, 0xc2, 0xc3, 0x09, 0x59, 0x84,
-	0xf9, 0x74, 0xd3, 0x1e, 0x4e, 0x48, 0x71, 0x1f, 0x96, 0x9a, 0xa5, 0x46, 0xa3, 0x80, 0xf0, 0xa2,
-	0x1d, 0x0e, 0xc1, 0x8e, 0xf8, 0x1c, 0x2c, 0x32, 0x54, 0x7b, 0xfb, 0xff, 0x33, 0xb8, 0x7e, 0x73,
-	0xd6, 0x6e, 0x96, 0x1a, 0x3b, 0x0b, 0x0c, 0x2f, 0x95, 0xe0, 0x00, 0x34, 0xb9, 0x13, 0x9d, 0x53,
-	0x67, 0x42, 0xb8, 0x8c, 0x5d, 0xed, 0x3d, 0xbb, 0xef, 0x17, 0x0e, 0x0f, 0x07, 0x39, 0xa9, 0xd0,
-	0xdb, 0xcb, 0x52, 0xa3, 0xb9, 0x04, 0xf1, 0x4a, 0x04, 0xbe, 0x01, 0x7b, 0x0e, 0x0d, 0xb9, 0x2d,
-	0xae, 0xf4, 0xcc, 0x0e, 0x88, 0xb6, 0x2d, 0xf3, 0x7a, 0x54, 0xac, 0x79, 0xef, 0xb0, 0x5c, 0xc4,
-	0xeb, 0x5c, 0xeb, 0xdd, 0xf5, 0x9d, 0x5e, 0xb9, 0xb9, 0xd3, 0x2b, 0xb7, 0x77, 0x7a, 0xe5, 0x6b,
-	0xa6, 0x2b, 0xd7, 0x99, 0xae, 0xdc, 0x64, 0xba, 0x72, 0x9b, 0xe9, 0xca, 0x8f, 0x4c, 0x57, 0xbe,
-	0xfd, 0xd4, 0x2b, 0x9f, 0x5b, 0x9b, 0xff, 0x73, 0xfe, 0x04, 0x00, 0x00, 0xff, 0xff, 0x76, 0x37,
-	0xcb, 0x78, 0x90, 0x04, 0x00, 0x00,
+	// 1123 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x56, 0x4b, 0x6f, 0xe3, 0x44,
+	0x1c, 0x6f, 0xda, 0x6c, 0x1e, 0xe3, 0xbe, 0x76, 0x68, 0x85, 0xb7, 0x5a, 0x92, 0x12, 0xb4, 0xa8,
+	0xac, 0x16, 0x87, 0x06, 0x81, 0x56, 0x82, 0x03, 0x75, 0x68, 0x9b, 0x55, 0x61, 0x1b, 0x4d, 0xd2,
+	0x1e, 0x90, 0x10, 0x72, 0x9d, 0x7f, 0x13, 0x2b, 0x89, 0xc7, 0xcc, 0x4c, 0x4a, 0xc3, 0x89, 0x0b,
+	0x77, 0x8e, 0xdc, 0xf8, 0x06, 0x7c, 0x8e, 0x5e, 0x40, 0x7b, 0xdc, 0x53, 0x44, 0xcd, 0xb7, 0xe0,
+	0x84, 0x66, 0x3c, 0x71, 0x9e, 0x2d, 0x5b, 0xd1, 0x9b, 0xfd, 0x7f, 0xfc, 0xfe, 0xcf, 0xf9, 0xcd,
+	0xa0, 0xa7, 0xed, 0x2e, 0x6d, 0xf4, 0x3a, 0xc0, 0xad, 0xcb, 0xfe, 0x8f, 0xc5, 0x80, 0xd1, 0x33,
+	0x60, 0x45, 0x27, 0xf0, 0x8a, 0x17, 0xbb, 0xc5, 0x26, 0xf8, 0xc0, 0x1c, 0x01, 0x0d, 0x2b, 0x60,
+	0x54, 0x50, 0xbc, 0x35, 0x6e, 0x6b, 0x45, 0xb6, 0x96, 0x13, 0x78, 0xd6, 0xc5, 0xee, 0xd6, 0x87,
+	0x4d, 0x4f, 0xb4, 0x7a, 0x67, 0x96, 0x4b, 0xbb, 0xc5, 0x26, 0x6d, 0xd2, 0xa2, 0x72, 0x39, 0xeb,
+	0x9d, 0xab, 0x3f, 0xf5, 0xa3, 0xbe, 0x22, 0xa8, 0xad, 0x42, 0xfb, 0x39, 0xb7, 0x3c, 0xaa, 0x22,
+	0xb9, 0x94, 0xc1, 0x9c, 0x70, 0x5b, 0x1f, 0x8f, 0x6c, 0xba, 0x8e, 0xdb, 0xf2, 0x7c, 0x60, 0xfd,
+	0x62, 0xd0, 0x6e, 0x16, 0x7b, 0xc2, 0xeb, 0x14, 0x3d, 0x5f, 0x70, 0xc1, 0xa6, 0x9d, 0x0a, 0x2f,
+	0x51, 0xf6, 0x80, 0xb2, 0xee, 0xbe, 0x2f, 0x58, 0x1f, 0xbf, 0x83, 0x96, 0xda, 0xd0, 0x37, 0x13,
+	0xdb, 0x89, 0x9d, 0xac, 0x6d, 0x5c, 0x0d, 0xf2, 0x0b, 0xe1, 0x20, 0xbf, 0x74, 0x04, 0x7d, 0x22,
+	0xe5, 0xb8, 0x80, 0x52, 0x17, 0x4e, 0xa7, 0x07, 0xdc, 0x5c, 0xdc, 0x5e, 0xda, 0xc9, 0xda, 0x28,
+	0x1c, 0xe4, 0x53, 0xa7, 0x4a, 0x42, 0xb4, 0xa6, 0xf0, 0x6b, 0x02, 0xad, 0x54, 0xbe, 0xde, 0x2b,
+	0xd7, 0xbc, 0xa6, 0xef, 0x88, 0x1e, 0x03, 0xfc, 0x1d, 0x5a, 0x6e, 0x43, 0xbf, 0x06, 0x2e, 0x03,
+	0x41, 0xe0, 0x5c, 0xa1, 0x1b, 0xa5, 0x27, 0x56, 0x94, 0xad, 0xea, 0x87, 0xac, 0xc8, 0xba, 0xd8,
+	0xb5, 0x22, 0xa3, 0x23, 0x69, 0xdd, 0x01, 0x57, 0x50, 0x66, 0x6f, 0xe8, 0x24, 0x96, 0x8f, 0xc6,
+	0x20, 0xc8, 0x04, 0x20, 0x7e, 0x1f, 0xa5, 0x5a, 0xe0, 0x34, 0x80, 0x99, 0x8b, 0x2a, 0xf1, 0x55,
+	0xed, 0x93, 0xaa, 0x28, 0x29, 0xd1, 0xda, 0xc2, 0xcf, 0x49, 0xb4, 0x5a, 0xa9, 0xd7, 0xab, 0x87,
+	0x20, 0x8e, 0x03, 0xe1, 0x51, 0x9f, 0xe3, 0x2f, 0xd1, 0x3a, 0x5c, 0x06, 0xe0, 0x8a, 0x9a, 0x70,
+	0x44, 0x8f, 0xd7, 0xe1, 0x52, 0xe8, 0xea, 0x4d, 0x0d, 0xb2, 0xbe, 0x3f, 0xa5, 0x27, 0x33, 0x1e,
+	0xf8, 0x18, 0x6d, 0x9e, 0x53, 0x76, 0xe6, 0x35, 0x08, 0xf0, 0x80, 0xfa, 0x1c, 0xa2, 0xc0, 0xc3,
+	0x36, 0x3d, 0x0a, 0x07, 0xf9, 0xcd, 0x83, 0x79, 0x06, 0x64, 0xbe, 0xdf, 0x28, 0x2d, 0x9b, 0x36,
+	0xfa, 0xb5, 0xca, 0x5e, 0xe9, 0x93, 0x4f, 0xcd, 0xa5, 0x79, 0x69, 0x8d, 0xf4, 0x64, 0xc6, 0x03,
+	0xef, 0xa1, 0x35, 0xb7, 0x43, 0x39, 0x94, 0xa9, 0xef, 0x83, 0x2b, 0x0b, 0x36, 0x93, 0xdb, 0x89,
+	0x9d, 0x8c, 0xfd, 0xb6, 0x06, 0x59, 0x2b, 0x4f, 0xaa, 0xc9, 0xb4, 0x3d, 0xae, 0xa2, 0x0d, 0x5d,
+	0x2d, 0xb0, 0x0b, 0x60, 0x65, 0xea, 0x0b, 0xc7, 0xf3, 0xb9, 0xf9, 0x40, 0x25, 0xf3, 0x58, 0xe3,
+	0x6c, 0xec, 0xcf, 0xb1, 0x21, 0x73, 0x3d, 0xf1, 0x67, 0x68, 0x25, 0x92, 0x97, 0x5b, 0x0e, 0xe3,
+	0x20, 0xcc, 0x94, 0x82, 0xda, 0xd4, 0x50, 0x2b, 0xfb, 0xe3, 0x4a, 0x32, 0x69, 0x2b, 0xfb, 0xc2,
+	0xe0, 0xfb, 0x9e, 0xc7, 0xe0, 0xd4, 0xe9, 0x78, 0x8d, 0x93, 0xfa, 0xc1, 0x73, 0x33, 0xad, 0x4a,
+	0x8a, 0xfb, 0x42, 0xa6, 0xf4, 0x64, 0xc6, 0xa3, 0xf0, 0x47, 0x26, 0xda, 0x83, 0x2a, 0xe5, 0x62,
+	0x2f, 0xaa, 0x73, 0x1b, 0x25, 0x03, 0x47, 0xb4, 0xf4, 0xec, 0x97, 0x35, 0x58, 0xb2, 0xea, 0x88,
+	0x16, 0x51, 0x1a, 0x4c, 0x50, 0x32, 0xa0, 0x4c, 0xa8, 0x15, 0x33, 0x4a, 0x1f, 0x8d, 0x6d, 0x6f,
+	0x7c, 0xd6, 0xac, 0xa0, 0xdd, 0xb4, 0xe4, 0x59, 0xb3, 0xa2, 0xb3, 0x66, 0xbd, 0xf0, 0xc5, 0x31,
+	0xab, 0x09, 0xe6, 0xf9, 0xcd, 0x31, 0x4c, 0xca, 0x04, 0x51, 0x58, 0x32, 0x6a, 0x8b, 0x72, 0xa1,
+	0x47, 0x1b, 0x5b, 0x54, 0x28, 0x17, 0x44, 0x69, 0xf0, 0x01, 0x4a, 0x71, 0xb7, 0x05, 0x5d, 0x50,
+	0x93, 0xcb, 0xda, 0xd6, 0x70, 0xb5, 0x6b, 0x4a, 0xfa, 0xcf, 0x20, 0xff, 0x78, 0x96, 0x18, 0xac,
+	0x13, 0xf2, 0x22, 0xd2, 0x13, 0xed, 0x8d, 0x4f, 0x90, 0xd1, 0x12, 0x22, 0x18, 0xee, 0xe5, 0x83,
+	0xed, 0xa5, 0x1d, 0xa3, 0x94, 0x9b, 0x77, 0x04, 0x65, 0x63, 0x22, 0x33, 0xfb, 0x2d, 0x1d, 0xcc,
+	0x18, 0xc9, 0x38, 0x19, 0xc7, 0x91, 0x05, 0x9c, 0xd1, 0x46, 0xdf, 0x4c, 0x4d, 0x16, 0x20, 0x77,
+	0x90, 0x28, 0x0d, 0x3e, 0x44, 0xc9, 0x73, 0xca, 0xba, 0x66, 0x5a, 0x45, 0x7c, 0x62, 0xdd, 0xcc,
+	0x88, 0x56, 0x4c, 0x43, 0x23, 0x20, 0x29, 0x22, 0x0a, 0x00, 0x9f, 0xa2, 0x2c, 0x1f, 0x52, 0x8a,
+	0x99, 0x51, 0x43, 0xf8, 0xe0, 0x36, 0xb4, 0x09, 0x0e, 0xb2, 0x57, 0xc2, 0x41, 0x3e, 0x1b, 0xff,
+	0x92, 0x11, 0xd4, 0x5c, 0x06, 0xc8, 0xde, 0x1f, 0x03, 0xa0, 0x7b, 0x64, 0x00, 0xe3, 0x3e, 0x18,
+	0x60, 0xf9, 0x8e, 0x0c, 0xf0, 0x15, 0xca, 0x08, 0xe6, 0x78, 0x1d, 0x59, 0xcc, 0xca, 0x1b, 0xad,
+	0xcd, 0xba, 0xc6, 0xce, 0xd4, 0xb5, 0x1f, 0x89, 0x11, 0x6e, 0xe4, 0x93, 0xd5, 0xfb, 0xe3, 0x93,
+	0xb5, 0xff, 0xc9, 0x27, 0xeb, 0x77, 0xe6, 0x93, 0x3f, 0x93, 0x28, 0x5d, 0x71, 0xfc, 0x46, 0x07,
+	0x18, 0xfe, 0x1c, 0x25, 0xe1, 0x12, 0x5c, 0x7d, 0xc9, 0xcd, 0x6d, 0xd5, 0xfe, 0x25, 0xb8, 0x11,
+	0xed, 0xd8, 0x19, 0xb9, 0xe4, 0xf2, 0x9f, 0x28, 0x2f, 0x5c, 0x41, 0x69, 0x79, 0xbc, 0x0e, 0x61,
+	0xc8, 0x33, 0xef, 0xde, 0xd4, 0xeb, 0x43, 0xd0, 0xd4, 0x65, 0x1b, 0xe1, 0x20, 0x9f, 0xd6, 0x22,
+	0x32, 0x74, 0xc7, 0x75, 0x94, 0x91, 0x9f, 0xd5, 0x21, 0xbd, 0x18, 0xa5, 0xa7, 0xb7, 0x9e, 0x96,
+	0x09, 0x3a, 0xb4, 0x97, 0xe5, 0xf8, 0x86, 0x32, 0x12, 0x23, 0xe1, 0x2a, 0xca, 0x0a, 0x37, 0xa8,
+	0x51, 0xb7, 0x0d, 0x42, 0x31, 0x92, 0x51, 0x7a, 0x6f, 0x5e, 0x86, 0xf5, 0x72, 0x35, 0x32, 0xd2,
+	0x78, 0xea, 0xf8, 0xc5, 0x42, 0x32, 0x02, 0x91, 0xe3, 0x73, 0xa3, 0x51, 0x02, 0x7b, 0xe9, 0x74,
+	0xc1, 0x7c, 0x30, 0x39, 0xbe, 0xf2, 0xb8, 0x92, 0x4c, 0xda, 0xe2, 0x73, 0xb4, 0xaa, 0xeb, 0xd5,
+	0xf7, 0xb9, 0x99, 0x7a, 0xb3, 0x52, 0x47, 0x1e, 0x36, 0x0e, 0x07, 0xf9, 0xa9, 0x57, 0x01, 0x99,
+	0x42, 0xc5, 0x3e, 0x5a, 0x8f, 0x33, 0x1e, 0x46, 0x4a, 0xab, 0x48, 0xcf, 0x6e, 0x8b, 0x14, 0x17,
+	0x3c, 0x8c, 0xb5, 0x21, 0x17, 0x6a, 0x5a, 0x4a, 0x66, 0xb0, 0x0b, 0xbf, 0x27, 0xd0, 0x6a, 0xbd,
+	0x5c, 0x2d, 0x33, 0x68, 0x80, 0x2f, 0x3c, 0xa7, 0xc3, 0xf1, 0x33, 0x94, 0xe9, 0x71, 0x60, 0xbe,
+	0x6c, 0x51, 0x74, 0x49, 0xc5, 0xc7, 0xec, 0x44, 0xcb, 0x49, 0x6c, 0x81, 0x7d, 0xf4, 0x30, 0x70,
+	0x38, 0xff, 0x81, 0xb2, 0xc6, 0xe8, 0xdd, 0xb5, 0x78, 0x97, 0x77, 0xd7, 0x23, 0x8d, 0xfe, 0xb0,
+	0x3a, 0x8d, 0x43, 0x66, 0xa1, 0x0b, 0xbf, 0x25, 0xd0, 0x4c, 0x5d, 0x32, 0x65, 0xf5, 0xc4, 0x74,
+	0x69, 0x67, 0x3a, 0xe5, 0xaa, 0x96, 0x93, 0xd8, 0x02, 0x7f, 0x8b, 0x0c, 0x77, 0x54, 0xaf, 0xb9,
+	0xf8, 0xdf, 0x83, 0x9c, 0xec, 0x90, 0xbd, 0x26, 0x6f, 0xaa, 0x31, 0x01, 0x19, 0xc7, 0xb3, 0xbf,
+	0xb8, 0xba, 0xce, 0x2d, 0xbc, 0xba, 0xce, 0x2d, 0xbc, 0xbe, 0xce, 0x2d, 0xfc, 0x14, 0xe6, 0x12,
+	0x57, 0x61, 0x2e, 0xf1, 0x2a, 0xcc, 0x25, 0x5e, 0x87, 0xb9, 0xc4, 0x5f, 0x61, 0x2e, 0xf1, 0xcb,
+	0xdf, 0xb9, 0x85, 0x6f, 0xb6, 0x6e, 0x7e, 0xdc, 0xff, 0x3b, 0x00, 0x83, 0x16, 0xd3, 0xf4, 0xf9,
+	0x0b, 0x00, 0x00,
 }
 
 func (m *FormEntry) Marshal() (dAtA []byte, err error) {
@@ -218,6 +365,112 @@ func (m *FormEntry) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *HMACSignature) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *HMACSignature) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *HMACSignature) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	i -= len(m.Header)
+	copy(dAtA[i:], m.Header)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.Header)))
+	i--
+	dAtA[i] = 0x12
+	{
+		size, err := m.KeySecretRef.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintGenerated(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
+func (m *HTTPGetOptions) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *HTTPGetOptions) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *HTTPGetOptions) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	i--
+	if m.RequireValidUTF8 {
+		dAtA[i] = 1
+	} else {
+		dAtA[i] = 0
+	}
+	i--
+	dAtA[i] = 0x38
+	i -= len(m.ExpectCharset)
+	copy(dAtA[i:], m.ExpectCharset)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.ExpectCharset)))
+	i--
+	dAtA[i] = 0x32
+	i -= len(m.ExpectServerContains)
+	copy(dAtA[i:], m.ExpectServerContains)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.ExpectServerContains)))
+	i--
+	dAtA[i] = 0x2a
+	i--
+	if m.CloseConnection {
+		dAtA[i] = 1
+	} else {
+		dAtA[i] = 0
+	}
+	i--
+	dAtA[i] = 0x20
+	i -= len(m.ExpectBodySHA256)
+	copy(dAtA[i:], m.ExpectBodySHA256)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.ExpectBodySHA256)))
+	i--
+	dAtA[i] = 0x1a
+	if len(m.ForbidResponseHeaders) > 0 {
+		for iNdEx := len(m.ForbidResponseHeaders) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.ForbidResponseHeaders[iNdEx])
+			copy(dAtA[i:], m.ForbidResponseHeaders[iNdEx])
+			i = encodeVarintGenerated(dAtA, i, uint64(len(m.ForbidResponseHeaders[iNdEx])))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	i -= len(m.ExpectStatusText)
+	copy(dAtA[i:], m.ExpectStatusText)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.ExpectStatusText)))
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
 func (m *HTTPPostAction) Marshal() (dAtA []byte, err error) {
 	size := m.Size()
 	dAtA = make([]byte, size)
@@ -238,6 +491,79 @@ func (m *HTTPPostAction) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	i--
+	if m.RequireValidUTF8 {
+		dAtA[i] = 1
+	} else {
+		dAtA[i] = 0
+	}
+	i--
+	dAtA[i] = 0x1
+	i--
+	dAtA[i] = 0x80
+	i -= len(m.ExpectCharset)
+	copy(dAtA[i:], m.ExpectCharset)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.ExpectCharset)))
+	i--
+	dAtA[i] = 0x7a
+	i -= len(m.ExpectServerContains)
+	copy(dAtA[i:], m.ExpectServerContains)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.ExpectServerContains)))
+	i--
+	dAtA[i] = 0x72
+	if len(m.Trailers) > 0 {
+		for iNdEx := len(m.Trailers) - 1; iNdEx >= 0; iNdEx-- {
+			{
+				size, err := m.Trailers[iNdEx].MarshalToSizedBuffer(dAtA[:i])
+				if err != nil {
+					return 0, err
+				}
+				i -= size
+				i = encodeVarintGenerated(dAtA, i, uint64(size))
+			}
+			i--
+			dAtA[i] = 0x6a
+		}
+	}
+	i--
+	if m.CloseConnection {
+		dAtA[i] = 1
+	} else {
+		dAtA[i] = 0
+	}
+	i--
+	dAtA[i] = 0x60
+	i -= len(m.ExpectBodySHA256)
+	copy(dAtA[i:], m.ExpectBodySHA256)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.ExpectBodySHA256)))
+	i--
+	dAtA[i] = 0x5a
+	if len(m.ForbidResponseHeaders) > 0 {
+		for iNdEx := len(m.ForbidResponseHeaders) - 1; iNdEx >= 0; iNdEx-- {
+			i -= len(m.ForbidResponseHeaders[iNdEx])
+			copy(dAtA[i:], m.ForbidResponseHeaders[iNdEx])
+			i = encodeVarintGenerated(dAtA, i, uint64(len(m.ForbidResponseHeaders[iNdEx])))
+			i--
+			dAtA[i] = 0x52
+		}
+	}
+	i -= len(m.ExpectStatusText)
+	copy(dAtA[i:], m.ExpectStatusText)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.ExpectStatusText)))
+	i--
+	dAtA[i] = 0x4a
+	if m.Signature != nil {
+		{
+			size, err := m.Signature.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintGenerated(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x42
+	}
 	if len(m.Form) > 0 {
 		for iNdEx := len(m.Form) - 1; iNdEx >= 0; iNdEx-- {
 			{
@@ -319,6 +645,30 @@ func (m *Handler) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	_ = i
 	var l int
 	_ = l
+	if m.TCPSocketOptions != nil {
+		{
+			size, err := m.TCPSocketOptions.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintGenerated(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x3a
+	}
+	if m.HTTPGetOptions != nil {
+		{
+			size, err := m.HTTPGetOptions.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintGenerated(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x32
+	}
 	i -= len(m.ContainerName)
 	copy(dAtA[i:], m.ContainerName)
 	i = encodeVarintGenerated(dAtA, i, uint64(len(m.ContainerName)))
@@ -375,6 +725,84 @@ func (m *Handler) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 	return len(dAtA) - i, nil
 }
 
+func (m *TCPCredentials) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TCPCredentials) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TCPCredentials) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	{
+		size, err := m.PasswordSecretRef.MarshalToSizedBuffer(dAtA[:i])
+		if err != nil {
+			return 0, err
+		}
+		i -= size
+		i = encodeVarintGenerated(dAtA, i, uint64(size))
+	}
+	i--
+	dAtA[i] = 0x12
+	i -= len(m.Username)
+	copy(dAtA[i:], m.Username)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.Username)))
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
+func (m *TCPSocketOptions) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *TCPSocketOptions) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *TCPSocketOptions) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Credentials != nil {
+		{
+			size, err := m.Credentials.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintGenerated(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x12
+	}
+	i -= len(m.Protocol)
+	copy(dAtA[i:], m.Protocol)
+	i = encodeVarintGenerated(dAtA, i, uint64(len(m.Protocol)))
+	i--
+	dAtA[i] = 0xa
+	return len(dAtA) - i, nil
+}
+
 func encodeVarintGenerated(dAtA []byte, offset int, v uint64) int {
 	offset -= sovGenerated(v)
 	base := offset
@@ -403,17 +831,55 @@ func (m *FormEntry) Size() (n int) {
 	return n
 }
 
-func (m *HTTPPostAction) Size() (n int) {
+func (m *HMACSignature) Size() (n int) {
 	if m == nil {
 		return 0
 	}
 	var l int
 	_ = l
-	l = len(m.Path)
-	n += 1 + l + sovGenerated(uint64(l))
-	l = m.Port.Size()
+	l = m.KeySecretRef.Size()
 	n += 1 + l + sovGenerated(uint64(l))
-	l = len(m.Host)
+	l = len(m.Header)
+	n += 1 + l + sovGenerated(uint64(l))
+	return n
+}
+
+func (m *HTTPGetOptions) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.ExpectStatusText)
+	n += 1 + l + sovGenerated(uint64(l))
+	if len(m.ForbidResponseHeaders) > 0 {
+		for _, s := range m.ForbidResponseHeaders {
+			l = len(s)
+			n += 1 + l + sovGenerated(uint64(l))
+		}
+	}
+	l = len(m.ExpectBodySHA256)
+	n += 1 + l + sovGenerated(uint64(l))
+	n += 2
+	l = len(m.ExpectServerContains)
+	n += 1 + l + sovGenerated(uint64(l))
+	l = len(m.ExpectCharset)
+	n += 1 + l + sovGenerated(uint64(l))
+	n += 2
+	return n
+}
+
+func (m *HTTPPostAction) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Path)
+	n += 1 + l + sovGenerated(uint64(l))
+	l = m.Port.Size()
+	n += 1 + l + sovGenerated(uint64(l))
+	l = len(m.Host)
 	n += 1 + l + sovGenerated(uint64(l))
 	l = len(m.Scheme)
 	n += 1 + l + sovGenerated(uint64(l))
@@ -431,6 +897,32 @@ func (m *HTTPPostAction) Size() (n int) {
 			n += 1 + l + sovGenerated(uint64(l))
 		}
 	}
+	if m.Signature != nil {
+		l = m.Signature.Size()
+		n += 1 + l + sovGenerated(uint64(l))
+	}
+	l = len(m.ExpectStatusText)
+	n += 1 + l + sovGenerated(uint64(l))
+	if len(m.ForbidResponseHeaders) > 0 {
+		for _, s := range m.ForbidResponseHeaders {
+			l = len(s)
+			n += 1 + l + sovGenerated(uint64(l))
+		}
+	}
+	l = len(m.ExpectBodySHA256)
+	n += 1 + l + sovGenerated(uint64(l))
+	n += 2
+	if len(m.Trailers) > 0 {
+		for _, e := range m.Trailers {
+			l = e.Size()
+			n += 1 + l + sovGenerated(uint64(l))
+		}
+	}
+	l = len(m.ExpectServerContains)
+	n += 1 + l + sovGenerated(uint64(l))
+	l = len(m.ExpectCharset)
+	n += 1 + l + sovGenerated(uint64(l))
+	n += 3
 	return n
 }
 
@@ -458,6 +950,42 @@ func (m *Handler) Size() (n int) {
 	}
 	l = len(m.ContainerName)
 	n += 1 + l + sovGenerated(uint64(l))
+	if m.HTTPGetOptions != nil {
+		l = m.HTTPGetOptions.Size()
+		n += 1 + l + sovGenerated(uint64(l))
+	}
+	if m.TCPSocketOptions != nil {
+		l = m.TCPSocketOptions.Size()
+		n += 1 + l + sovGenerated(uint64(l))
+	}
+	return n
+}
+
+func (m *TCPCredentials) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Username)
+	n += 1 + l + sovGenerated(uint64(l))
+	l = m.PasswordSecretRef.Size()
+	n += 1 + l + sovGenerated(uint64(l))
+	return n
+}
+
+func (m *TCPSocketOptions) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Protocol)
+	n += 1 + l + sovGenerated(uint64(l))
+	if m.Credentials != nil {
+		l = m.Credentials.Size()
+		n += 1 + l + sovGenerated(uint64(l))
+	}
 	return n
 }
 
@@ -478,6 +1006,33 @@ func (this *FormEntry) String() string {
 	}, "")
 	return s
 }
+func (this *HMACSignature) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&HMACSignature{`,
+		`KeySecretRef:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.KeySecretRef), "SecretKeySelector", "v1.SecretKeySelector", 1), `&`, ``, 1) + `,`,
+		`Header:` + fmt.Sprintf("%v", this.Header) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *HTTPGetOptions) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&HTTPGetOptions{`,
+		`ExpectStatusText:` + fmt.Sprintf("%v", this.ExpectStatusText) + `,`,
+		`ForbidResponseHeaders:` + fmt.Sprintf("%v", this.ForbidResponseHeaders) + `,`,
+		`ExpectBodySHA256:` + fmt.Sprintf("%v", this.ExpectBodySHA256) + `,`,
+		`CloseConnection:` + fmt.Sprintf("%v", this.CloseConnection) + `,`,
+		`ExpectServerContains:` + fmt.Sprintf("%v", this.ExpectServerContains) + `,`,
+		`ExpectCharset:` + fmt.Sprintf("%v", this.ExpectCharset) + `,`,
+		`RequireValidUTF8:` + fmt.Sprintf("%v", this.RequireValidUTF8) + `,`,
+		`}`,
+	}, "")
+	return s
+}
 func (this *HTTPPostAction) String() string {
 	if this == nil {
 		return "nil"
@@ -492,6 +1047,11 @@ func (this *HTTPPostAction) String() string {
 		repeatedStringForForm += strings.Replace(strings.Replace(f.String(), "FormEntry", "FormEntry", 1), `&`, ``, 1) + ","
 	}
 	repeatedStringForForm += "}"
+	repeatedStringForTrailers := "[]HTTPHeader{"
+	for _, f := range this.Trailers {
+		repeatedStringForTrailers += fmt.Sprintf("%v", f) + ","
+	}
+	repeatedStringForTrailers += "}"
 	s := strings.Join([]string{`&HTTPPostAction{`,
 		`Path:` + fmt.Sprintf("%v", this.Path) + `,`,
 		`Port:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.Port), "IntOrString", "intstr.IntOrString", 1), `&`, ``, 1) + `,`,
@@ -500,6 +1060,15 @@ func (this *HTTPPostAction) String() string {
 		`HTTPHeaders:` + repeatedStringForHTTPHeaders + `,`,
 		`Body:` + fmt.Sprintf("%v", this.Body) + `,`,
 		`Form:` + repeatedStringForForm + `,`,
+		`Signature:` + strings.Replace(this.Signature.String(), "HMACSignature", "HMACSignature", 1) + `,`,
+		`ExpectStatusText:` + fmt.Sprintf("%v", this.ExpectStatusText) + `,`,
+		`ForbidResponseHeaders:` + fmt.Sprintf("%v", this.ForbidResponseHeaders) + `,`,
+		`ExpectBodySHA256:` + fmt.Sprintf("%v", this.ExpectBodySHA256) + `,`,
+		`CloseConnection:` + fmt.Sprintf("%v", this.CloseConnection) + `,`,
+		`Trailers:` + repeatedStringForTrailers + `,`,
+		`ExpectServerContains:` + fmt.Sprintf("%v", this.ExpectServerContains) + `,`,
+		`ExpectCharset:` + fmt.Sprintf("%v", this.ExpectCharset) + `,`,
+		`RequireValidUTF8:` + fmt.Sprintf("%v", this.RequireValidUTF8) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -514,6 +1083,30 @@ func (this *Handler) String() string {
 		`HTTPPost:` + strings.Replace(this.HTTPPost.String(), "HTTPPostAction", "HTTPPostAction", 1) + `,`,
 		`TCPSocket:` + strings.Replace(fmt.Sprintf("%v", this.TCPSocket), "TCPSocketAction", "v1.TCPSocketAction", 1) + `,`,
 		`ContainerName:` + fmt.Sprintf("%v", this.ContainerName) + `,`,
+		`HTTPGetOptions:` + strings.Replace(this.HTTPGetOptions.String(), "HTTPGetOptions", "HTTPGetOptions", 1) + `,`,
+		`TCPSocketOptions:` + strings.Replace(this.TCPSocketOptions.String(), "TCPSocketOptions", "TCPSocketOptions", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *TCPCredentials) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&TCPCredentials{`,
+		`Username:` + fmt.Sprintf("%v", this.Username) + `,`,
+		`PasswordSecretRef:` + strings.Replace(strings.Replace(fmt.Sprintf("%v", this.PasswordSecretRef), "SecretKeySelector", "v1.SecretKeySelector", 1), `&`, ``, 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *TCPSocketOptions) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&TCPSocketOptions{`,
+		`Protocol:` + fmt.Sprintf("%v", this.Protocol) + `,`,
+		`Credentials:` + strings.Replace(this.Credentials.String(), "TCPCredentials", "TCPCredentials", 1) + `,`,
 		`}`,
 	}, "")
 	return s
@@ -557,7 +1150,901 @@ func (m *FormEntry) Unmarshal(dAtA []byte) error {
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Key", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Key = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Values", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Values = append(m.Values, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGenerated(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *HMACSignature) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGenerated
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: HMACSignature: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: HMACSignature: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field KeySecretRef", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.KeySecretRef.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Header", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Header = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGenerated(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *HTTPGetOptions) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGenerated
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: HTTPGetOptions: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: HTTPGetOptions: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpectStatusText", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ExpectStatusText = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ForbidResponseHeaders", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ForbidResponseHeaders = append(m.ForbidResponseHeaders, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpectBodySHA256", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ExpectBodySHA256 = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CloseConnection", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.CloseConnection = bool(v != 0)
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpectServerContains", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ExpectServerContains = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpectCharset", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ExpectCharset = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RequireValidUTF8", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.RequireValidUTF8 = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGenerated(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *HTTPPostAction) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGenerated
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: HTTPPostAction: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: HTTPPostAction: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Path", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Path = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Port", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if err := m.Port.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Host", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Host = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Scheme", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Scheme = k8s_io_api_core_v1.URIScheme(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HTTPHeaders", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.HTTPHeaders = append(m.HTTPHeaders, v1.HTTPHeader{})
+			if err := m.HTTPHeaders[len(m.HTTPHeaders)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Body", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Body = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Form", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Form = append(m.Form, FormEntry{})
+			if err := m.Form[len(m.Form)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 8:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Signature", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Signature == nil {
+				m.Signature = &HMACSignature{}
+			}
+			if err := m.Signature.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpectStatusText", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ExpectStatusText = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 10:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ForbidResponseHeaders", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ForbidResponseHeaders = append(m.ForbidResponseHeaders, string(dAtA[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpectBodySHA256", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ExpectBodySHA256 = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CloseConnection", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.CloseConnection = bool(v != 0)
+		case 13:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Trailers", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthGenerated
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Trailers = append(m.Trailers, v1.HTTPHeader{})
+			if err := m.Trailers[len(m.Trailers)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 14:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpectServerContains", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -585,11 +2072,11 @@ func (m *FormEntry) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Key = string(dAtA[iNdEx:postIndex])
+			m.ExpectServerContains = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 2:
+		case 15:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Values", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ExpectCharset", wireType)
 			}
 			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
@@ -617,8 +2104,28 @@ func (m *FormEntry) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Values = append(m.Values, string(dAtA[iNdEx:postIndex]))
+			m.ExpectCharset = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
+		case 16:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RequireValidUTF8", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowGenerated
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.RequireValidUTF8 = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipGenerated(dAtA[iNdEx:])
@@ -640,7 +2147,7 @@ func (m *FormEntry) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *HTTPPostAction) Unmarshal(dAtA []byte) error {
+func (m *Handler) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -663,17 +2170,17 @@ func (m *HTTPPostAction) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: HTTPPostAction: wiretype end group for non-group")
+			return fmt.Errorf("proto: Handler: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: HTTPPostAction: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: Handler: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Path", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Exec", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGenerated
@@ -683,27 +2190,31 @@ func (m *HTTPPostAction) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthGenerated
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthGenerated
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Path = string(dAtA[iNdEx:postIndex])
+			if m.Exec == nil {
+				m.Exec = &v1.ExecAction{}
+			}
+			if err := m.Exec.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Port", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field HTTPGet", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -730,15 +2241,18 @@ func (m *HTTPPostAction) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if err := m.Port.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if m.HTTPGet == nil {
+				m.HTTPGet = &v1.HTTPGetAction{}
+			}
+			if err := m.HTTPGet.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
 		case 3:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Host", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field HTTPPost", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGenerated
@@ -748,29 +2262,33 @@ func (m *HTTPPostAction) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthGenerated
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthGenerated
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Host = string(dAtA[iNdEx:postIndex])
+			if m.HTTPPost == nil {
+				m.HTTPPost = &HTTPPostAction{}
+			}
+			if err := m.HTTPPost.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
 		case 4:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Scheme", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field TCPSocket", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGenerated
@@ -780,29 +2298,33 @@ func (m *HTTPPostAction) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthGenerated
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthGenerated
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Scheme = k8s_io_api_core_v1.URIScheme(dAtA[iNdEx:postIndex])
+			if m.TCPSocket == nil {
+				m.TCPSocket = &v1.TCPSocketAction{}
+			}
+			if err := m.TCPSocket.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
 		case 5:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field HTTPHeaders", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field ContainerName", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGenerated
@@ -812,31 +2334,29 @@ func (m *HTTPPostAction) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthGenerated
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthGenerated
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.HTTPHeaders = append(m.HTTPHeaders, v1.HTTPHeader{})
-			if err := m.HTTPHeaders[len(m.HTTPHeaders)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.ContainerName = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 6:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Body", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field HTTPGetOptions", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGenerated
@@ -846,27 +2366,31 @@ func (m *HTTPPostAction) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthGenerated
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthGenerated
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Body = string(dAtA[iNdEx:postIndex])
+			if m.HTTPGetOptions == nil {
+				m.HTTPGetOptions = &HTTPGetOptions{}
+			}
+			if err := m.HTTPGetOptions.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
 		case 7:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Form", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field TCPSocketOptions", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -893,8 +2417,10 @@ func (m *HTTPPostAction) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.Form = append(m.Form, FormEntry{})
-			if err := m.Form[len(m.Form)-1].Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if m.TCPSocketOptions == nil {
+				m.TCPSocketOptions = &TCPSocketOptions{}
+			}
+			if err := m.TCPSocketOptions.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
@@ -919,7 +2445,7 @@ func (m *HTTPPostAction) Unmarshal(dAtA []byte) error {
 	}
 	return nil
 }
-func (m *Handler) Unmarshal(dAtA []byte) error {
+func (m *TCPCredentials) Unmarshal(dAtA []byte) error {
 	l := len(dAtA)
 	iNdEx := 0
 	for iNdEx < l {
@@ -942,17 +2468,17 @@ func (m *Handler) Unmarshal(dAtA []byte) error {
 		fieldNum := int32(wire >> 3)
 		wireType := int(wire & 0x7)
 		if wireType == 4 {
-			return fmt.Errorf("proto: Handler: wiretype end group for non-group")
+			return fmt.Errorf("proto: TCPCredentials: wiretype end group for non-group")
 		}
 		if fieldNum <= 0 {
-			return fmt.Errorf("proto: Handler: illegal tag %d (wire type %d)", fieldNum, wire)
+			return fmt.Errorf("proto: TCPCredentials: illegal tag %d (wire type %d)", fieldNum, wire)
 		}
 		switch fieldNum {
 		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field Exec", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Username", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGenerated
@@ -962,31 +2488,27 @@ func (m *Handler) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthGenerated
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthGenerated
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.Exec == nil {
-				m.Exec = &v1.ExecAction{}
-			}
-			if err := m.Exec.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Username = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
 		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field HTTPGet", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field PasswordSecretRef", wireType)
 			}
 			var msglen int
 			for shift := uint(0); ; shift += 7 {
@@ -1013,54 +2535,65 @@ func (m *Handler) Unmarshal(dAtA []byte) error {
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.HTTPGet == nil {
-				m.HTTPGet = &v1.HTTPGetAction{}
-			}
-			if err := m.HTTPGet.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+			if err := m.PasswordSecretRef.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
 				return err
 			}
 			iNdEx = postIndex
-		case 3:
-			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field HTTPPost", wireType)
-			}
-			var msglen int
-			for shift := uint(0); ; shift += 7 {
-				if shift >= 64 {
-					return ErrIntOverflowGenerated
-				}
-				if iNdEx >= l {
-					return io.ErrUnexpectedEOF
-				}
-				b := dAtA[iNdEx]
-				iNdEx++
-				msglen |= int(b&0x7F) << shift
-				if b < 0x80 {
-					break
-				}
-			}
-			if msglen < 0 {
-				return ErrInvalidLengthGenerated
+		default:
+			iNdEx = preIndex
+			skippy, err := skipGenerated(dAtA[iNdEx:])
+			if err != nil {
+				return err
 			}
-			postIndex := iNdEx + msglen
-			if postIndex < 0 {
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
 				return ErrInvalidLengthGenerated
 			}
-			if postIndex > l {
+			if (iNdEx + skippy) > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.HTTPPost == nil {
-				m.HTTPPost = &HTTPPostAction{}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *TCPSocketOptions) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowGenerated
 			}
-			if err := m.HTTPPost.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
 			}
-			iNdEx = postIndex
-		case 4:
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: TCPSocketOptions: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: TCPSocketOptions: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field TCPSocket", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Protocol", wireType)
 			}
-			var msglen int
+			var stringLen uint64
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGenerated
@@ -1070,33 +2603,29 @@ func (m *Handler) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				msglen |= int(b&0x7F) << shift
+				stringLen |= uint64(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			if msglen < 0 {
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthGenerated
 			}
-			postIndex := iNdEx + msglen
+			postIndex := iNdEx + intStringLen
 			if postIndex < 0 {
 				return ErrInvalidLengthGenerated
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			if m.TCPSocket == nil {
-				m.TCPSocket = &v1.TCPSocketAction{}
-			}
-			if err := m.TCPSocket.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
-				return err
-			}
+			m.Protocol = string(dAtA[iNdEx:postIndex])
 			iNdEx = postIndex
-		case 5:
+		case 2:
 			if wireType != 2 {
-				return fmt.Errorf("proto: wrong wireType = %d for field ContainerName", wireType)
+				return fmt.Errorf("proto: wrong wireType = %d for field Credentials", wireType)
 			}
-			var stringLen uint64
+			var msglen int
 			for shift := uint(0); ; shift += 7 {
 				if shift >= 64 {
 					return ErrIntOverflowGenerated
@@ -1106,23 +2635,27 @@ func (m *Handler) Unmarshal(dAtA []byte) error {
 				}
 				b := dAtA[iNdEx]
 				iNdEx++
-				stringLen |= uint64(b&0x7F) << shift
+				msglen |= int(b&0x7F) << shift
 				if b < 0x80 {
 					break
 				}
 			}
-			intStringLen := int(stringLen)
-			if intStringLen < 0 {
+			if msglen < 0 {
 				return ErrInvalidLengthGenerated
 			}
-			postIndex := iNdEx + intStringLen
+			postIndex := iNdEx + msglen
 			if postIndex < 0 {
 				return ErrInvalidLengthGenerated
 			}
 			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			m.ContainerName = string(dAtA[iNdEx:postIndex])
+			if m.Credentials == nil {
+				m.Credentials = &TCPCredentials{}
+			}
+			if err := m.Credentials.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
 			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
@@ -0,0 +1,53 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+// Kinds of the actions of a Handler, as used by the prober in its error messages.
+const (
+	ProbeKindExec     = "exec"
+	ProbeKindHTTPGet  = "httpGet"
+	ProbeKindHTTPPost = "httpPost"
+	ProbeKindTCP      = "tcp"
+)
+
+// ProbeKind returns the kind of the action of h, e.g. "httpGet" for logs or metrics.
+// It returns "" if h sets no action, or more than one.
+func (h *Handler) ProbeKind() string {
+	kinds := h.ProbeKinds()
+	if len(kinds) != 1 {
+		return ""
+	}
+	return kinds[0]
+}
+
+// ProbeKinds returns the kinds of all the actions set in h, in the order the prober runs them.
+func (h *Handler) ProbeKinds() []string {
+	var kinds []string
+	if h.Exec != nil {
+		kinds = append(kinds, ProbeKindExec)
+	}
+	if h.HTTPGet != nil {
+		kinds = append(kinds, ProbeKindHTTPGet)
+	}
+	if h.HTTPPost != nil {
+		kinds = append(kinds, ProbeKindHTTPPost)
+	}
+	if h.TCPSocket != nil {
+		kinds = append(kinds, ProbeKindTCP)
+	}
+	return kinds
+}
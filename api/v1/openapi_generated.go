@@ -30,9 +30,13 @@ import (
 
 func GetOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
-		"kmodules.xyz/prober/api/v1.FormEntry":      schema_kmodulesxyz_prober_api_v1_FormEntry(ref),
-		"kmodules.xyz/prober/api/v1.HTTPPostAction": schema_kmodulesxyz_prober_api_v1_HTTPPostAction(ref),
-		"kmodules.xyz/prober/api/v1.Handler":        schema_kmodulesxyz_prober_api_v1_Handler(ref),
+		"kmodules.xyz/prober/api/v1.FormEntry":        schema_kmodulesxyz_prober_api_v1_FormEntry(ref),
+		"kmodules.xyz/prober/api/v1.HMACSignature":    schema_kmodulesxyz_prober_api_v1_HMACSignature(ref),
+		"kmodules.xyz/prober/api/v1.HTTPGetOptions":   schema_kmodulesxyz_prober_api_v1_HTTPGetOptions(ref),
+		"kmodules.xyz/prober/api/v1.HTTPPostAction":   schema_kmodulesxyz_prober_api_v1_HTTPPostAction(ref),
+		"kmodules.xyz/prober/api/v1.Handler":          schema_kmodulesxyz_prober_api_v1_Handler(ref),
+		"kmodules.xyz/prober/api/v1.TCPCredentials":   schema_kmodulesxyz_prober_api_v1_TCPCredentials(ref),
+		"kmodules.xyz/prober/api/v1.TCPSocketOptions": schema_kmodulesxyz_prober_api_v1_TCPSocketOptions(ref),
 	}
 }
 
@@ -68,6 +72,106 @@ func schema_kmodulesxyz_prober_api_v1_FormEntry(ref common.ReferenceCallback) co
 	}
 }
 
+func schema_kmodulesxyz_prober_api_v1_HMACSignature(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "HMACSignature sets a hex encoded HMAC-SHA256 of the request body in a request header.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"keySecretRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "KeySecretRef selects the key of a Secret in the namespace of the pod that holds the shared secret. The Secret is read when the pod is probed, and a missing Secret makes the probe Unknown. The key is never logged.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
+						},
+					},
+					"header": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Header is the name of the header that carries the signature. Defaults to X-Signature.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"keySecretRef"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.SecretKeySelector"},
+	}
+}
+
+func schema_kmodulesxyz_prober_api_v1_HTTPGetOptions(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "HTTPGetOptions extends the HTTPGet action of a Handler with settings core.HTTPGetAction does not have. It is ignored unless HTTPGet is set.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"expectStatusText": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExpectStatusText is a regular expression that the status line of the response must match, e.g. \"200 WARMING\". It overrides the one of the prober options.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"forbidResponseHeaders": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ForbidResponseHeaders fails the probe if the response carries any of these headers, e.g. \"X-Debug\", in addition to those forbidden by the prober options.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"expectBodySHA256": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExpectBodySHA256 fails the probe unless the hex encoded SHA-256 of the response body matches, e.g. for endpoints serving immutable content. It overrides the hash of the prober options.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"closeConnection": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CloseConnection sends the request with \"Connection: close\", so the server closes the connection after responding, even if the prober options keep connections alive.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"expectServerContains": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExpectServerContains fails the probe unless the Server header of the response contains this text, ignoring case, e.g. \"envoy\". It overrides the text of the prober options.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"expectCharset": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExpectCharset is the charset the Content-Type of the response must declare, e.g. \"utf-8\". The body must also be valid in that charset; this is checked for UTF-8 and US-ASCII. It overrides the charset of the prober options.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"requireValidUTF8": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RequireValidUTF8 fails the probe if the response body is not valid UTF-8, or if the Content-Type declares a charset other than UTF-8 or its subset US-ASCII, even if the prober options do not require it.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func schema_kmodulesxyz_prober_api_v1_HTTPPostAction(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -137,12 +241,89 @@ func schema_kmodulesxyz_prober_api_v1_HTTPPostAction(ref common.ReferenceCallbac
 							},
 						},
 					},
+					"signature": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Signature signs the request body with an HMAC-SHA256, overriding the signer of the prober options.",
+							Ref:         ref("kmodules.xyz/prober/api/v1.HMACSignature"),
+						},
+					},
+					"expectStatusText": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExpectStatusText is a regular expression that the status line of the response must match, e.g. \"200 WARMING\". It overrides the one of the prober options.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"forbidResponseHeaders": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ForbidResponseHeaders fails the probe if the response carries any of these headers, e.g. \"X-Debug\", in addition to those forbidden by the prober options.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: "",
+										Type:    []string{"string"},
+										Format:  "",
+									},
+								},
+							},
+						},
+					},
+					"expectBodySHA256": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExpectBodySHA256 fails the probe unless the hex encoded SHA-256 of the response body matches, e.g. for endpoints serving immutable content. It overrides the hash of the prober options.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"closeConnection": {
+						SchemaProps: spec.SchemaProps{
+							Description: "CloseConnection sends the request with \"Connection: close\", so the server closes the connection after responding, even if the prober options keep connections alive.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
+					"trailers": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Trailers are sent after the request body, e.g. for servers of streaming protocols that expect them. The body is then sent with chunked transfer encoding and without a Content-Length. They override the trailers of the prober options.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("k8s.io/api/core/v1.HTTPHeader"),
+									},
+								},
+							},
+						},
+					},
+					"expectServerContains": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExpectServerContains fails the probe unless the Server header of the response contains this text, ignoring case, e.g. \"envoy\". It overrides the text of the prober options.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"expectCharset": {
+						SchemaProps: spec.SchemaProps{
+							Description: "ExpectCharset is the charset the Content-Type of the response must declare, e.g. \"utf-8\". The body must also be valid in that charset; this is checked for UTF-8 and US-ASCII. It overrides the charset of the prober options.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"requireValidUTF8": {
+						SchemaProps: spec.SchemaProps{
+							Description: "RequireValidUTF8 fails the probe if the response body is not valid UTF-8, or if the Content-Type declares a charset other than UTF-8 or its subset US-ASCII, even if the prober options do not require it.",
+							Type:        []string{"boolean"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"port"},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/api/core/v1.HTTPHeader", "k8s.io/apimachinery/pkg/util/intstr.IntOrString", "kmodules.xyz/prober/api/v1.FormEntry"},
+			"k8s.io/api/core/v1.HTTPHeader", "k8s.io/apimachinery/pkg/util/intstr.IntOrString", "kmodules.xyz/prober/api/v1.FormEntry", "kmodules.xyz/prober/api/v1.HMACSignature"},
 	}
 }
 
@@ -184,10 +365,80 @@ func schema_kmodulesxyz_prober_api_v1_Handler(ref common.ReferenceCallback) comm
 							Format:      "",
 						},
 					},
+					"httpGetOptions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "HTTPGetOptions specifies additional settings of the HTTPGet action.",
+							Ref:         ref("kmodules.xyz/prober/api/v1.HTTPGetOptions"),
+						},
+					},
+					"tcpSocketOptions": {
+						SchemaProps: spec.SchemaProps{
+							Description: "TCPSocketOptions specifies additional settings of the TCPSocket action.",
+							Ref:         ref("kmodules.xyz/prober/api/v1.TCPSocketOptions"),
+						},
+					},
+				},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.ExecAction", "k8s.io/api/core/v1.HTTPGetAction", "k8s.io/api/core/v1.TCPSocketAction", "kmodules.xyz/prober/api/v1.HTTPGetOptions", "kmodules.xyz/prober/api/v1.HTTPPostAction", "kmodules.xyz/prober/api/v1.TCPSocketOptions"},
+	}
+}
+
+func schema_kmodulesxyz_prober_api_v1_TCPCredentials(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TCPCredentials authenticate the protocol check of a TCP probe. The password is never logged nor reported in the output of the probe.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"username": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Username selects a Redis 6 ACL user. Empty authenticates with the password only.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"passwordSecretRef": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PasswordSecretRef selects the key of a Secret in the namespace of the pod that holds the password. The Secret is read when the pod is probed, and a missing Secret makes the probe Unknown.",
+							Default:     map[string]interface{}{},
+							Ref:         ref("k8s.io/api/core/v1.SecretKeySelector"),
+						},
+					},
+				},
+				Required: []string{"passwordSecretRef"},
+			},
+		},
+		Dependencies: []string{
+			"k8s.io/api/core/v1.SecretKeySelector"},
+	}
+}
+
+func schema_kmodulesxyz_prober_api_v1_TCPSocketOptions(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "TCPSocketOptions extends the TCPSocket action of a Handler with settings core.TCPSocketAction does not have. It is ignored unless TCPSocket is set.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"protocol": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Protocol runs a check of the application protocol over the connection, one of \"redis\" or \"memcached\". Defaults to the protocol of the prober options, which is a connect check only unless set.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"credentials": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Credentials authenticate the Protocol check. Only the redis protocol supports them. Defaults to the credentials of the prober options.",
+							Ref:         ref("kmodules.xyz/prober/api/v1.TCPCredentials"),
+						},
+					},
 				},
 			},
 		},
 		Dependencies: []string{
-			"k8s.io/api/core/v1.ExecAction", "k8s.io/api/core/v1.HTTPGetAction", "k8s.io/api/core/v1.TCPSocketAction", "kmodules.xyz/prober/api/v1.HTTPPostAction"},
+			"kmodules.xyz/prober/api/v1.TCPCredentials"},
 	}
 }
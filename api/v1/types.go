@@ -43,6 +43,46 @@ type Handler struct {
 	// or where to find the port for HTTP or TCP probe
 	// +optional
 	ContainerName string `json:"containerName,omitempty" protobuf:"bytes,5,opt,name=containerName"`
+	// HTTPGetOptions specifies additional settings of the HTTPGet action.
+	// +optional
+	HTTPGetOptions *HTTPGetOptions `json:"httpGetOptions,omitempty" protobuf:"bytes,6,opt,name=httpGetOptions"`
+	// TCPSocketOptions specifies additional settings of the TCPSocket action.
+	// +optional
+	TCPSocketOptions *TCPSocketOptions `json:"tcpSocketOptions,omitempty" protobuf:"bytes,7,opt,name=tcpSocketOptions"`
+}
+
+// HTTPGetOptions extends the HTTPGet action of a Handler with settings core.HTTPGetAction does not have.
+// It is ignored unless HTTPGet is set.
+type HTTPGetOptions struct {
+	// ExpectStatusText is a regular expression that the status line of the response must match,
+	// e.g. "200 WARMING". It overrides the one of the prober options.
+	// +optional
+	ExpectStatusText string `json:"expectStatusText,omitempty" protobuf:"bytes,1,opt,name=expectStatusText"`
+	// ForbidResponseHeaders fails the probe if the response carries any of these headers, e.g. "X-Debug",
+	// in addition to those forbidden by the prober options.
+	// +optional
+	ForbidResponseHeaders []string `json:"forbidResponseHeaders,omitempty" protobuf:"bytes,2,rep,name=forbidResponseHeaders"`
+	// ExpectBodySHA256 fails the probe unless the hex encoded SHA-256 of the response body matches,
+	// e.g. for endpoints serving immutable content. It overrides the hash of the prober options.
+	// +optional
+	ExpectBodySHA256 string `json:"expectBodySHA256,omitempty" protobuf:"bytes,3,opt,name=expectBodySHA256"`
+	// CloseConnection sends the request with "Connection: close", so the server closes the connection
+	// after responding, even if the prober options keep connections alive.
+	// +optional
+	CloseConnection bool `json:"closeConnection,omitempty" protobuf:"varint,4,opt,name=closeConnection"`
+	// ExpectServerContains fails the probe unless the Server header of the response contains this text,
+	// ignoring case, e.g. "envoy". It overrides the text of the prober options.
+	// +optional
+	ExpectServerContains string `json:"expectServerContains,omitempty" protobuf:"bytes,5,opt,name=expectServerContains"`
+	// ExpectCharset is the charset the Content-Type of the response must declare, e.g. "utf-8". The body must
+	// also be valid in that charset; this is checked for UTF-8 and US-ASCII. It overrides the charset of the
+	// prober options.
+	// +optional
+	ExpectCharset string `json:"expectCharset,omitempty" protobuf:"bytes,6,opt,name=expectCharset"`
+	// RequireValidUTF8 fails the probe if the response body is not valid UTF-8, or if the Content-Type
+	// declares a charset other than UTF-8 or its subset US-ASCII, even if the prober options do not require it.
+	// +optional
+	RequireValidUTF8 bool `json:"requireValidUTF8,omitempty" protobuf:"varint,7,opt,name=requireValidUTF8"`
 }
 
 // HTTPPostAction describes an action based on HTTP Post requests.
@@ -71,9 +111,81 @@ type HTTPPostAction struct {
 	// Form to set in the request body.
 	// +optional
 	Form []FormEntry `json:"form,omitempty" protobuf:"bytes,7,rep,name=form"`
+	// Signature signs the request body with an HMAC-SHA256, overriding the signer of the prober options.
+	// +optional
+	Signature *HMACSignature `json:"signature,omitempty" protobuf:"bytes,8,opt,name=signature"`
+	// ExpectStatusText is a regular expression that the status line of the response must match,
+	// e.g. "200 WARMING". It overrides the one of the prober options.
+	// +optional
+	ExpectStatusText string `json:"expectStatusText,omitempty" protobuf:"bytes,9,opt,name=expectStatusText"`
+	// ForbidResponseHeaders fails the probe if the response carries any of these headers, e.g. "X-Debug",
+	// in addition to those forbidden by the prober options.
+	// +optional
+	ForbidResponseHeaders []string `json:"forbidResponseHeaders,omitempty" protobuf:"bytes,10,rep,name=forbidResponseHeaders"`
+	// ExpectBodySHA256 fails the probe unless the hex encoded SHA-256 of the response body matches,
+	// e.g. for endpoints serving immutable content. It overrides the hash of the prober options.
+	// +optional
+	ExpectBodySHA256 string `json:"expectBodySHA256,omitempty" protobuf:"bytes,11,opt,name=expectBodySHA256"`
+	// CloseConnection sends the request with "Connection: close", so the server closes the connection
+	// after responding, even if the prober options keep connections alive.
+	// +optional
+	CloseConnection bool `json:"closeConnection,omitempty" protobuf:"varint,12,opt,name=closeConnection"`
+	// Trailers are sent after the request body, e.g. for servers of streaming protocols that expect them.
+	// The body is then sent with chunked transfer encoding and without a Content-Length. They override the
+	// trailers of the prober options.
+	// +optional
+	Trailers []core.HTTPHeader `json:"trailers,omitempty" protobuf:"bytes,13,rep,name=trailers"`
+	// ExpectServerContains fails the probe unless the Server header of the response contains this text,
+	// ignoring case, e.g. "envoy". It overrides the text of the prober options.
+	// +optional
+	ExpectServerContains string `json:"expectServerContains,omitempty" protobuf:"bytes,14,opt,name=expectServerContains"`
+	// ExpectCharset is the charset the Content-Type of the response must declare, e.g. "utf-8". The body must
+	// also be valid in that charset; this is checked for UTF-8 and US-ASCII. It overrides the charset of the
+	// prober options.
+	// +optional
+	ExpectCharset string `json:"expectCharset,omitempty" protobuf:"bytes,15,opt,name=expectCharset"`
+	// RequireValidUTF8 fails the probe if the response body is not valid UTF-8, or if the Content-Type
+	// declares a charset other than UTF-8 or its subset US-ASCII, even if the prober options do not require it.
+	// +optional
+	RequireValidUTF8 bool `json:"requireValidUTF8,omitempty" protobuf:"varint,16,opt,name=requireValidUTF8"`
+}
+
+// HMACSignature sets a hex encoded HMAC-SHA256 of the request body in a request header.
+type HMACSignature struct {
+	// KeySecretRef selects the key of a Secret in the namespace of the pod that holds the shared secret.
+	// The Secret is read when the pod is probed, and a missing Secret makes the probe Unknown.
+	// The key is never logged.
+	KeySecretRef core.SecretKeySelector `json:"keySecretRef" protobuf:"bytes,1,opt,name=keySecretRef"`
+	// Header is the name of the header that carries the signature. Defaults to X-Signature.
+	// +optional
+	Header string `json:"header,omitempty" protobuf:"bytes,2,opt,name=header"`
 }
 
 type FormEntry struct {
 	Key    string   `json:"key,omitempty" protobuf:"bytes,1,rep,name=key"`
 	Values []string `json:"values,omitempty" protobuf:"bytes,2,rep,name=values"`
 }
+
+// TCPSocketOptions extends the TCPSocket action of a Handler with settings core.TCPSocketAction does not have.
+// It is ignored unless TCPSocket is set.
+type TCPSocketOptions struct {
+	// Protocol runs a check of the application protocol over the connection, one of "redis" or "memcached".
+	// Defaults to the protocol of the prober options, which is a connect check only unless set.
+	// +optional
+	Protocol string `json:"protocol,omitempty" protobuf:"bytes,1,opt,name=protocol"`
+	// Credentials authenticate the Protocol check. Only the redis protocol supports them.
+	// Defaults to the credentials of the prober options.
+	// +optional
+	Credentials *TCPCredentials `json:"credentials,omitempty" protobuf:"bytes,2,opt,name=credentials"`
+}
+
+// TCPCredentials authenticate the protocol check of a TCP probe. The password is never logged
+// nor reported in the output of the probe.
+type TCPCredentials struct {
+	// Username selects a Redis 6 ACL user. Empty authenticates with the password only.
+	// +optional
+	Username string `json:"username,omitempty" protobuf:"bytes,1,opt,name=username"`
+	// PasswordSecretRef selects the key of a Secret in the namespace of the pod that holds the password.
+	// The Secret is read when the pod is probed, and a missing Secret makes the probe Unknown.
+	PasswordSecretRef core.SecretKeySelector `json:"passwordSecretRef" protobuf:"bytes,2,opt,name=passwordSecretRef"`
+}
@@ -46,6 +46,44 @@ func (in *FormEntry) DeepCopy() *FormEntry {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HMACSignature) DeepCopyInto(out *HMACSignature) {
+	*out = *in
+	in.KeySecretRef.DeepCopyInto(&out.KeySecretRef)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HMACSignature.
+func (in *HMACSignature) DeepCopy() *HMACSignature {
+	if in == nil {
+		return nil
+	}
+	out := new(HMACSignature)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPGetOptions) DeepCopyInto(out *HTTPGetOptions) {
+	*out = *in
+	if in.ForbidResponseHeaders != nil {
+		in, out := &in.ForbidResponseHeaders, &out.ForbidResponseHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPGetOptions.
+func (in *HTTPGetOptions) DeepCopy() *HTTPGetOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPGetOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HTTPPostAction) DeepCopyInto(out *HTTPPostAction) {
 	*out = *in
@@ -62,6 +100,21 @@ func (in *HTTPPostAction) DeepCopyInto(out *HTTPPostAction) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Signature != nil {
+		in, out := &in.Signature, &out.Signature
+		*out = new(HMACSignature)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ForbidResponseHeaders != nil {
+		in, out := &in.ForbidResponseHeaders, &out.ForbidResponseHeaders
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Trailers != nil {
+		in, out := &in.Trailers, &out.Trailers
+		*out = make([]corev1.HTTPHeader, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -98,6 +151,16 @@ func (in *Handler) DeepCopyInto(out *Handler) {
 		*out = new(corev1.TCPSocketAction)
 		**out = **in
 	}
+	if in.HTTPGetOptions != nil {
+		in, out := &in.HTTPGetOptions, &out.HTTPGetOptions
+		*out = new(HTTPGetOptions)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TCPSocketOptions != nil {
+		in, out := &in.TCPSocketOptions, &out.TCPSocketOptions
+		*out = new(TCPSocketOptions)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -110,3 +173,41 @@ func (in *Handler) DeepCopy() *Handler {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TCPCredentials) DeepCopyInto(out *TCPCredentials) {
+	*out = *in
+	in.PasswordSecretRef.DeepCopyInto(&out.PasswordSecretRef)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TCPCredentials.
+func (in *TCPCredentials) DeepCopy() *TCPCredentials {
+	if in == nil {
+		return nil
+	}
+	out := new(TCPCredentials)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TCPSocketOptions) DeepCopyInto(out *TCPSocketOptions) {
+	*out = *in
+	if in.Credentials != nil {
+		in, out := &in.Credentials, &out.Credentials
+		*out = new(TCPCredentials)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TCPSocketOptions.
+func (in *TCPSocketOptions) DeepCopy() *TCPSocketOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(TCPSocketOptions)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,339 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+	api_v1 "kmodules.xyz/prober/api/v1"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// CompiledProbe is a probe whose ports, URLs and headers have been resolved ahead of time,
+// so that it can be run repeatedly without redoing that work. It is safe for concurrent use.
+type CompiledProbe struct {
+	pb    *Prober
+	steps []compiledStep
+	// skipped are the steps of a pod that does not meet Prober.Gate, which are recorded but not run.
+	skipped []compiledStep
+}
+
+// ErrPodHasNoIP is returned for probes that address the pod by its IP before it has been assigned one.
+// The probe result is Unknown, as the pod is not reachable yet rather than unhealthy.
+var ErrPodHasNoIP = errors.New("pod has no IP yet")
+
+type compiledStep struct {
+	kind   string
+	target api.Target
+}
+
+// Compile resolves the probe described by p against pod. Changes to p or pod after Compile returns
+// are not reflected in the CompiledProbe.
+func (pb *Prober) Compile(p *api_v1.Handler, pod *core.Pod) (*CompiledProbe, error) {
+	cp := &CompiledProbe{pb: pb}
+	if !pb.Gate.met(pod) {
+		for _, kind := range p.ProbeKinds() {
+			cp.skipped = append(cp.skipped, compiledStep{kind: kind, target: api.Target{Pod: pod, ContainerName: p.ContainerName}})
+		}
+		return cp, nil
+	}
+	if p.Exec != nil {
+		klog.V(5).Infof("Exec-Probe Pod: %v, Container: %v, Command: %v", formatPod(pod), p.ContainerName, p.Exec.Command)
+		cp.steps = append(cp.steps, compiledStep{kind: KindExec, target: api.Target{
+			Pod:           pod,
+			ContainerName: p.ContainerName,
+			Command:       p.Exec.Command,
+		}})
+	}
+	if p.HTTPGet != nil {
+		port, err := extractPort(p.HTTPGet.Port, pod, p.ContainerName)
+		if err != nil {
+			return nil, handleProbeFailure(KindHTTPGet, api.Unknown, "", err)
+		}
+		host, err := pb.resolveHost(p.HTTPGet.Host, pod, pb.podHost)
+		if err != nil {
+			return nil, handleProbeFailure(KindHTTPGet, api.Unknown, "", err)
+		}
+		headers, sensitive, err := pb.probeHeaders(p.HTTPGet.HTTPHeaders)
+		if err != nil {
+			return nil, handleProbeFailure(KindHTTPGet, api.Unknown, "", err)
+		}
+		target := api.Target{
+			URL:              formatHTTPURL(p.HTTPGet.Scheme, host, port, p.HTTPGet.Path),
+			Headers:          headers,
+			SensitiveHeaders: sensitive,
+			Pod:              pod,
+		}
+		if opts := p.HTTPGetOptions; opts != nil {
+			target.ExpectStatusText, err = compileStatusText(opts.ExpectStatusText)
+			if err != nil {
+				return nil, handleProbeFailure(KindHTTPGet, api.Unknown, "", err)
+			}
+			target.ForbidResponseHeaders = opts.ForbidResponseHeaders
+			target.ExpectBodySHA256 = opts.ExpectBodySHA256
+			target.CloseConnection = opts.CloseConnection
+			target.ExpectServerContains = opts.ExpectServerContains
+			target.ExpectCharset = opts.ExpectCharset
+			target.RequireValidUTF8 = opts.RequireValidUTF8
+		}
+		cp.steps = append(cp.steps, compiledStep{kind: KindHTTPGet, target: target})
+	}
+	if p.HTTPPost != nil {
+		port, err := extractPort(p.HTTPPost.Port, pod, p.ContainerName)
+		if err != nil {
+			return nil, handleProbeFailure(KindHTTPPost, api.Unknown, "", err)
+		}
+		host, err := pb.resolveHost(p.HTTPPost.Host, pod, pb.podHost)
+		if err != nil {
+			return nil, handleProbeFailure(KindHTTPPost, api.Unknown, "", err)
+		}
+		form, body := toValues(p.HTTPPost.Form), p.HTTPPost.Body
+		if pb.TemplateRequestBody {
+			if form, body, err = renderRequestBody(form, body, pod); err != nil {
+				return nil, handleProbeFailure(KindHTTPPost, api.Unknown, "", err)
+			}
+		}
+		headers, sensitive, err := pb.probeHeaders(p.HTTPPost.HTTPHeaders)
+		if err != nil {
+			return nil, handleProbeFailure(KindHTTPPost, api.Unknown, "", err)
+		}
+		statusText, err := compileStatusText(p.HTTPPost.ExpectStatusText)
+		if err != nil {
+			return nil, handleProbeFailure(KindHTTPPost, api.Unknown, "", err)
+		}
+		target := api.Target{
+			URL:                   formatHTTPURL(p.HTTPPost.Scheme, host, port, p.HTTPPost.Path),
+			Headers:               headers,
+			SensitiveHeaders:      sensitive,
+			Form:                  form,
+			Pod:                   pod,
+			Body:                  body,
+			ExpectStatusText:      statusText,
+			ForbidResponseHeaders: p.HTTPPost.ForbidResponseHeaders,
+			ExpectBodySHA256:      p.HTTPPost.ExpectBodySHA256,
+			CloseConnection:       p.HTTPPost.CloseConnection,
+			ExpectServerContains:  p.HTTPPost.ExpectServerContains,
+			ExpectCharset:         p.HTTPPost.ExpectCharset,
+			RequireValidUTF8:      p.HTTPPost.RequireValidUTF8,
+		}
+		if len(p.HTTPPost.Trailers) > 0 {
+			target.Trailers = buildHeader(p.HTTPPost.Trailers)
+		}
+		if sig := p.HTTPPost.Signature; sig != nil {
+			target.SigningKeySecret, target.SignatureHeader = sig.KeySecretRef.DeepCopy(), sig.Header
+		}
+		cp.steps = append(cp.steps, compiledStep{kind: KindHTTPPost, target: target})
+	}
+	if p.TCPSocket != nil {
+		port, err := extractPort(p.TCPSocket.Port, pod, p.ContainerName)
+		if err != nil {
+			return nil, handleProbeFailure(KindTCP, api.Unknown, "", err)
+		}
+		host, err := pb.resolveHost(p.TCPSocket.Host, pod, podIP)
+		if err != nil {
+			return nil, handleProbeFailure(KindTCP, api.Unknown, "", err)
+		}
+		klog.V(5).Infof("TCP-Probe Host: %v, Port: %v", host, port)
+		target := api.Target{Host: host, Port: port, Pod: pod}
+		if opts := p.TCPSocketOptions; opts != nil {
+			target.Protocol = opts.Protocol
+			if creds := opts.Credentials; creds != nil {
+				target.Username, target.PasswordSecret = creds.Username, creds.PasswordSecretRef.DeepCopy()
+			}
+		}
+		cp.steps = append(cp.steps, compiledStep{kind: KindTCP, target: target})
+	}
+	return cp, nil
+}
+
+// Run runs the compiled probe. It returns an error describing the first probe that did not succeed.
+func (cp *CompiledProbe) Run(ctx context.Context, timeout time.Duration) error {
+	_, err := cp.run(ctx, timeout)
+	return err
+}
+
+// run runs the compiled probe and returns its overall result, which is the result of the first probe
+// that did not pass, or Warning if a probe passed with a warning. Skipped probes pass.
+func (cp *CompiledProbe) run(ctx context.Context, timeout time.Duration) (api.Result, error) {
+	result := api.Success
+	for i := range cp.skipped {
+		step := &cp.skipped[i]
+		cp.pb.recordSkipped(step.kind, step.target)
+		cp.pb.observeSkipped(ctx, step.kind, step.target)
+	}
+	for i := range cp.steps {
+		step := &cp.steps[i]
+		target := step.target
+		target.Timeout = timeout
+		res, resp, err := cp.pb.RunKind(ctx, step.kind, target)
+		switch res {
+		case api.Success:
+		case api.Warning:
+			result = api.Warning
+		default:
+			return res, handleProbeFailure(step.kind, res, resp, err)
+		}
+	}
+	return result, nil
+}
+
+// resolveHost returns host, or the address of pod returned by podAddress if host is empty.
+// It returns ErrPodHasNoIP if that address is not known yet.
+func (pb *Prober) resolveHost(host string, pod *core.Pod, podAddress func(*core.Pod) string) (string, error) {
+	if host != "" {
+		return host, nil
+	}
+	if pod == nil {
+		return "", errors.New("no host given and no pod to probe")
+	}
+	if host = podAddress(pod); host == "" {
+		return "", fmt.Errorf("%w: %s", ErrPodHasNoIP, formatPod(pod))
+	}
+	return host, nil
+}
+
+// compileStatusText compiles the ExpectStatusText of an HTTP action, which is nil if it is not set.
+func compileStatusText(expr string) (*regexp.Regexp, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expectStatusText: %v", err)
+	}
+	return re, nil
+}
+
+func podIP(pod *core.Pod) string {
+	return pod.Status.PodIP
+}
+
+func formatHTTPURL(scheme core.URIScheme, host string, port int, path string) *url.URL {
+	klog.V(5).Infof("HTTP-Probe Host: %v://%v, Port: %v, Path: %v", scheme, host, port, path)
+	return formatURL(strings.ToLower(string(scheme)), host, port, path)
+}
+
+// probeHeaders builds the request headers of an HTTP probe, expanding environment variables with
+// ExpandHeaderEnv. It also returns the names of the headers that referenced a variable.
+func (pb *Prober) probeHeaders(headerList []core.HTTPHeader) (http.Header, []string, error) {
+	if !pb.ExpandHeaderEnv {
+		return buildHeader(headerList), nil, nil
+	}
+	expanded := make([]core.HTTPHeader, len(headerList))
+	var sensitive []string
+	for i, header := range headerList {
+		value, referenced, err := expandEnv(header.Value)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to expand header %s: %w", header.Name, err)
+		}
+		if referenced {
+			sensitive = append(sensitive, header.Name)
+		}
+		expanded[i] = core.HTTPHeader{Name: header.Name, Value: value}
+	}
+	return buildHeader(expanded), sensitive, nil
+}
+
+// expandEnv replaces the ${NAME} references in value with the values of the environment variables.
+// "$$" is a literal "$", other "$" are kept as is. It reports whether value referenced a variable.
+func expandEnv(value string) (string, bool, error) {
+	if !strings.Contains(value, "$") {
+		return value, false, nil
+	}
+	var buf strings.Builder
+	referenced := false
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 == len(value) {
+			buf.WriteByte(value[i])
+			continue
+		}
+		switch value[i+1] {
+		case '$':
+			buf.WriteByte('$')
+			i++
+		case '{':
+			end := strings.IndexByte(value[i+2:], '}')
+			if end < 0 {
+				return "", false, errors.New("unterminated environment variable reference")
+			}
+			name := value[i+2 : i+2+end]
+			if name == "" {
+				return "", false, errors.New("empty environment variable reference")
+			}
+			v, ok := os.LookupEnv(name)
+			if !ok {
+				return "", false, fmt.Errorf("environment variable %s is not set", name)
+			}
+			buf.WriteString(v)
+			referenced = true
+			i += 2 + end
+		default:
+			buf.WriteByte('$')
+		}
+	}
+	return buf.String(), referenced, nil
+}
+
+// renderRequestBody renders the form values and body of an HTTP POST probe as text/templates against pod.
+func renderRequestBody(form url.Values, body string, pod *core.Pod) (url.Values, string, error) {
+	render := func(text string) (string, error) {
+		if !strings.Contains(text, "{{") {
+			return text, nil
+		}
+		tpl, err := template.New("body").Option("missingkey=error").Parse(text)
+		if err != nil {
+			return "", fmt.Errorf("invalid request body template: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := tpl.Execute(&buf, pod); err != nil {
+			return "", fmt.Errorf("failed to render request body: %v", err)
+		}
+		return buf.String(), nil
+	}
+
+	var err error
+	if body, err = render(body); err != nil {
+		return nil, "", err
+	}
+	if form == nil {
+		return nil, body, nil
+	}
+	out := make(url.Values, len(form))
+	for k, values := range form {
+		rendered := make([]string, len(values))
+		for i, v := range values {
+			if rendered[i], err = render(v); err != nil {
+				return nil, "", err
+			}
+		}
+		out[k] = rendered
+	}
+	return out, body, nil
+}
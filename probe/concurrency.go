@@ -0,0 +1,137 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+)
+
+// LimitMode selects what happens to a probe when its target already has the maximum number of probes in flight.
+type LimitMode string
+
+const (
+	// LimitModeWait blocks until a slot is free or the probe deadline expires.
+	LimitModeWait LimitMode = "Wait"
+	// LimitModeFailFast returns immediately with an Unknown result.
+	LimitModeFailFast LimitMode = "FailFast"
+)
+
+// targetLimiter bounds the number of concurrent probes per host:port.
+type targetLimiter struct {
+	lock  sync.Mutex
+	slots map[string]*targetSlots
+}
+
+// targetSlots is the semaphore of a target. It is dropped from the targetLimiter once no probe holds
+// or waits for one of its slots.
+type targetSlots struct {
+	ch chan struct{}
+	// users counts the probes that hold or wait for a slot.
+	users int
+}
+
+// get returns the semaphore of key, counting the caller as a user until it calls put.
+func (l *targetLimiter) get(key string, limit int) *targetSlots {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.slots == nil {
+		l.slots = map[string]*targetSlots{}
+	}
+	s, ok := l.slots[key]
+	if !ok || cap(s.ch) != limit {
+		s = &targetSlots{ch: make(chan struct{}, limit)}
+		l.slots[key] = s
+	}
+	s.users++
+	return s
+}
+
+// put stops counting the caller as a user of s, and drops s once it has none.
+func (l *targetLimiter) put(key string, s *targetSlots) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	s.users--
+	if s.users == 0 && l.slots[key] == s {
+		delete(l.slots, key)
+	}
+}
+
+// acquire reserves a slot for key and returns the function that releases it, together with what is left
+// of timeout after waiting for the slot, which is the timeout of the probe then.
+func (pb *Prober) acquire(ctx context.Context, key string, timeout time.Duration) (func(), time.Duration, error) {
+	limit := pb.MaxConcurrentProbesPerTarget
+	if limit <= 0 || key == "" {
+		return func() {}, timeout, nil
+	}
+	s := pb.limiter.get(key, limit)
+	release := func() {
+		<-s.ch
+		pb.limiter.put(key, s)
+	}
+
+	if pb.LimitMode == LimitModeFailFast {
+		select {
+		case s.ch <- struct{}{}:
+			return release, timeout, nil
+		default:
+			pb.limiter.put(key, s)
+			return nil, 0, fmt.Errorf("too many concurrent probes to %s (limit %d)", key, limit)
+		}
+	}
+
+	start := time.Now()
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case s.ch <- struct{}{}:
+		if timeout <= 0 {
+			return release, timeout, nil
+		}
+		if remaining := timeout - time.Since(start); remaining > 0 {
+			return release, remaining, nil
+		}
+		release()
+	case <-ctx.Done():
+		pb.limiter.put(key, s)
+		return nil, 0, fmt.Errorf("waiting for a probe slot to %s: %w", key, ctx.Err())
+	case <-expired:
+		pb.limiter.put(key, s)
+	}
+	return nil, 0, fmt.Errorf("timed out after %v waiting for a probe slot to %s (limit %d)", timeout, key, limit)
+}
+
+// targetKey returns the host:port a probe connects to, or "" if it does not connect to a network address.
+func targetKey(target api.Target) string {
+	if target.URL != nil {
+		return target.URL.Host
+	}
+	if target.Host != "" || target.Port != 0 {
+		return net.JoinHostPort(target.Host, strconv.Itoa(target.Port))
+	}
+	return ""
+}
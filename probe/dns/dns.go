@@ -0,0 +1,189 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+
+	"k8s.io/klog/v2"
+)
+
+// RecordType selects the DNS records checked by a probe.
+type RecordType string
+
+const (
+	// RecordTypeA checks the IPv4 addresses of the name.
+	RecordTypeA RecordType = "A"
+	// RecordTypeAAAA checks the IPv6 addresses of the name.
+	RecordTypeAAAA RecordType = "AAAA"
+	// RecordTypeCNAME checks the canonical name of the name, without resolving its addresses.
+	RecordTypeCNAME RecordType = "CNAME"
+)
+
+// New creates a Prober.
+func New() Prober {
+	return dnsProber{}
+}
+
+// NewWithOptions creates a Prober with additional options.
+func NewWithOptions(opts Options) Prober {
+	return dnsProber{opts}
+}
+
+// Options holds the optional settings of the DNS prober.
+// The zero value keeps the default probe behavior.
+type Options struct {
+	// Resolver looks up the names. Defaults to net.DefaultResolver.
+	// +optional
+	Resolver *net.Resolver
+
+	// RecordType selects the records that are resolved. Defaults to both A and AAAA records.
+	// +optional
+	RecordType RecordType
+
+	// ExpectAddresses fails the probe unless the resolved addresses include each of these IPs,
+	// e.g. the cluster IP of a Service. The failure reports the addresses resolved.
+	// +optional
+	ExpectAddresses []string
+
+	// ExpectCNAME fails the probe unless the canonical name of the name matches, e.g. "db.example.com".
+	// A trailing dot and case are ignored. The failure reports the canonical name resolved.
+	// +optional
+	ExpectCNAME string
+}
+
+// Prober is an interface that defines the Probe function for doing DNS lookups.
+type Prober interface {
+	// Probe resolves name and checks the records against the expected values. The probe passes
+	// if name resolves and the records match, with the resolved values as output.
+	Probe(name string, timeout time.Duration) (api.Result, string, error)
+}
+
+type dnsProber struct {
+	opts Options
+}
+
+// Probe resolves name within timeout.
+func (pr dnsProber) Probe(name string, timeout time.Duration) (api.Result, string, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return doDNSProbe(ctx, name, &pr.opts)
+}
+
+// NewTargetProber adapts a Prober to the unified api.Prober interface.
+func NewTargetProber(p Prober) api.Prober {
+	return targetProber{p}
+}
+
+type targetProber struct {
+	Prober
+}
+
+// Probe resolves target.Host.
+func (pr targetProber) Probe(ctx context.Context, target api.Target) (api.Result, string, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Unknown, "", err
+	}
+	return pr.Prober.Probe(target.Host, api.TimeoutFor(ctx, target))
+}
+
+func doDNSProbe(ctx context.Context, name string, opts *Options) (api.Result, string, error) {
+	switch opts.RecordType {
+	case "", RecordTypeA, RecordTypeAAAA, RecordTypeCNAME:
+	default:
+		return api.Unknown, "", fmt.Errorf("unsupported DNS record type %q", opts.RecordType)
+	}
+	resolver := opts.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	var resolved []string
+	if opts.RecordType == RecordTypeCNAME || opts.ExpectCNAME != "" {
+		cname, err := resolver.LookupCNAME(ctx, name)
+		if err != nil {
+			klog.V(4).Infof("DNS probe failed to resolve %s: %v", name, err)
+			return api.Failure, fmt.Sprintf("failed to resolve CNAME of %s: %v", name, err), nil
+		}
+		if opts.ExpectCNAME != "" && canonical(cname) != canonical(opts.ExpectCNAME) {
+			return api.Failure, fmt.Sprintf("%s has CNAME %s, expected %s", name, cname, opts.ExpectCNAME), nil
+		}
+		resolved = append(resolved, cname)
+	}
+
+	if opts.RecordType != RecordTypeCNAME {
+		ips, err := resolver.LookupIP(ctx, opts.network(), name)
+		if err != nil {
+			klog.V(4).Infof("DNS probe failed to resolve %s: %v", name, err)
+			return api.Failure, fmt.Sprintf("failed to resolve %s: %v", name, err), nil
+		}
+		addrs := make([]string, 0, len(ips))
+		for _, ip := range ips {
+			addrs = append(addrs, ip.String())
+		}
+		sort.Strings(addrs)
+		if missing := missingAddresses(opts.ExpectAddresses, ips); len(missing) > 0 {
+			return api.Failure, fmt.Sprintf("%s resolved to %q, expected to include %q", name, addrs, missing), nil
+		}
+		resolved = append(resolved, addrs...)
+	}
+	return api.Success, strings.Join(resolved, ", "), nil
+}
+
+// network returns the network passed to net.Resolver.LookupIP for the record type.
+func (opts *Options) network() string {
+	switch opts.RecordType {
+	case RecordTypeA:
+		return "ip4"
+	case RecordTypeAAAA:
+		return "ip6"
+	}
+	return "ip"
+}
+
+// missingAddresses returns the addresses in expected that are not in ips.
+func missingAddresses(expected []string, ips []net.IP) []string {
+	var missing []string
+	for _, addr := range expected {
+		found := false
+		for _, ip := range ips {
+			if ip.Equal(net.ParseIP(addr)) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, addr)
+		}
+	}
+	return missing
+}
+
+func canonical(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
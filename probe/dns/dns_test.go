@@ -0,0 +1,159 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dns
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// zone maps names to their records. A CNAME record is followed by the records of its target.
+type zone map[string]struct {
+	cname string
+	a     [][4]byte
+	aaaa  [][16]byte
+}
+
+// startServer serves z over UDP and returns a resolver that queries it.
+func startServer(t *testing.T, z zone) *net.Resolver {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if resp, err := z.answer(buf[:n]); err == nil {
+				_, _ = conn.WriteTo(resp, addr)
+			}
+		}
+	}()
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "udp", conn.LocalAddr().String())
+		},
+	}
+}
+
+func (z zone) answer(query []byte) ([]byte, error) {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(query); err != nil {
+		return nil, err
+	}
+	msg.Header.Response = true
+	msg.Header.Authoritative = true
+	for _, q := range msg.Questions {
+		name := strings.TrimSuffix(q.Name.String(), ".")
+		records, ok := z[name]
+		if !ok {
+			msg.Header.RCode = dnsmessage.RCodeNameError
+			break
+		}
+		if records.cname != "" {
+			target := dnsmessage.MustNewName(records.cname + ".")
+			msg.Answers = append(msg.Answers, dnsmessage.Resource{
+				Header: dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypeCNAME, Class: dnsmessage.ClassINET},
+				Body:   &dnsmessage.CNAMEResource{CNAME: target},
+			})
+			q.Name, records = target, z[records.cname]
+		}
+		header := dnsmessage.ResourceHeader{Name: q.Name, Type: q.Type, Class: dnsmessage.ClassINET}
+		switch q.Type {
+		case dnsmessage.TypeA:
+			for _, a := range records.a {
+				msg.Answers = append(msg.Answers, dnsmessage.Resource{Header: header, Body: &dnsmessage.AResource{A: a}})
+			}
+		case dnsmessage.TypeAAAA:
+			for _, aaaa := range records.aaaa {
+				msg.Answers = append(msg.Answers, dnsmessage.Resource{Header: header, Body: &dnsmessage.AAAAResource{AAAA: aaaa}})
+			}
+		}
+	}
+	return msg.Pack()
+}
+
+func TestDNSProbe(t *testing.T) {
+	resolver := startServer(t, zone{
+		"svc.example.com": {
+			a:    [][4]byte{{10, 0, 0, 1}, {10, 0, 0, 2}},
+			aaaa: [][16]byte{{0xfd, 0x00, 15: 1}},
+		},
+		"db.example.com": {cname: "primary.example.com"},
+		"primary.example.com": {
+			a: [][4]byte{{10, 0, 1, 1}},
+		},
+	})
+
+	tests := []struct {
+		name           string
+		host           string
+		opts           Options
+		expectedStatus api.Result
+		expectedOutput string
+	}{
+		{"resolves", "svc.example.com", Options{}, api.Success, "10.0.0.1, 10.0.0.2, fd00::1"},
+		{"A records", "svc.example.com", Options{RecordType: RecordTypeA}, api.Success, "10.0.0.1, 10.0.0.2"},
+		{"AAAA records", "svc.example.com", Options{RecordType: RecordTypeAAAA}, api.Success, "fd00::1"},
+		{"expected addresses", "svc.example.com", Options{ExpectAddresses: []string{"10.0.0.2", "fd00:0::1"}}, api.Success, ""},
+		{
+			"missing address", "svc.example.com", Options{RecordType: RecordTypeA, ExpectAddresses: []string{"10.0.0.1", "10.0.0.3"}}, api.Failure,
+			`svc.example.com resolved to ["10.0.0.1" "10.0.0.2"], expected to include ["10.0.0.3"]`,
+		},
+		{"not found", "missing.example.com", Options{}, api.Failure, "failed to resolve missing.example.com"},
+		{"CNAME", "db.example.com", Options{RecordType: RecordTypeCNAME}, api.Success, "primary.example.com."},
+		{"expected CNAME", "db.example.com", Options{ExpectCNAME: "Primary.example.com", ExpectAddresses: []string{"10.0.1.1"}}, api.Success, "primary.example.com., 10.0.1.1"},
+		{
+			"wrong CNAME", "db.example.com", Options{ExpectCNAME: "replica.example.com"}, api.Failure,
+			"db.example.com has CNAME primary.example.com., expected replica.example.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Resolver = resolver
+			status, output, err := NewWithOptions(tt.opts).Probe(tt.host, 5*time.Second)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if status != tt.expectedStatus {
+				t.Errorf("expected status=%v, get=%v, output=%q", tt.expectedStatus, status, output)
+			}
+			if !strings.HasPrefix(output, tt.expectedOutput) {
+				t.Errorf("expected output=%q, get=%q", tt.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestDNSProbe_UnsupportedRecordType(t *testing.T) {
+	status, _, err := NewWithOptions(Options{RecordType: "MX"}).Probe("example.com", time.Second)
+	if status != api.Unknown || err == nil {
+		t.Errorf("expected status=%v with an error, get=%v, err=%v", api.Unknown, status, err)
+	}
+}
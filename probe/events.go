@@ -0,0 +1,108 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"container/list"
+	"sync"
+
+	api "kmodules.xyz/prober/api"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Reasons of the Events recorded by a Prober with an EventRecorder.
+const (
+	EventReasonProbeFailed    = "ProbeFailed"
+	EventReasonProbeRecovered = "ProbeRecovered"
+)
+
+// EventRecorder records Kubernetes Events. The record.EventRecorder of k8s.io/client-go/tools/record implements it.
+type EventRecorder interface {
+	Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{})
+}
+
+// maxProbeStates bounds the number of probes whose state a Prober keeps for its EventRecorder.
+// Probes of pods that are gone are not run anymore, so they are the first to be forgotten.
+const maxProbeStates = 4096
+
+// probeStates tracks whether the last probe of each pod, kind and target passed. It keeps the state of
+// the size most recently run probes, forgetting the least recently run one when it is full.
+type probeStates struct {
+	lock sync.Mutex
+	// size is the maximum number of probes tracked. Zero uses maxProbeStates.
+	size    int
+	entries map[string]*list.Element
+	// lru orders the probeState entries from the most to the least recently run probe.
+	lru list.List
+}
+
+type probeState struct {
+	key     string
+	passing bool
+}
+
+// update stores whether the probe identified by key passed, and reports whether that changed.
+// A probe whose state is not known yet changes if it fails, but not if it passes.
+func (s *probeStates) update(key string, passing bool) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if e, ok := s.entries[key]; ok {
+		state := e.Value.(*probeState)
+		changed := state.passing != passing
+		state.passing = passing
+		s.lru.MoveToFront(e)
+		return changed
+	}
+	if s.entries == nil {
+		s.entries = map[string]*list.Element{}
+	}
+	size := s.size
+	if size <= 0 {
+		size = maxProbeStates
+	}
+	if s.lru.Len() >= size {
+		oldest := s.lru.Back()
+		delete(s.entries, oldest.Value.(*probeState).key)
+		s.lru.Remove(oldest)
+	}
+	s.entries[key] = s.lru.PushFront(&probeState{key: key, passing: passing})
+	return !passing
+}
+
+// recordEvent records an Event against the probed pod when the probe starts failing or recovers.
+// Success and Warning results count as passing.
+func (pb *Prober) recordEvent(kind string, target api.Target, result api.Result, output string, err error) {
+	if pb.EventRecorder == nil || target.Pod == nil {
+		return
+	}
+	desc := describeTarget(target)
+	passing := result == api.Success || result == api.Warning
+	if !pb.states.update(string(target.Pod.UID)+"/"+kind+"/"+desc, passing) {
+		return
+	}
+	if passing {
+		pb.EventRecorder.Eventf(target.Pod, core.EventTypeNormal, EventReasonProbeRecovered, "%s probe of %s succeeded", kind, desc)
+		return
+	}
+	reason := output
+	if err != nil {
+		reason = err.Error()
+	}
+	pb.EventRecorder.Eventf(target.Pod, core.EventTypeWarning, EventReasonProbeFailed, "%s probe of %s failed: %s", kind, desc, reason)
+}
@@ -18,52 +18,219 @@ package exec
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	exec_util "kmodules.xyz/client-go/tools/exec"
 	"kmodules.xyz/prober/api"
 
 	core "k8s.io/api/core/v1"
 	"k8s.io/client-go/rest"
+	utilexec "k8s.io/client-go/util/exec"
 )
 
 const (
 	maxReadLength = 10 * 1 << 10 // 10KB
 )
 
+// execIntoPod runs the command of a probe. It is replaced in tests by a fake stream.
+var execIntoPod = exec_util.ExecIntoPod
+
 // New creates a Prober.
 func New() Prober {
 	return execProber{}
 }
 
+// NewWithOptions creates a Prober with additional options.
+func NewWithOptions(opts Options) Prober {
+	return execProber{opts}
+}
+
+// Options holds the optional settings of the exec prober.
+// The zero value keeps the default probe behavior.
+type Options struct {
+	// MaxOutputLength caps the captured stdout and stderr of the command, each, in bytes.
+	// Output beyond the cap is discarded while the command keeps running, and the probe
+	// output notes the truncation. Defaults to 10KB. The probe output is the stdout of the
+	// command, followed by its stderr, if any, after a "[stderr]" line.
+	// +optional
+	MaxOutputLength int64
+	// TTY allocates a pseudo-terminal for the command, for scripts that behave differently when interactive.
+	// A terminal merges stderr into stdout, so both end up in the probe output, with line endings
+	// normalized to "\n".
+	// +optional
+	TTY bool
+
+	// SuccessExitCodes are non-zero exit codes of the command that also pass the probe,
+	// e.g. a code meaning "healthy but warming". Exit code 0 always passes.
+	// +optional
+	SuccessExitCodes []int
+	// WarningExitCodes are exit codes of the command that make the probe result a Warning.
+	// +optional
+	WarningExitCodes []int
+
+	// SoftTimeout makes the probe result a Warning when the command passes, but takes longer than this,
+	// e.g. to catch health scripts that are getting slower before they start failing. The output then
+	// reports the measured duration of the command. The command is not stopped when it is exceeded:
+	// exec probes have no hard timeout, they wait for the command to exit.
+	// +optional
+	SoftTimeout time.Duration
+}
+
 // Prober is an interface defining the Probe object for container readiness/liveness checks.
 type Prober interface {
 	Probe(config *rest.Config, pod *core.Pod, containerName string, commands []string) (api.Result, string, error)
 }
 
-type execProber struct{}
+type execProber struct {
+	opts Options
+}
 
 // Probe executes a command to check the liveness/readiness of container
 // from executing a command. Returns the Result status, command output, and
 // errors if any.
 func (pr execProber) Probe(config *rest.Config, pod *core.Pod, containerName string, commands []string) (api.Result, string, error) {
-	// limit output and error msg size to 10KB
+	limit := pr.opts.MaxOutputLength
+	if limit <= 0 {
+		limit = maxReadLength
+	}
+	// limit output and error msg size, so that a command flooding its output can not exhaust memory
 	var outBuffer, errBuffer bytes.Buffer
-	stdOut := LimitWriter(&outBuffer, maxReadLength)
-	stdErr := LimitWriter(&errBuffer, maxReadLength)
+	stdOut := truncateWriter(&outBuffer, limit)
+	stdErr := truncateWriter(&errBuffer, limit)
 
 	container := containerName
 	if container == "" {
 		container = pod.Spec.Containers[0].Name
 	}
 
-	data, err := exec_util.ExecIntoPod(config, pod, func(opt *exec_util.Options) {
+	start := time.Now()
+	_, err := execIntoPod(config, pod, func(opt *exec_util.Options) {
 		opt.Container = container
 		opt.Command = commands
 		opt.StreamOptions.Stdout = stdOut
 		opt.StreamOptions.Stderr = stdErr
+		if pr.opts.TTY {
+			// the api server rejects stderr along with a tty, the terminal writes it to stdout instead
+			opt.PodExecOptions.TTY = true
+			opt.PodExecOptions.Stderr = false
+			opt.StreamOptions.Tty = true
+			opt.StreamOptions.Stderr = nil
+		}
 	})
+	elapsed := time.Since(start)
+	output := outBuffer.String()
+	if pr.opts.TTY {
+		output = strings.ReplaceAll(output, "\r\n", "\n")
+		if stdOut.truncated {
+			// the cap may split a line ending
+			output = strings.TrimSuffix(output, "\r")
+		}
+	}
+	if errBuffer.Len() > 0 {
+		if output != "" && !strings.HasSuffix(output, "\n") {
+			output += "\n"
+		}
+		output += "[stderr]\n" + errBuffer.String()
+	}
+	if stdOut.truncated || stdErr.truncated {
+		output += fmt.Sprintf("\n[output truncated at %d bytes]", limit)
+	}
+	result, output, err := pr.result(output, err)
+	result, output = pr.softTimeout(result, output, elapsed)
+	return result, output, err
+}
+
+// result maps the outcome of the command to the probe result, based on its exit code.
+// Errors that do not come from the command, e.g. when the exec endpoint of the api server
+// can not be reached or rejects the request, make the result Unknown.
+func (pr execProber) result(output string, err error) (api.Result, string, error) {
 	if err != nil {
-		return api.Failure, data, err
+		code, ok := exitCode(err)
+		if !ok {
+			if commandFailed(err) {
+				return api.Failure, output, err
+			}
+			return api.Unknown, output, err
+		}
+		output += fmt.Sprintf("\n[exit code %d]", code)
+		switch {
+		case containsCode(pr.opts.SuccessExitCodes, code):
+			return api.Success, output, nil
+		case containsCode(pr.opts.WarningExitCodes, code):
+			return api.Warning, output, nil
+		}
+		return api.Failure, output, err
+	}
+	return api.Success, output, nil
+}
+
+// softTimeout downgrades a passing result to Warning if the command took longer than SoftTimeout,
+// and reports the duration of the command when SoftTimeout is set.
+func (pr execProber) softTimeout(result api.Result, output string, elapsed time.Duration) (api.Result, string) {
+	if pr.opts.SoftTimeout <= 0 {
+		return result, output
+	}
+	took := elapsed.Round(time.Millisecond)
+	if result == api.Success && elapsed > pr.opts.SoftTimeout {
+		return api.Warning, output + fmt.Sprintf("\n[took %v, exceeding the soft timeout of %v]", took, pr.opts.SoftTimeout)
+	}
+	return result, output + fmt.Sprintf("\n[took %v]", took)
+}
+
+// exitCodeRegexp matches the error of a command that exited with a non-zero code.
+// exec_util formats the error of the stream with %v, so only the message of utilexec.CodeExitError survives.
+var exitCodeRegexp = regexp.MustCompile(`command terminated with exit code (\d+)`)
+
+// exitCode returns the exit code of the command that failed with err, if it exited.
+func exitCode(err error) (int, bool) {
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) && exitErr.Exited() {
+		return exitErr.ExitStatus(), true
+	}
+	m := exitCodeRegexp.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0, false
+	}
+	code, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, false
+	}
+	return code, true
+}
+
+// commandFailed reports whether err comes from a command that ran but failed without reporting its exit code.
+func commandFailed(err error) bool {
+	return strings.Contains(err.Error(), "command terminated with non-zero exit code")
+}
+
+func containsCode(codes []int, code int) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+// NewTargetProber adapts a Prober to the unified api.Prober interface.
+func NewTargetProber(p Prober) api.Prober {
+	return targetProber{p}
+}
+
+type targetProber struct {
+	Prober
+}
+
+// Probe executes target.Command in target.ContainerName of target.Pod.
+func (pr targetProber) Probe(ctx context.Context, target api.Target) (api.Result, string, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Unknown, "", err
 	}
-	return api.Success, data, nil
+	return pr.Prober.Probe(target.Config, target.Pod, target.ContainerName, target.Command)
 }
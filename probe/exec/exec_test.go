@@ -0,0 +1,227 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package exec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	exec_util "kmodules.xyz/client-go/tools/exec"
+	"kmodules.xyz/prober/api"
+
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+func TestExecProberResult(t *testing.T) {
+	// exec_util reports a non-zero exit code as a formatted error.
+	exited := func(code int) error {
+		return fmt.Errorf("could not execute: command terminated with exit code %d", code)
+	}
+	opts := Options{SuccessExitCodes: []int{2}, WarningExitCodes: []int{3}}
+
+	tests := map[string]struct {
+		opts   Options
+		err    error
+		result api.Result
+		output string
+		hasErr bool
+	}{
+		"exit 0":             {Options{}, nil, api.Success, "ok", false},
+		"exit 1 by default":  {Options{}, exited(1), api.Failure, "ok\n[exit code 1]", true},
+		"exit 2 by default":  {Options{}, exited(2), api.Failure, "ok\n[exit code 2]", true},
+		"success exit code":  {opts, exited(2), api.Success, "ok\n[exit code 2]", false},
+		"warning exit code":  {opts, exited(3), api.Warning, "ok\n[exit code 3]", false},
+		"unlisted exit code": {opts, exited(1), api.Failure, "ok\n[exit code 1]", true},
+		"typed exit error":   {opts, utilexec.CodeExitError{Err: errors.New("exit"), Code: 2}, api.Success, "ok\n[exit code 2]", false},
+		"no exit code":       {opts, errors.New("could not execute: command terminated with non-zero exit code: error"), api.Failure, "ok", true},
+		"container missing":  {opts, errors.New("could not execute: container not found"), api.Unknown, "ok", true},
+		"executor setup":     {opts, errors.New("failed to init executor: unknown scheme"), api.Unknown, "ok", true},
+		"api unreachable":    {opts, errors.New("could not execute: error dialing backend: dial tcp: connection refused"), api.Unknown, "ok", true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, output, err := execProber{tt.opts}.result("ok", tt.err)
+			assert.Equal(t, tt.result, result)
+			assert.Equal(t, tt.output, output)
+			assert.Equal(t, tt.hasErr, err != nil)
+		})
+	}
+}
+
+func TestExecProberSoftTimeout(t *testing.T) {
+	tests := map[string]struct {
+		softTimeout time.Duration
+		result      api.Result
+		elapsed     time.Duration
+		expected    api.Result
+		output      string
+	}{
+		"not set":           {0, api.Success, 3 * time.Second, api.Success, "ok"},
+		"within":            {2 * time.Second, api.Success, 1500 * time.Millisecond, api.Success, "ok\n[took 1.5s]"},
+		"exceeded":          {2 * time.Second, api.Success, 2500 * time.Millisecond, api.Warning, "ok\n[took 2.5s, exceeding the soft timeout of 2s]"},
+		"exceeded, warning": {2 * time.Second, api.Warning, 2500 * time.Millisecond, api.Warning, "ok\n[took 2.5s]"},
+		"exceeded, failed":  {2 * time.Second, api.Failure, 2500 * time.Millisecond, api.Failure, "ok\n[took 2.5s]"},
+		"rounded to 1ms":    {time.Second, api.Success, 1234567 * time.Microsecond, api.Warning, "ok\n[took 1.235s, exceeding the soft timeout of 1s]"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, output := execProber{Options{SoftTimeout: tt.softTimeout}}.softTimeout(tt.result, "ok", tt.elapsed)
+			assert.Equal(t, tt.expected, result)
+			assert.Equal(t, tt.output, output)
+		})
+	}
+}
+
+func TestExecProber_APIUnavailable(t *testing.T) {
+	pod := &core.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+		Spec:       core.PodSpec{Containers: []core.Container{{Name: "app"}}},
+	}
+	forbidden := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "forbidden", http.StatusForbidden)
+	}))
+	defer forbidden.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	tests := map[string]string{
+		"rejected":    forbidden.URL,
+		"unreachable": closed.URL,
+	}
+	for name, host := range tests {
+		t.Run(name, func(t *testing.T) {
+			result, _, err := New().Probe(&rest.Config{Host: host}, pod, "", []string{"true"})
+			assert.Equal(t, api.Unknown, result)
+			assert.Error(t, err)
+		})
+	}
+}
+
+// fakeExec replaces execIntoPod with a stream that writes stdout and stderr, and returns the options the command is run with.
+func fakeExec(t *testing.T, stdout, stderr string) *exec_util.Options {
+	ran := &exec_util.Options{
+		PodExecOptions: core.PodExecOptions{Stdout: true, Stderr: true},
+	}
+	execIntoPod = func(config *rest.Config, pod *core.Pod, options ...func(*exec_util.Options)) (string, error) {
+		for _, option := range options {
+			option(ran)
+		}
+		if _, err := io.WriteString(ran.StreamOptions.Stdout, stdout); err != nil {
+			return "", err
+		}
+		if ran.StreamOptions.Stderr != nil {
+			if _, err := io.WriteString(ran.StreamOptions.Stderr, stderr); err != nil {
+				return "", err
+			}
+		}
+		return "", nil
+	}
+	t.Cleanup(func() { execIntoPod = exec_util.ExecIntoPod })
+	return ran
+}
+
+func TestExecProber_TTY(t *testing.T) {
+	pod := &core.Pod{Spec: core.PodSpec{Containers: []core.Container{{Name: "app"}}}}
+
+	tests := map[string]struct {
+		maxOutputLength int64
+		stdout          string
+		output          string
+	}{
+		"crlf":                {0, "ready\r\nall good\r\n", "ready\nall good\n"},
+		"truncated":           {9, "ready\r\nall good\r\n", "ready\nal\n[output truncated at 9 bytes]"},
+		"truncated in a crlf": {6, "ready\r\nall good\r\n", "ready\n[output truncated at 6 bytes]"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ran := fakeExec(t, tt.stdout, "")
+			result, output, err := NewWithOptions(Options{TTY: true, MaxOutputLength: tt.maxOutputLength}).Probe(&rest.Config{}, pod, "", []string{"check"})
+			assert.NoError(t, err)
+			assert.Equal(t, api.Success, result)
+			assert.Equal(t, tt.output, output)
+			assert.True(t, ran.PodExecOptions.TTY)
+			assert.False(t, ran.PodExecOptions.Stderr)
+			assert.True(t, ran.StreamOptions.Tty)
+			assert.Nil(t, ran.StreamOptions.Stderr)
+			assert.Equal(t, "app", ran.PodExecOptions.Container)
+			assert.Equal(t, []string{"check"}, ran.PodExecOptions.Command)
+		})
+	}
+
+	ran := fakeExec(t, "ready\r\n", "")
+	_, output, err := New().Probe(&rest.Config{}, pod, "", []string{"check"})
+	assert.NoError(t, err)
+	assert.Equal(t, "ready\r\n", output, "line endings are kept without a TTY")
+	assert.False(t, ran.PodExecOptions.TTY)
+	assert.True(t, ran.PodExecOptions.Stderr)
+}
+
+func TestExecProber_Stderr(t *testing.T) {
+	pod := &core.Pod{Spec: core.PodSpec{Containers: []core.Container{{Name: "app"}}}}
+
+	tests := map[string]struct {
+		maxOutputLength int64
+		stdout          string
+		stderr          string
+		output          string
+	}{
+		"stdout only":      {0, "ok\n", "", "ok\n"},
+		"stderr only":      {0, "", "disk 90% full\n", "[stderr]\ndisk 90% full\n"},
+		"both":             {0, "ok", "disk 90% full\n", "ok\n[stderr]\ndisk 90% full\n"},
+		"stderr truncated": {4, "ok\n", "disk 90% full\n", "ok\n[stderr]\ndisk\n[output truncated at 4 bytes]"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			fakeExec(t, tt.stdout, tt.stderr)
+			result, output, err := NewWithOptions(Options{MaxOutputLength: tt.maxOutputLength}).Probe(&rest.Config{}, pod, "", []string{"check"})
+			assert.NoError(t, err)
+			assert.Equal(t, api.Success, result)
+			assert.Equal(t, tt.output, output)
+		})
+	}
+}
+
+func TestExecTargetProber(t *testing.T) {
+	pod := &core.Pod{Spec: core.PodSpec{Containers: []core.Container{{Name: "app"}, {Name: "sidecar"}}}}
+	ran := fakeExec(t, "ok", "")
+	prober := NewTargetProber(New())
+
+	result, output, err := prober.Probe(context.Background(), api.Target{Config: &rest.Config{}, Pod: pod, ContainerName: "sidecar", Command: []string{"check"}})
+	assert.NoError(t, err)
+	assert.Equal(t, api.Success, result)
+	assert.Equal(t, "ok", output)
+	assert.Equal(t, "sidecar", ran.PodExecOptions.Container)
+	assert.Equal(t, []string{"check"}, ran.PodExecOptions.Command)
+
+	ran = fakeExec(t, "ok", "")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, _, err = prober.Probe(ctx, api.Target{Config: &rest.Config{}, Pod: pod, Command: []string{"check"}})
+	assert.Error(t, err)
+	assert.Equal(t, api.Unknown, result)
+	assert.Nil(t, ran.PodExecOptions.Command, "the command is not run with a done context")
+}
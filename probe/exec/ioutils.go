@@ -50,3 +50,30 @@ func (l *LimitedWriter) Write(p []byte) (n int, err error) {
 	}
 	return
 }
+
+// truncateWriter returns a truncatingWriter that writes at most n bytes to w.
+func truncateWriter(w io.Writer, n int64) *truncatingWriter {
+	return &truncatingWriter{w: w, n: n}
+}
+
+// truncatingWriter writes to w until n bytes have been written and silently discards the rest,
+// so that the producer is not interrupted by a write error. truncated records whether anything was discarded.
+type truncatingWriter struct {
+	w         io.Writer
+	n         int64
+	truncated bool
+}
+
+func (t *truncatingWriter) Write(p []byte) (int, error) {
+	size := len(p)
+	if int64(size) > t.n {
+		p = p[:max(t.n, 0)]
+		t.truncated = true
+	}
+	n, err := t.w.Write(p)
+	t.n -= int64(n)
+	if err != nil {
+		return n, err
+	}
+	return size, nil
+}
@@ -82,6 +82,36 @@ func TestLimitWriter(t *testing.T) {
 	}
 }
 
+func TestTruncateWriter(t *testing.T) {
+	tests := []struct {
+		limit     int64
+		writes    []string
+		output    string
+		truncated bool
+	}{
+		{10, []string{"hello"}, "hello", false},
+		{5, []string{"hello"}, "hello", false},
+		{4, []string{"hello"}, "hell", true},
+		{7, []string{"hello", " world", "!"}, "hello w", true},
+		{0, []string{"hello"}, "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("limit=%d writes=%q", test.limit, test.writes), func(t *testing.T) {
+			output := &bytes.Buffer{}
+			w := truncateWriter(output, test.limit)
+			for _, s := range test.writes {
+				n, err := w.Write([]byte(s))
+				// the producer never sees the truncation
+				assert.NoError(t, err)
+				assert.Equal(t, len(s), n)
+			}
+			assert.Equal(t, test.output, output.String())
+			assert.Equal(t, test.truncated, w.truncated)
+		})
+	}
+}
+
 func bounded(min, val, max int64) int64 {
 	if max < val {
 		val = max
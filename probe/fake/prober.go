@@ -0,0 +1,107 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fake provides a scripted probe.ProberInterface for tests that must not do network I/O.
+package fake
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	api_v1 "kmodules.xyz/prober/api/v1"
+	"kmodules.xyz/prober/probe"
+
+	core "k8s.io/api/core/v1"
+)
+
+// Prober is a probe.ProberInterface that returns scripted results and records its calls.
+// It is safe for concurrent use.
+type Prober struct {
+	lock    sync.Mutex
+	results []error
+	// Default is returned once the queued results are used up. nil means the probe passes.
+	Default error
+	calls   []Call
+}
+
+var _ probe.ProberInterface = &Prober{}
+
+// Call is a probe run through the fake Prober.
+type Call struct {
+	Handler *api_v1.Handler
+	Pod     *core.Pod
+	Timeout time.Duration
+}
+
+// NewProber returns a fake Prober whose probes return results in order; nil means the probe passes.
+func NewProber(results ...error) *Prober {
+	return &Prober{results: results}
+}
+
+// Enqueue appends results to be returned by the next probes.
+func (p *Prober) Enqueue(results ...error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.results = append(p.results, results...)
+}
+
+// Calls returns the probes run so far, in order.
+func (p *Prober) Calls() []Call {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return append([]Call(nil), p.calls...)
+}
+
+// RunProbe records the call and returns the next scripted result.
+func (p *Prober) RunProbe(probes *api_v1.Handler, pod *core.Pod, timeout time.Duration) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.calls = append(p.calls, Call{Handler: probes, Pod: pod, Timeout: timeout})
+	if len(p.results) == 0 {
+		return p.Default
+	}
+	err := p.results[0]
+	p.results = p.results[1:]
+	return err
+}
+
+// RunProbeContext is like RunProbe. It returns the error of ctx if it is already done.
+func (p *Prober) RunProbeContext(ctx context.Context, probes *api_v1.Handler, pod *core.Pod, timeout time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return p.RunProbe(probes, pod, timeout)
+}
+
+// RunPodProbes runs the handler of each container of pod through RunProbe, in the order of the
+// containers in the pod spec. Like probe.Prober, it only reports handlers of unknown containers
+// as failed, without consuming a scripted result for them.
+func (p *Prober) RunPodProbes(pod *core.Pod, handlers map[string]*api_v1.Handler, timeout time.Duration) map[string]error {
+	results := make(map[string]error, len(handlers))
+	for _, c := range pod.Spec.Containers {
+		if handler := handlers[c.Name]; handler != nil {
+			results[c.Name] = p.RunProbe(handler, pod, timeout)
+		}
+	}
+	for name, handler := range handlers {
+		if _, ok := results[name]; !ok && handler != nil {
+			results[name] = fmt.Errorf("container %s not found in pod %s/%s", name, pod.Namespace, pod.Name)
+		}
+	}
+	return results
+}
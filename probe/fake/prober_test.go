@@ -0,0 +1,72 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fake
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	api_v1 "kmodules.xyz/prober/api/v1"
+	"kmodules.xyz/prober/probe"
+
+	"github.com/stretchr/testify/assert"
+	core "k8s.io/api/core/v1"
+)
+
+// waitReady stands in for downstream code that depends on the interface.
+func waitReady(p probe.ProberInterface, handler *api_v1.Handler, pod *core.Pod) int {
+	for i := 1; ; i++ {
+		if err := p.RunProbe(handler, pod, time.Second); err == nil {
+			return i
+		}
+	}
+}
+
+func TestProber(t *testing.T) {
+	handler := &api_v1.Handler{Exec: &core.ExecAction{Command: []string{"true"}}}
+	pod := &core.Pod{}
+	notReady := errors.New("not ready")
+
+	p := NewProber(notReady, notReady)
+	assert.Equal(t, 3, waitReady(p, handler, pod))
+	assert.Len(t, p.Calls(), 3)
+	assert.Same(t, handler, p.Calls()[0].Handler)
+
+	p.Default = notReady
+	p.Enqueue(nil)
+	assert.NoError(t, p.RunProbe(handler, pod, time.Second))
+	assert.Equal(t, notReady, p.RunProbe(handler, pod, time.Second))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, p.RunProbeContext(ctx, handler, pod, time.Second), context.Canceled)
+}
+
+func TestProberRunPodProbes(t *testing.T) {
+	handler := &api_v1.Handler{Exec: &core.ExecAction{Command: []string{"true"}}}
+	pod := &core.Pod{Spec: core.PodSpec{Containers: []core.Container{{Name: "app"}, {Name: "sidecar"}}}}
+	notReady := errors.New("not ready")
+
+	p := NewProber(nil, notReady)
+	results := p.RunPodProbes(pod, map[string]*api_v1.Handler{"app": handler, "sidecar": handler, "missing": handler}, time.Second)
+	assert.NoError(t, results["app"])
+	assert.Equal(t, notReady, results["sidecar"])
+	assert.Error(t, results["missing"])
+	assert.Len(t, p.Calls(), 2)
+}
@@ -0,0 +1,45 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	core "k8s.io/api/core/v1"
+)
+
+// GateNotMet is the output recorded for probes skipped because the pod does not meet Prober.Gate.
+const GateNotMet = "skipped: gate not met"
+
+// AnnotationGate limits probes to the pods that carry an annotation, e.g. to enable a probe
+// for part of a fleet.
+type AnnotationGate struct {
+	// Name is the annotation the pod must carry.
+	Name string
+	// Value is the value the annotation must have. Empty accepts any value.
+	Value string
+}
+
+// met reports whether pod passes the gate. A nil gate passes every pod, and no pod passes a gate.
+func (g *AnnotationGate) met(pod *core.Pod) bool {
+	if g == nil {
+		return true
+	}
+	if pod == nil {
+		return false
+	}
+	value, ok := pod.Annotations[g.Name]
+	return ok && (g.Value == "" || value == g.Value)
+}
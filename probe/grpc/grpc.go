@@ -0,0 +1,193 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpc
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"k8s.io/klog/v2"
+)
+
+// New creates a Prober.
+func New() Prober {
+	return grpcProber{}
+}
+
+// NewWithOptions creates a Prober with additional options.
+func NewWithOptions(opts Options) Prober {
+	return grpcProber{opts}
+}
+
+// Options holds the optional settings of the gRPC prober.
+// The zero value keeps the default probe behavior.
+type Options struct {
+	// TLSConfig makes the probe connect over TLS with this configuration. Defaults to plaintext.
+	// +optional
+	TLSConfig *tls.Config
+
+	// ExpectReflectionService additionally lists the services of the target through the server reflection API
+	// after the health check passed, and fails the probe unless it includes this fully qualified service name,
+	// e.g. "helloworld.Greeter". The failure reports the services found.
+	// +optional
+	ExpectReflectionService string
+
+	// ExpectMetadata are response headers or trailers the health check must return, keyed by name, e.g.
+	// {"x-server-version": "v2"}. Names are case insensitive, and an empty value only requires the key
+	// to be present. The failure reports the metadata received.
+	// +optional
+	ExpectMetadata map[string]string
+
+	// Method, if set, calls this fully qualified unary method instead of the health check, e.g.
+	// "/helloworld.Greeter/SayHello", and checks the status code it returns. The service of the probe is
+	// not used, while ExpectMetadata and ExpectReflectionService still apply. The output reports the code.
+	// +optional
+	Method string
+
+	// MethodRequest is the request message sent to Method. Defaults to an empty message.
+	// +optional
+	MethodRequest proto.Message
+
+	// ExpectCodes are the status codes Method may return. Defaults to OK, unless RejectCodes is set.
+	// +optional
+	ExpectCodes []codes.Code
+
+	// RejectCodes are status codes Method must not return, e.g. Unimplemented to check that a method
+	// exists regardless of how it answers an empty request. Unless ExpectCodes is set, the codes of a failed
+	// call, Unavailable, DeadlineExceeded, Canceled and Unknown, fail too.
+	// +optional
+	RejectCodes []codes.Code
+}
+
+// Prober is an interface that defines the Probe function for doing gRPC health checks.
+type Prober interface {
+	// Probe calls the standard grpc.health.v1.Health/Check method for service, where the
+	// empty service checks the overall health of the server, or Options.Method if it is set.
+	Probe(host string, port int, service string, timeout time.Duration) (api.Result, string, error)
+}
+
+type grpcProber struct {
+	opts Options
+}
+
+// Probe returns a ProbeRunner capable of running a gRPC check.
+func (pr grpcProber) Probe(host string, port int, service string, timeout time.Duration) (api.Result, string, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return doGRPCProbe(ctx, net.JoinHostPort(host, strconv.Itoa(port)), service, &pr.opts)
+}
+
+// NewTargetProber adapts a Prober to the unified api.Prober interface.
+func NewTargetProber(p Prober) api.Prober {
+	return targetProber{p}
+}
+
+type targetProber struct {
+	Prober
+}
+
+// Probe checks the health of target.Service at target.Host:target.Port.
+func (pr targetProber) Probe(ctx context.Context, target api.Target) (api.Result, string, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Unknown, "", err
+	}
+	return pr.Prober.Probe(target.Host, target.Port, target.Service, api.TimeoutFor(ctx, target))
+}
+
+func doGRPCProbe(ctx context.Context, addr, service string, opts *Options) (api.Result, string, error) {
+	creds := insecure.NewCredentials()
+	if opts.TLSConfig != nil {
+		creds = credentials.NewTLS(opts.TLSConfig)
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return api.Unknown, "", err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			klog.Errorf("Unexpected error closing gRPC probe connection: %v", err)
+		}
+	}()
+
+	var (
+		output string
+		md     metadata.MD
+		ok     bool
+	)
+	if opts.Method != "" {
+		output, md, ok = callMethod(ctx, conn, opts)
+	} else {
+		output, md, ok = checkHealth(ctx, conn, service)
+	}
+	if !ok {
+		return api.Failure, output, nil
+	}
+	if err := verifyMetadata(opts.ExpectMetadata, md); err != nil {
+		return api.Failure, err.Error(), nil
+	}
+	if opts.ExpectReflectionService != "" {
+		services, err := listServices(ctx, conn)
+		if err != nil {
+			return api.Failure, fmt.Sprintf("server reflection failed: %v", err), nil
+		}
+		if !contains(services, opts.ExpectReflectionService) {
+			return api.Failure, fmt.Sprintf("service %s is not listed by server reflection, found %q", opts.ExpectReflectionService, services), nil
+		}
+	}
+	return api.Success, output, nil
+}
+
+// checkHealth calls the standard health check for service on conn.
+func checkHealth(ctx context.Context, conn *grpc.ClientConn, service string) (string, metadata.MD, bool) {
+	var header, trailer metadata.MD
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: service},
+		grpc.Header(&header), grpc.Trailer(&trailer))
+	if err != nil {
+		// Convert errors to failures to handle timeouts.
+		return fmt.Sprintf("health check failed: %v", err), nil, false
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		return fmt.Sprintf("service unhealthy (responded with %q)", resp.GetStatus()), nil, false
+	}
+	return "", metadata.Join(header, trailer), true
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
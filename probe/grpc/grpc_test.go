@@ -0,0 +1,161 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpc
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+)
+
+// startServer serves the health service, and server reflection if reflect is set.
+// Unary calls return the header x-server-version=v2 and the trailer x-region=eu.
+func startServer(t *testing.T, reflect bool) (string, int) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	server := grpc.NewServer(grpc.UnaryInterceptor(func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		_ = grpc.SetHeader(ctx, metadata.Pairs("x-server-version", "v2"))
+		_ = grpc.SetTrailer(ctx, metadata.Pairs("x-region", "eu"))
+		return handler(ctx, req)
+	}))
+	hs := health.NewServer()
+	hs.SetServingStatus("demo.Ready", healthpb.HealthCheckResponse_SERVING)
+	hs.SetServingStatus("demo.Warming", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthpb.RegisterHealthServer(server, hs)
+	if reflect {
+		reflection.Register(server)
+	}
+	go func() {
+		_ = server.Serve(ln)
+	}()
+	t.Cleanup(server.Stop)
+
+	host, portStr, err := net.SplitHostPort(ln.Addr().String())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	port, _ := strconv.Atoi(portStr)
+	return host, port
+}
+
+func TestGRPCHealthChecker(t *testing.T) {
+	host, port := startServer(t, true)
+	noReflectionHost, noReflectionPort := startServer(t, false)
+	// nothing listens on closedPort, so calls fail with Unavailable
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	closedPort := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	tests := []struct {
+		name           string
+		host           string
+		port           int
+		service        string
+		opts           Options
+		expectedStatus api.Result
+		expectedOutput string
+	}{
+		{"serving", host, port, "", Options{}, api.Success, ""},
+		{"serving service", host, port, "demo.Ready", Options{}, api.Success, ""},
+		{"not serving", host, port, "demo.Warming", Options{}, api.Failure, `service unhealthy (responded with "NOT_SERVING")`},
+		{"unknown service", host, port, "demo.Missing", Options{}, api.Failure, "health check failed"},
+		{"reflection lists service", host, port, "", Options{ExpectReflectionService: "grpc.health.v1.Health"}, api.Success, ""},
+		{
+			"reflection misses service", host, port, "", Options{ExpectReflectionService: "helloworld.Greeter"}, api.Failure,
+			`service helloworld.Greeter is not listed by server reflection, found ["grpc.health.v1.Health" "grpc.reflection.v1.ServerReflection" "grpc.reflection.v1alpha.ServerReflection"]`,
+		},
+		{"metadata header", host, port, "", Options{ExpectMetadata: map[string]string{"X-Server-Version": "v2"}}, api.Success, ""},
+		{"metadata trailer", host, port, "", Options{ExpectMetadata: map[string]string{"x-region": "eu"}}, api.Success, ""},
+		{"metadata present", host, port, "", Options{ExpectMetadata: map[string]string{"x-region": ""}}, api.Success, ""},
+		{
+			"metadata missing", host, port, "", Options{ExpectMetadata: map[string]string{"x-build": ""}}, api.Failure,
+			`response metadata x-build is missing, received {content-type=["application/grpc"], x-region=["eu"], x-server-version=["v2"]}`,
+		},
+		{
+			"metadata mismatch", host, port, "", Options{ExpectMetadata: map[string]string{"x-server-version": "v3"}}, api.Failure,
+			`response metadata x-server-version is ["v2"], expected "v3", received`,
+		},
+		{"method ok", host, port, "", Options{Method: "/grpc.health.v1.Health/Check"}, api.Success, "method /grpc.health.v1.Health/Check returned code OK"},
+		{
+			"method typed request", host, port, "", Options{Method: "/grpc.health.v1.Health/Check", MethodRequest: &healthpb.HealthCheckRequest{Service: "demo.Missing"}}, api.Failure,
+			"method /grpc.health.v1.Health/Check returned code NotFound, expected OK: rpc error",
+		},
+		{
+			"method expected code", host, port, "", Options{Method: "/grpc.health.v1.Health/Check", MethodRequest: &healthpb.HealthCheckRequest{Service: "demo.Missing"}, ExpectCodes: []codes.Code{codes.OK, codes.NotFound}}, api.Success,
+			"method /grpc.health.v1.Health/Check returned code NotFound",
+		},
+		{"method not rejected", host, port, "", Options{Method: "/grpc.health.v1.Health/Check", MethodRequest: &healthpb.HealthCheckRequest{Service: "demo.Missing"}, RejectCodes: []codes.Code{codes.Unimplemented}}, api.Success, "method /grpc.health.v1.Health/Check returned code NotFound"},
+		{"method rejected", host, port, "", Options{Method: "/demo.Greeter/SayHello", RejectCodes: []codes.Code{codes.Unimplemented}}, api.Failure, "method /demo.Greeter/SayHello returned code Unimplemented: rpc error"},
+		{"method rejected codes on closed port", host, closedPort, "", Options{Method: "/demo.Greeter/SayHello", RejectCodes: []codes.Code{codes.Unimplemented}}, api.Failure, "method /demo.Greeter/SayHello failed with code Unavailable: rpc error"},
+		{"method expected unavailable on closed port", host, closedPort, "", Options{Method: "/demo.Greeter/SayHello", ExpectCodes: []codes.Code{codes.Unavailable}}, api.Success, "method /demo.Greeter/SayHello returned code Unavailable"},
+		{"method metadata", host, port, "", Options{Method: "/grpc.health.v1.Health/Check", ExpectMetadata: map[string]string{"x-region": "eu"}}, api.Success, "method /grpc.health.v1.Health/Check returned code OK"},
+		{"reflection unavailable", noReflectionHost, noReflectionPort, "", Options{ExpectReflectionService: "grpc.health.v1.Health"}, api.Failure, "server reflection failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prober := NewWithOptions(tt.opts)
+			status, output, err := prober.Probe(tt.host, tt.port, tt.service, 5*time.Second)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if status != tt.expectedStatus {
+				t.Errorf("expected status=%v, get=%v, output=%q", tt.expectedStatus, status, output)
+			}
+			if !strings.HasPrefix(output, tt.expectedOutput) {
+				t.Errorf("expected output=%q, get=%q", tt.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestGRPCHealthChecker_Timeout(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	start := time.Now()
+	status, _, err := New().Probe("127.0.0.1", port, "", 100*time.Millisecond)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if status != api.Failure {
+		t.Errorf("expected status=%v, get=%v", api.Failure, status)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("expected the probe to honor the timeout, took %v", elapsed)
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// verifyMetadata checks that md, the headers and trailers of a response, hold the expected keys and values.
+func verifyMetadata(expected map[string]string, md metadata.MD) error {
+	keys := make([]string, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		values := md.Get(key)
+		want := expected[key]
+		if len(values) > 0 && want == "" {
+			continue
+		}
+		if contains(values, want) {
+			continue
+		}
+		if len(values) == 0 {
+			return fmt.Errorf("response metadata %s is missing, received %s", strings.ToLower(key), formatMetadata(md))
+		}
+		return fmt.Errorf("response metadata %s is %q, expected %q, received %s", strings.ToLower(key), values, want, formatMetadata(md))
+	}
+	return nil
+}
+
+// formatMetadata prints md with sorted keys, leaving out the binary values.
+func formatMetadata(md metadata.MD) string {
+	keys := make([]string, 0, len(md))
+	for key := range md {
+		if !strings.HasSuffix(key, "-bin") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%q", key, md[key]))
+	}
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
@@ -0,0 +1,84 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// callMethod invokes opts.Method on conn and checks the status code it returns against ExpectCodes
+// and RejectCodes. The codes of a failed call, e.g. Unavailable for a refused connection, fail unless
+// they are expected. The response message is not decoded. The output reports the code returned.
+func callMethod(ctx context.Context, conn *grpc.ClientConn, opts *Options) (string, metadata.MD, bool) {
+	var req proto.Message = &emptypb.Empty{}
+	if opts.MethodRequest != nil {
+		req = opts.MethodRequest
+	}
+	var header, trailer metadata.MD
+	err := conn.Invoke(ctx, opts.Method, req, &emptypb.Empty{}, grpc.Header(&header), grpc.Trailer(&trailer))
+	code := status.Code(err)
+	md := metadata.Join(header, trailer)
+
+	if containsCode(opts.RejectCodes, code) {
+		return fmt.Sprintf("method %s returned code %s: %v", opts.Method, code, err), md, false
+	}
+	expected := opts.ExpectCodes
+	if len(expected) == 0 && len(opts.RejectCodes) == 0 {
+		expected = []codes.Code{codes.OK}
+	}
+	if len(expected) == 0 && containsCode(transportCodes, code) {
+		// Convert errors to failures to handle timeouts.
+		return fmt.Sprintf("method %s failed with code %s: %v", opts.Method, code, err), md, false
+	}
+	if len(expected) > 0 && !containsCode(expected, code) {
+		if err != nil {
+			return fmt.Sprintf("method %s returned code %s, expected %s: %v", opts.Method, code, formatCodes(expected), err), md, false
+		}
+		return fmt.Sprintf("method %s returned code %s, expected %s", opts.Method, code, formatCodes(expected)), md, false
+	}
+	return fmt.Sprintf("method %s returned code %s", opts.Method, code), md, true
+}
+
+// transportCodes are the status codes of calls that failed rather than being answered by the method,
+// e.g. because the connection was refused or timed out. They fail a probe that only sets RejectCodes.
+var transportCodes = []codes.Code{codes.Unavailable, codes.DeadlineExceeded, codes.Canceled, codes.Unknown}
+
+func containsCode(list []codes.Code, code codes.Code) bool {
+	for _, c := range list {
+		if c == code {
+			return true
+		}
+	}
+	return false
+}
+
+func formatCodes(list []codes.Code) string {
+	names := make([]string, 0, len(list))
+	for _, c := range list {
+		names = append(names, c.String())
+	}
+	return strings.Join(names, " or ")
+}
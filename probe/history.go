@@ -0,0 +1,120 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"sync"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+)
+
+// ResultRecord is an entry of the probe result history of a Prober.
+type ResultRecord struct {
+	Kind   string
+	Target string
+	Time   time.Time
+	Result api.Result
+	// Reason is the error or output of the probe. It is empty for successful probes, except for skipped ones.
+	Reason string
+	// Skipped reports that the probe was not run because the pod does not meet Prober.Gate.
+	// Its Result is Success and its Reason GateNotMet.
+	Skipped bool
+}
+
+// resultHistory is a fixed size ring buffer of probe results.
+type resultHistory struct {
+	lock    sync.Mutex
+	entries []ResultRecord
+	next    int
+	full    bool
+}
+
+func (h *resultHistory) add(size int, r ResultRecord) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if len(h.entries) != size {
+		h.entries = make([]ResultRecord, size)
+		h.next = 0
+		h.full = false
+	}
+	h.entries[h.next] = r
+	h.next = (h.next + 1) % size
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+func (h *resultHistory) list() []ResultRecord {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if !h.full {
+		return append([]ResultRecord(nil), h.entries[:h.next]...)
+	}
+	out := make([]ResultRecord, 0, len(h.entries))
+	out = append(out, h.entries[h.next:]...)
+	return append(out, h.entries[:h.next]...)
+}
+
+// describeTarget returns the host:port a probe connects to, or the pod and container for exec probes.
+func describeTarget(target api.Target) string {
+	key := targetKey(target)
+	if key == "" && target.Pod != nil {
+		key = formatPod(target.Pod) + "/" + target.ContainerName
+	}
+	return key
+}
+
+// RecentResults returns the last HistorySize probe results of this Prober, oldest first.
+// It is safe to call concurrently with running probes.
+func (pb *Prober) RecentResults() []ResultRecord {
+	return pb.history.list()
+}
+
+func (pb *Prober) record(kind string, target api.Target, result api.Result, output string, err error) {
+	if pb.HistorySize <= 0 {
+		return
+	}
+	r := ResultRecord{
+		Kind:   kind,
+		Target: describeTarget(target),
+		Time:   time.Now(),
+		Result: result,
+	}
+	if result != api.Success {
+		r.Reason = output
+		if err != nil {
+			r.Reason = err.Error()
+		}
+	}
+	pb.history.add(pb.HistorySize, r)
+}
+
+// recordSkipped adds a probe that was not run because the pod does not meet Prober.Gate to the result history.
+func (pb *Prober) recordSkipped(kind string, target api.Target) {
+	if pb.HistorySize <= 0 {
+		return
+	}
+	pb.history.add(pb.HistorySize, ResultRecord{
+		Kind:    kind,
+		Target:  describeTarget(target),
+		Time:    time.Now(),
+		Result:  api.Success,
+		Reason:  GateNotMet,
+		Skipped: true,
+	})
+}
@@ -0,0 +1,221 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// verify evaluates the assertions configured in opts against the response.
+// It returns an error describing the first assertion that does not hold.
+func (opts *Options) verify(res *http.Response) error {
+	if opts.TLSMinAcceptedVersion != 0 {
+		if res.TLS == nil {
+			return fmt.Errorf("TLS version check failed: response was not received over TLS")
+		}
+		if res.TLS.Version < opts.TLSMinAcceptedVersion {
+			return fmt.Errorf("TLS version check failed: negotiated %s, minimum accepted %s",
+				tls.VersionName(res.TLS.Version), tls.VersionName(opts.TLSMinAcceptedVersion))
+		}
+	}
+	if opts.TLSMinKeyBits > 0 {
+		if err := verifyKeySize(res.TLS, opts.TLSMinKeyBits); err != nil {
+			return err
+		}
+	}
+	if opts.RequireOCSPStaple || opts.RequireOCSPGood {
+		if err := verifyOCSPStaple(res.TLS, opts.RequireOCSPGood); err != nil {
+			return err
+		}
+	}
+	if opts.ExpectStatusText != nil && !opts.ExpectStatusText.MatchString(res.Status) {
+		return fmt.Errorf("status line %q does not match %q", res.Status, opts.ExpectStatusText)
+	}
+	if opts.ExpectProto != "" && res.Proto != opts.ExpectProto {
+		return fmt.Errorf("expected protocol %s, got %s", opts.ExpectProto, res.Proto)
+	}
+	if opts.ExpectRedirectCount != nil {
+		if n := redirectCount(res); n != *opts.ExpectRedirectCount {
+			return fmt.Errorf("expected %d redirects, observed %d", *opts.ExpectRedirectCount, n)
+		}
+	}
+	if opts.ExpectHTTPS {
+		if err := verifyHTTPS(res); err != nil {
+			return err
+		}
+	}
+	if opts.MaxClockSkew > 0 {
+		if err := verifyClockSkew(res, opts.MaxClockSkew); err != nil {
+			return err
+		}
+	}
+	if opts.ExpectServerContains != "" {
+		server := res.Header.Get("Server")
+		if !strings.Contains(strings.ToLower(server), strings.ToLower(opts.ExpectServerContains)) {
+			return fmt.Errorf("expected the Server header to contain %q, got %q", opts.ExpectServerContains, server)
+		}
+	}
+	for _, name := range opts.ForbidResponseHeaders {
+		if values, ok := res.Header[http.CanonicalHeaderKey(name)]; ok {
+			return fmt.Errorf("forbidden response header %s is present: %q", http.CanonicalHeaderKey(name), values)
+		}
+	}
+	for _, a := range opts.ExpectResponseHeaders {
+		if err := opts.verifyHeader(res.Header, a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HeaderAssertion requires a response header to have a value, e.g. {Name: "X-Served-By", Value: "{{.Name}}"}
+// to assert that the probe reached the pod it targets rather than another backend.
+type HeaderAssertion struct {
+	// Name is the name of the header, e.g. "X-Served-By".
+	Name string
+	// Value is the expected value of the header. Like ExpectJSONValue, it may be a text/template rendered
+	// against the probed pod.
+	Value string
+	// Match treats the rendered Value as a regular expression that the header value must match instead of
+	// equal, e.g. `^{{.Name}}\b`. Values rendered from the pod are inserted as is.
+	// +optional
+	Match bool
+}
+
+// verifyHeader checks that one of the values of the header of a is the expected value.
+func (opts *Options) verifyHeader(header http.Header, a HeaderAssertion) error {
+	name := http.CanonicalHeaderKey(a.Name)
+	want, err := opts.renderExpected(a.Value)
+	if err != nil {
+		return err
+	}
+	var re *regexp.Regexp
+	if a.Match {
+		if re, err = regexp.Compile(want); err != nil {
+			return fmt.Errorf("invalid pattern for response header %s: %v", name, err)
+		}
+	}
+	values := header.Values(name)
+	for _, v := range values {
+		if (re != nil && re.MatchString(v)) || (re == nil && v == want) {
+			return nil
+		}
+	}
+	expected := fmt.Sprintf("expected %q", want)
+	if re != nil {
+		expected = fmt.Sprintf("expected to match %q", want)
+	}
+	switch len(values) {
+	case 0:
+		return fmt.Errorf("response header %s is missing, %s", name, expected)
+	case 1:
+		return fmt.Errorf("response header %s is %q, %s", name, values[0], expected)
+	default:
+		return fmt.Errorf("response header %s is %q, %s", name, values, expected)
+	}
+}
+
+// verifyClockSkew checks that the Date header of res is within maxSkew of the local clock.
+func verifyClockSkew(res *http.Response, maxSkew time.Duration) error {
+	value := res.Header.Get("Date")
+	if value == "" {
+		return fmt.Errorf("clock skew check failed: response has no Date header")
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return fmt.Errorf("clock skew check failed: invalid Date header %q: %v", value, err)
+	}
+	skew := date.Sub(time.Now()).Round(time.Second)
+	if skew > maxSkew || -skew > maxSkew {
+		return fmt.Errorf("clock skew check failed: server clock is off by %v, maximum %v", skew, maxSkew)
+	}
+	return nil
+}
+
+// verifyContentLength checks that the Content-Length of res, if declared, matches the received bytes of the body.
+// received counts the bytes as transferred, before the probe decodes a Content-Encoding.
+func verifyContentLength(res *http.Response, received int64) error {
+	if res.ContentLength < 0 || res.ContentLength == received {
+		return nil
+	}
+	return fmt.Errorf("Content-Length mismatch: declared %d bytes, received %d bytes", res.ContentLength, received)
+}
+
+// verifyHTTPS checks that the final URL of res uses the https scheme.
+func verifyHTTPS(res *http.Response) error {
+	final := res.Request.URL
+	if final.Scheme == "https" {
+		return nil
+	}
+	if location := res.Header.Get("Location"); location != "" && res.StatusCode >= http.StatusMultipleChoices && res.StatusCode < http.StatusBadRequest {
+		return fmt.Errorf("expected the final URL to use https, got %s with a redirect to %s that was not followed", final.Redacted(), location)
+	}
+	return fmt.Errorf("expected the final URL to use https, got %s", final.Redacted())
+}
+
+// redirectCount returns the number of redirects the client followed to receive res.
+// Every request made for a redirect links the redirect response that caused it.
+func redirectCount(res *http.Response) int {
+	n := 0
+	for req := res.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		n++
+	}
+	return n
+}
+
+func verifyOCSPStaple(state *tls.ConnectionState, requireGood bool) error {
+	if state == nil {
+		return fmt.Errorf("OCSP check failed: response was not received over TLS")
+	}
+	if len(state.OCSPResponse) == 0 {
+		return fmt.Errorf("OCSP check failed: stapled response present: false")
+	}
+	if !requireGood {
+		return nil
+	}
+	var issuer *x509.Certificate
+	if len(state.PeerCertificates) > 1 {
+		issuer = state.PeerCertificates[1]
+	}
+	resp, err := ocsp.ParseResponse(state.OCSPResponse, issuer)
+	if err != nil {
+		return fmt.Errorf("OCSP check failed: stapled response present: true, failed to parse: %v", err)
+	}
+	if resp.Status != ocsp.Good {
+		return fmt.Errorf("OCSP check failed: stapled response present: true, status: %s", ocspStatus(resp.Status))
+	}
+	return nil
+}
+
+func ocspStatus(status int) string {
+	switch status {
+	case ocsp.Good:
+		return "good"
+	case ocsp.Revoked:
+		return "revoked"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,89 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/tls"
+	"time"
+)
+
+// Option configures the probers created by NewGet and NewPost.
+type Option func(*builder)
+
+type builder struct {
+	tlsConfig               *tls.Config
+	followNonLocalRedirects bool
+	opts                    Options
+}
+
+func newBuilder(options []Option) builder {
+	var b builder
+	for _, option := range options {
+		option(&b)
+	}
+	return b
+}
+
+// WithTLSConfig sets the TLS configuration of the prober. Without it, server certificates are verified
+// against the system roots.
+func WithTLSConfig(config *tls.Config) Option {
+	return func(b *builder) {
+		b.tlsConfig = config
+	}
+}
+
+// WithFollowNonLocalRedirects configures whether the prober follows redirects to a different hostname.
+// If disabled, the default, redirects to other hosts trigger a warning result.
+func WithFollowNonLocalRedirects(follow bool) Option {
+	return func(b *builder) {
+		b.followNonLocalRedirects = follow
+	}
+}
+
+// WithTimeout sets Options.Timeout, the timeout of probes run without one.
+func WithTimeout(timeout time.Duration) Option {
+	return func(b *builder) {
+		b.opts.Timeout = timeout
+	}
+}
+
+// WithUserAgent sets Options.UserAgent, the User-Agent of probes whose headers do not set one.
+func WithUserAgent(userAgent string) Option {
+	return func(b *builder) {
+		b.opts.UserAgent = userAgent
+	}
+}
+
+// WithOptions sets the additional prober options. It replaces the Options set by earlier options,
+// such as WithTimeout, so it is best passed first.
+func WithOptions(opts Options) Option {
+	return func(b *builder) {
+		b.opts = opts
+	}
+}
+
+// NewGet creates a GetProber configured by options.
+func NewGet(options ...Option) GetProber {
+	b := newBuilder(options)
+	return httpGetProber{newTransport(b.tlsConfig, b.opts), b.followNonLocalRedirects, b.opts}
+}
+
+// NewPost creates a PostProber configured by options.
+func NewPost(options ...Option) PostProber {
+	b := newBuilder(options)
+	return httpPostProber{newTransport(b.tlsConfig, b.opts), b.followNonLocalRedirects, b.opts}
+}
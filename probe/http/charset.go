@@ -0,0 +1,98 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+)
+
+// charsetAliases maps the names of the charsets that can be validated to their canonical name.
+var charsetAliases = map[string]string{
+	"utf-8":      "utf-8",
+	"utf8":       "utf-8",
+	"us-ascii":   "us-ascii",
+	"ascii":      "us-ascii",
+	"iso-8859-1": "iso-8859-1",
+	"latin1":     "iso-8859-1",
+}
+
+// normalizeCharset returns the canonical name of charset, or charset in lower case if it is not known.
+func normalizeCharset(charset string) string {
+	charset = strings.ToLower(strings.TrimSpace(charset))
+	if canonical, ok := charsetAliases[charset]; ok {
+		return canonical
+	}
+	return charset
+}
+
+// verifyCharset checks the charset declared by the Content-Type of res against ExpectCharset, and that body
+// is valid in it or, with RequireValidUTF8, valid UTF-8. A truncated body may end in the middle of a character.
+func (opts *Options) verifyCharset(res *http.Response, body []byte, truncated bool) error {
+	if opts.ExpectCharset == "" && !opts.RequireValidUTF8 {
+		return nil
+	}
+	declared := ""
+	if _, params, err := mime.ParseMediaType(res.Header.Get("Content-Type")); err == nil {
+		declared = params["charset"]
+	}
+	charset := normalizeCharset(declared)
+	if opts.ExpectCharset != "" {
+		expected := normalizeCharset(opts.ExpectCharset)
+		if declared == "" {
+			return fmt.Errorf("response declares no charset, expected %s", opts.ExpectCharset)
+		}
+		if charset != expected {
+			return fmt.Errorf("response declares charset %s, expected %s", declared, opts.ExpectCharset)
+		}
+	}
+	if opts.RequireValidUTF8 {
+		if charset != "" && charset != "utf-8" && charset != "us-ascii" {
+			return fmt.Errorf("response declares charset %s, expected UTF-8", declared)
+		}
+		charset = "utf-8"
+	}
+	switch charset {
+	case "utf-8":
+		return validUTF8(body, truncated)
+	case "us-ascii":
+		for i, c := range body {
+			if c >= utf8.RuneSelf {
+				return fmt.Errorf("response body is not valid US-ASCII: byte 0x%02x at offset %d", c, i)
+			}
+		}
+	}
+	return nil
+}
+
+// validUTF8 reports the first invalid byte of body. A truncated body may end with an incomplete character.
+func validUTF8(body []byte, truncated bool) error {
+	for i := 0; i < len(body); {
+		r, size := utf8.DecodeRune(body[i:])
+		if r == utf8.RuneError && size == 1 {
+			if truncated && !utf8.FullRune(body[i:]) {
+				return nil
+			}
+			return fmt.Errorf("response body is not valid UTF-8: invalid byte 0x%02x at offset %d", body[i], i)
+		}
+		i += size
+	}
+	return nil
+}
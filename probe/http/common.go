@@ -1,13 +1,24 @@
 package http
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	api "kmodules.xyz/prober/api"
 
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
 	"k8s.io/klog/v2"
 	utilio "k8s.io/utils/io"
 )
@@ -24,54 +35,578 @@ type HTTPInterface interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
-func doHTTPProbe(req *http.Request, url *url.URL, headers http.Header, client HTTPInterface) (api.Result, string, error) {
+func newTransport(config *tls.Config, opts Options) *http.Transport {
+	if opts.VerifyPeerCertificate != nil {
+		config = withPeerVerification(config, opts.VerifyPeerCertificate)
+	}
+	if opts.ClientCertificate != nil {
+		if config == nil {
+			config = &tls.Config{}
+		} else {
+			config = config.Clone()
+		}
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return opts.ClientCertificate()
+		}
+	} else if len(opts.ClientCertificates) > 0 {
+		if config == nil {
+			config = &tls.Config{}
+		} else {
+			config = config.Clone()
+		}
+		config.GetClientCertificate = selectClientCertificate(opts.ClientCertificates)
+	}
+	// We do not want the probe use node's local proxy set.
+	transport := utilnet.SetTransportDefaults(
+		&http.Transport{
+			TLSClientConfig:    config,
+			DisableKeepAlives:  !opts.EnableKeepAlives && opts.WarmupRequests <= 0,
+			DisableCompression: opts.RawBody,
+			Proxy:              http.ProxyURL(nil),
+		})
+	if opts.UseEnvironmentProxy {
+		// unlike http.ProxyFromEnvironment, this reads the environment for every prober instead of once per process
+		proxyFunc := httpproxy.FromEnvironment().ProxyFunc()
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxyFunc(req.URL)
+		}
+	}
+	if opts.Proxy != nil {
+		transport.Proxy = http.ProxyURL(opts.Proxy.ProxyURL())
+	}
+	var forward proxy.Dialer
+	if opts.LocalPorts != nil {
+		bound := opts.LocalPorts.Dialer(&net.Dialer{})
+		transport.DialContext = bound.DialContext
+		forward = bound
+	}
+	if opts.SOCKS5 != nil {
+		dialer, err := opts.SOCKS5.Dialer(forward)
+		if err != nil {
+			transport.DialContext = func(context.Context, string, string) (net.Conn, error) {
+				return nil, fmt.Errorf("failed to configure %s: %w", opts.SOCKS5, err)
+			}
+		} else {
+			transport.DialContext = dialer.DialContext
+		}
+	}
+	if opts.DialAddress != "" {
+		dial := transport.DialContext
+		if dial == nil {
+			dial = (&net.Dialer{}).DialContext
+		}
+		transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return dial(ctx, network, opts.DialAddress)
+		}
+	}
+	if opts.UnixSocket != "" {
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", opts.UnixSocket)
+		}
+	}
+	return transport
+}
+
+// withPeerVerification returns a copy of config that also runs verify during the handshake.
+// A VerifyPeerCertificate callback already set in config runs first.
+func withPeerVerification(config *tls.Config, verify func([][]byte, [][]*x509.Certificate) error) *tls.Config {
+	if config == nil {
+		config = &tls.Config{}
+	} else {
+		config = config.Clone()
+	}
+	prev := config.VerifyPeerCertificate
+	config.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if prev != nil {
+			if err := prev(rawCerts, verifiedChains); err != nil {
+				return err
+			}
+		}
+		if err := verify(rawCerts, verifiedChains); err != nil {
+			return &peerVerificationError{err}
+		}
+		return nil
+	}
+	return config
+}
+
+// peerVerificationError marks errors returned by Options.VerifyPeerCertificate.
+type peerVerificationError struct {
+	err error
+}
+
+func (e *peerVerificationError) Error() string { return e.err.Error() }
+
+func (e *peerVerificationError) Unwrap() error { return e.err }
+
+// ErrHTTP3Unsupported is returned by HTTP/3 probes when the binary was built without the http3 build tag.
+var ErrHTTP3Unsupported = errors.New("HTTP/3 probes are not supported by this build, rebuild with -tags http3")
+
+// http3RoundTripper is a transport that holds UDP sockets that must be closed after use.
+type http3RoundTripper interface {
+	http.RoundTripper
+	io.Closer
+}
+
+// selectClientCertificate returns a tls.Config.GetClientCertificate that picks the first of certs the server accepts.
+// Like the selection of tls.Config.Certificates, it sends no certificate if the server accepts none of them.
+func selectClientCertificate(certs []tls.Certificate) func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return func(cri *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		for i := range certs {
+			if err := cri.SupportsCertificate(&certs[i]); err == nil {
+				return &certs[i], nil
+			}
+		}
+		return &tls.Certificate{}, nil
+	}
+}
+
+// newClient returns the client for a single probe and a function that releases its resources.
+func newClient(transport *http.Transport, followNonLocalRedirects bool, opts *Options, timeout time.Duration) (*http.Client, func(), error) {
+	if opts.ClientCertificate != nil {
+		// Load the certificate upfront, so that a missing certificate is not mistaken for a TLS failure of the target.
+		if _, err := opts.ClientCertificate(); err != nil {
+			return nil, nil, err
+		}
+	}
+	if timeout <= 0 {
+		timeout = opts.Timeout
+	}
+	release := func() {}
+	if opts.AccountCost && !opts.HTTP3 {
+		opts.cost = &costCounter{}
+		transport = opts.cost.countingTransport(transport)
+		release = transport.CloseIdleConnections
+	}
+	client := &http.Client{
+		Timeout:       timeout,
+		Transport:     transport,
+		CheckRedirect: redirectChecker(followNonLocalRedirects, opts.AllowedRedirectHosts...),
+	}
+	switch {
+	case opts.HTTP3:
+		if err := opts.validateHTTP3(); err != nil {
+			return nil, nil, err
+		}
+		rt, err := newHTTP3Transport(transport.TLSClientConfig, timeout)
+		if err != nil {
+			return nil, nil, err
+		}
+		client.Transport = rt
+		closeTransport := release
+		release = func() {
+			if err := rt.Close(); err != nil {
+				klog.Errorf("Unexpected error closing HTTP/3 transport: %v", err)
+			}
+			closeTransport()
+		}
+	case opts.ForbidServerPush:
+		rt := newPushTransport(transport)
+		client.Transport = rt
+		closeTransport := release
+		release = func() {
+			rt.h2.CloseIdleConnections()
+			closeTransport()
+		}
+	}
+	if opts.WrapTransport != nil {
+		client.Transport = opts.WrapTransport(client.Transport)
+	}
+	return client, release, nil
+}
+
+func doHTTPProbe(req *http.Request, url *url.URL, headers http.Header, client HTTPInterface, opts *Options) (Details, error) {
+	if err := opts.validateSkipBodyRead(); err != nil {
+		return Details{Result: api.Unknown, Output: err.Error()}, err
+	}
+	if err := opts.readGoldenFile(); err != nil {
+		return Details{Result: api.Unknown, Output: err.Error()}, err
+	}
 	if _, ok := headers["User-Agent"]; !ok {
+		// Copy the headers as they may be shared by concurrent probes.
+		headers = headers.Clone()
 		if headers == nil {
 			headers = http.Header{}
 		}
 		// explicitly set User-Agent so it's not set to default Go value
-		headers.Set("User-Agent", "kmodules.xyz/client-go/release-11.0")
+		headers.Set("User-Agent", opts.userAgent())
 	}
 	req.Header = headers
+	req.Close = opts.CloseConnection
 	if headers.Get("Host") != "" {
 		req.Host = headers.Get("Host")
 	}
+	if opts.Authenticator != nil {
+		// The authenticator sets headers, which must not leak into the shared headers.
+		req.Header = req.Header.Clone()
+		if err := opts.Authenticator.Authenticate(req); err != nil {
+			klog.V(5).Infof("Failed to authenticate probe for %s: %v", url.String(), err)
+			return Details{Result: api.Failure, Output: fmt.Sprintf("authentication failed: %v", err), Reason: api.ReasonAuthenticationFailed}, nil
+		}
+	}
+	if encoding := opts.acceptEncoding(); encoding != "" && req.Header.Get("Accept-Encoding") == "" {
+		req.Header = req.Header.Clone()
+		req.Header.Set("Accept-Encoding", encoding)
+	}
+	req.Header = opts.conditionalRequestHeaders(req.Header)
+	var tracer *phaseTracer
+	if opts.Trace {
+		tracer = &phaseTracer{}
+		req = tracer.trace(req)
+	}
+	d, err := doRequest(req, url, headers, client, opts)
+	if tracer != nil {
+		d.Timings = tracer.result()
+	}
+	if opts.MaxOutputLength > 0 {
+		d.Output = truncateOutput(d.Output, opts.MaxOutputLength)
+	}
+	if opts.cost != nil {
+		d.Cost = opts.cost.cost()
+	}
+	return d, err
+}
+
+// validateSkipBodyRead returns an error if SkipBodyRead is set together with options that check the body.
+func (opts *Options) validateSkipBodyRead() error {
+	if !opts.SkipBodyRead {
+		return nil
+	}
+	checks := []struct {
+		name string
+		set  bool
+	}{
+		{"ExpectGzip", opts.ExpectGzip},
+		{"ExpectGzipSmaller", opts.ExpectGzipSmaller},
+		{"ExpectBodySHA256", opts.ExpectBodySHA256 != ""},
+		{"ExpectContentLengthMatch", opts.ExpectContentLengthMatch},
+		{"ExpectCharset", opts.ExpectCharset != ""},
+		{"RequireValidUTF8", opts.RequireValidUTF8},
+		{"ExpectJSONPath", opts.ExpectJSONPath != ""},
+		{"ExpectJSON", len(opts.ExpectJSON) > 0},
+		{"ExpectJSONAll", len(opts.ExpectJSONAll) > 0},
+		{"BodyAssertions", len(opts.BodyAssertions) > 0},
+		{"ExpectBodyEqualsFile", opts.ExpectBodyEqualsFile != ""},
+		{"ExpectUploadAckPath", opts.ExpectUploadAckPath != ""},
+		{"ExpectGRPCWebStatus", opts.ExpectGRPCWebStatus},
+	}
+	var conflicts []string
+	for _, c := range checks {
+		if c.set {
+			conflicts = append(conflicts, c.name)
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	return fmt.Errorf("SkipBodyRead cannot be combined with %s, which check the response body", strings.Join(conflicts, ", "))
+}
+
+// validateHTTP3 returns an error if HTTP3 is set together with options that only apply to TCP connections,
+// which the QUIC transport would otherwise ignore.
+func (opts *Options) validateHTTP3() error {
+	checks := []struct {
+		name string
+		set  bool
+	}{
+		{"DialAddress", opts.DialAddress != ""},
+		{"UnixSocket", opts.UnixSocket != ""},
+		{"LocalPorts", opts.LocalPorts != nil},
+		{"SOCKS5", opts.SOCKS5 != nil},
+		{"Proxy", opts.Proxy != nil},
+		{"UseEnvironmentProxy", opts.UseEnvironmentProxy},
+	}
+	var conflicts []string
+	for _, c := range checks {
+		if c.set {
+			conflicts = append(conflicts, c.name)
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	return fmt.Errorf("HTTP3 cannot be combined with %s, which only apply to TCP connections", strings.Join(conflicts, ", "))
+}
+
+// truncateOutput caps output at max bytes without splitting a UTF-8 encoded rune.
+func truncateOutput(output string, max int) string {
+	if len(output) <= max {
+		return output
+	}
+	i := max
+	for i > 0 && !utf8.RuneStart(output[i]) {
+		i--
+	}
+	return output[:i]
+}
+
+func doRequest(req *http.Request, url *url.URL, headers http.Header, client HTTPInterface, opts *Options) (Details, error) {
+	start := time.Now()
 	res, err := client.Do(req)
+	if errors.Is(err, api.ErrLocalPortsInUse) {
+		opts.record(req, nil, nil, err)
+		return Details{Result: api.Unknown, Output: err.Error()}, err
+	}
 	if err != nil {
+		opts.record(req, nil, nil, err)
 		// Convert errors into failures to catch timeouts.
-		return api.Failure, err.Error(), nil
+		reason, msg := classifyError(err)
+		result, reason := opts.errorResult(err, reason)
+		return Details{Result: result, Output: msg, Reason: reason}, nil
 	}
 	defer res.Body.Close()
-	b, err := utilio.ReadAtMost(res.Body, maxRespBodyLength)
+	d := Details{StatusCode: res.StatusCode}
+	if opts.reportTLS() && res.TLS != nil {
+		d.TLS = newTLSDetails(res.TLS)
+	}
+	if res.StatusCode < http.StatusOK {
+		opts.record(req, res, nil, nil)
+		return doInformationalResponse(res, url, opts, d, deadline(client, start)), nil
+	}
+	if opts.acceptsConditional(req, res) {
+		opts.record(req, res, nil, nil)
+		return doConditionalResponse(res, url, opts, d), nil
+	}
+	var body io.Reader = http.NoBody
+	var compressed *countingReader
+	if !opts.SkipBodyRead {
+		body, compressed, err = opts.bodyReader(res)
+		if err != nil {
+			opts.record(req, res, nil, nil)
+			d.Result, d.Reason = api.Failure, api.ReasonBodyReadFailed
+			return d, err
+		}
+	}
+	body, hasher := opts.hashBody(body)
+	var readErr error
+	b, err := utilio.ReadAtMost(body, maxRespBodyLength)
+	opts.record(req, res, b, nil)
+	size := int64(len(b))
+	if err == utilio.ErrLimitReached && hasher != nil {
+		// the hash covers the full body, read the remainder past the output limit
+		var n int64
+		n, err = io.Copy(io.Discard, body)
+		size += n
+	}
+	received := size
+	if compressed != nil {
+		received = compressed.n
+	}
+	truncated := err == utilio.ErrLimitReached
 	if err != nil {
-		if err == utilio.ErrLimitReached {
+		if truncated {
 			klog.V(5).Infof("Non fatal body truncation for %s, Response: %v", url.String(), *res)
+		} else if opts.TolerateBodyReadError {
+			klog.V(5).Infof("Non fatal body read error for %s: %v", url.String(), err)
+			readErr = err
+		} else if lengthErr := verifyContentLength(res, received); opts.ExpectContentLengthMatch && errors.Is(err, io.ErrUnexpectedEOF) && lengthErr != nil {
+			// the body ended before the declared Content-Length, report it as the mismatch
+			d.Result, d.Output, d.Reason = api.Failure, lengthErr.Error(), api.ReasonAssertionFailed
+			return d, nil
 		} else {
-			return api.Failure, "", err
+			d.Result, d.Reason = opts.errorResult(err, api.ReasonBodyReadFailed)
+			return d, err
 		}
 	}
 	respBody := string(b)
+	err = opts.verify(res)
+	if err == nil {
+		err = opts.verifyCompression(res, compressed, b, size)
+	}
+	if err == nil && opts.ExpectContentLengthMatch && !truncated {
+		err = verifyContentLength(res, received)
+	}
+	if err == nil {
+		err = opts.verifyBodyHash(hasher)
+	}
+	if err == nil {
+		err = opts.verifyCharset(res, b, truncated)
+	}
+	if err == nil {
+		err = opts.verifyBody(b)
+	}
+	if err == nil && req.Method == http.MethodPost {
+		err = opts.verifyUploadAck(b)
+	}
+	if err == nil && opts.ExpectGRPCWebStatus {
+		var status string
+		if status, err = verifyGRPCWebStatus(res, b); err == nil {
+			respBody = status
+		}
+	}
+	if err != nil {
+		klog.V(5).Infof("Probe failed for %s: %v", url.String(), err)
+		d.Result, d.Output, d.Reason = api.Failure, err.Error(), api.ReasonAssertionFailed
+		return d, nil
+	}
 	if res.StatusCode >= http.StatusOK && res.StatusCode < http.StatusBadRequest {
+		if isMalformedRedirect(res) {
+			klog.V(5).Infof("Probe received a redirect without Location for %s, Response: %v", url.String(), *res)
+			d.Result, d.Output = opts.malformedRedirectResult(), fmt.Sprintf("malformed redirect: %s response has no Location header", res.Status)
+			if d.Result == api.Failure {
+				d.Reason = api.ReasonMalformedRedirect
+			}
+			return d, nil
+		}
 		if res.StatusCode >= http.StatusMultipleChoices { // Redirect
 			klog.V(5).Infof("Probe terminated redirects for %s, Response: %v", url.String(), *res)
-			return api.Warning, respBody, nil
+			d.Result, d.Output = api.Warning, respBody
+			return d, nil
+		}
+		if readErr != nil {
+			klog.V(5).Infof("Probe succeeded with a partial body for %s, Response: %v", url.String(), *res)
+			d.Result, d.Output = api.Warning, fmt.Sprintf("%s\nbody read error: %v", respBody, readErr)
+			return d, nil
 		}
 		klog.V(5).Infof("Probe succeeded for %s, Response: %v", url.String(), *res)
-		return api.Success, respBody, nil
+		d.Result, d.Output = api.Success, respBody
+		return d, nil
 	}
-	klog.V(5).Infof("Probe failed for %s with request headers %v, response body: %v", url.String(), headers, respBody)
-	return api.Failure, fmt.Sprintf("HTTP probe failed with statuscode: %d", res.StatusCode), nil
+	if res.StatusCode == http.StatusTooManyRequests {
+		d.RetryAfter = retryAfter(res.Header.Get("Retry-After"), time.Now())
+		if result := opts.TooManyRequestsResult; result != "" && result != api.Failure {
+			klog.V(5).Infof("Probe throttled for %s, Response: %v", url.String(), *res)
+			d.Result, d.Output = result, fmt.Sprintf("HTTP probe throttled with statuscode: %d", res.StatusCode)
+			if d.RetryAfter > 0 {
+				d.Output += fmt.Sprintf(", retry after %v", d.RetryAfter)
+			}
+			return d, nil
+		}
+	}
+	klog.V(5).Infof("Probe failed for %s with request headers %v, response body: %v", url.String(), opts.redactHeaders(headers), respBody)
+	d.Result, d.Output, d.Reason = api.Failure, fmt.Sprintf("HTTP probe failed with statuscode: %d", res.StatusCode), api.ReasonUnexpectedStatus
+	if res.StatusCode == http.StatusUnauthorized && opts.Authenticator != nil {
+		d.Reason = api.ReasonAuthenticationFailed
+	}
+	return d, nil
+}
+
+// isMalformedRedirect reports whether res is a redirect that lacks the Location header it requires.
+// The client returns such responses instead of following them.
+func isMalformedRedirect(res *http.Response) bool {
+	switch res.StatusCode {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return res.Header.Get("Location") == ""
+	}
+	return false
+}
+
+// malformedRedirectResult returns the result of a probe that received a redirect without Location.
+func (opts *Options) malformedRedirectResult() api.Result {
+	if opts.MalformedRedirectResult != "" {
+		return opts.MalformedRedirectResult
+	}
+	return api.Failure
+}
+
+// deadline returns when the timeout of client for a request sent at start expires, or the zero time without a timeout.
+func deadline(client HTTPInterface, start time.Time) time.Time {
+	if c, ok := client.(*http.Client); ok && c.Timeout > 0 {
+		return start.Add(c.Timeout)
+	}
+	return time.Time{}
+}
+
+// doInformationalResponse evaluates a 1xx response. The client only returns 101 Switching Protocols,
+// other informational responses are consumed while waiting for the final response.
+// The body is not read, as after a protocol switch it is the upgraded connection.
+func doInformationalResponse(res *http.Response, url *url.URL, opts *Options, d Details, deadline time.Time) Details {
+	if err := opts.verify(res); err != nil {
+		klog.V(5).Infof("Probe failed for %s: %v", url.String(), err)
+		d.Result, d.Output, d.Reason = api.Failure, err.Error(), api.ReasonAssertionFailed
+		return d
+	}
+	for _, code := range opts.AcceptInformationalCodes {
+		if res.StatusCode == code {
+			if opts.MinOpenDuration > 0 && res.StatusCode == http.StatusSwitchingProtocols {
+				return opts.holdOpen(res, d, deadline)
+			}
+			klog.V(5).Infof("Probe succeeded for %s, Response: %v", url.String(), *res)
+			d.Result = api.Success
+			return d
+		}
+	}
+	klog.V(5).Infof("Probe failed for %s with informational response %s", url.String(), res.Status)
+	d.Result, d.Output, d.Reason = api.Failure, fmt.Sprintf("HTTP probe failed with statuscode: %d", res.StatusCode), api.ReasonUnexpectedStatus
+	return d
 }
 
-func redirectChecker(followNonLocalRedirects bool) func(*http.Request, []*http.Request) error {
-	if followNonLocalRedirects {
-		return nil // Use the default http client checker.
+// failureResult returns the result of a probe that failed with err, TimeoutResult if err is a timeout.
+func (opts *Options) failureResult(err error) api.Result {
+	if opts.TimeoutResult != "" && isTimeout(err) {
+		return opts.TimeoutResult
 	}
+	return api.Failure
+}
 
+// errorResult returns the result and failure reason of a probe that failed with err,
+// by ClassifyError if set, or else by failureResult and the default reason.
+func (opts *Options) errorResult(err error, reason api.FailureReason) (api.Result, api.FailureReason) {
+	if opts.ClassifyError != nil {
+		return opts.ClassifyError(err)
+	}
+	return opts.failureResult(err), reason
+}
+
+// DefaultErrorClassifier is the default mapping of the errors of HTTP probe requests: all of them fail the probe.
+// The reason is ReasonRedirectLoop for a redirect loop, ReasonEmptyResponse for a connection closed without
+// a response, ReasonTLSHandshakeFailed for TLS handshake and certificate verification errors, and
+// ReasonConnectionFailed otherwise. It can be called by an Options.ClassifyError that only overrides some errors.
+func DefaultErrorClassifier(err error) (api.Result, api.FailureReason) {
+	reason, _ := classifyError(err)
+	return api.Failure, reason
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// classifyError returns the failure reason for an error returned by the HTTP client,
+// together with the message to report for it.
+func classifyError(err error) (api.FailureReason, string) {
+	var (
+		verifyErr    *tls.CertificateVerificationError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+		peerErr      *peerVerificationError
+	)
+	switch {
+	case errors.Is(err, errRedirectLoop):
+		return api.ReasonRedirectLoop, err.Error()
+	case errors.Is(err, errServerPush):
+		return api.ReasonAssertionFailed, err.Error()
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return api.ReasonEmptyResponse, fmt.Sprintf("empty response, the connection was closed before a response was received: %v", err)
+	case errors.As(err, &peerErr):
+		return api.ReasonTLSHandshakeFailed, fmt.Sprintf("TLS peer certificate verification failed: %v", peerErr.err)
+	case errors.As(err, &verifyErr):
+		return api.ReasonTLSHandshakeFailed, fmt.Sprintf("TLS certificate verification failed: %v", verifyErr.Err)
+	case errors.As(err, &authorityErr), errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return api.ReasonTLSHandshakeFailed, fmt.Sprintf("TLS certificate verification failed: %v", errors.Unwrap(err))
+	case errors.As(err, &recordErr), errors.As(err, &alertErr):
+		return api.ReasonTLSHandshakeFailed, fmt.Sprintf("TLS handshake failed: %v", err)
+	}
+	return api.ReasonConnectionFailed, err.Error()
+}
+
+// errRedirectLoop is returned by the redirect checker for a redirect to a URL that was already requested.
+var errRedirectLoop = errors.New("redirect loop detected")
+
+func redirectChecker(followNonLocalRedirects bool, allowedHosts ...string) func(*http.Request, []*http.Request) error {
 	return func(req *http.Request, via []*http.Request) error {
-		if req.URL.Hostname() != via[0].URL.Hostname() {
+		if host := req.URL.Hostname(); !followNonLocalRedirects && host != via[0].URL.Hostname() && !isAllowedHost(host, allowedHosts) {
 			return http.ErrUseLastResponse
 		}
+		// Stop at the first repeated request instead of running into the redirect limit.
+		for _, prev := range via {
+			if prev.Method == req.Method && prev.URL.String() == req.URL.String() {
+				return fmt.Errorf("%w: %s %s was already requested", errRedirectLoop, req.Method, req.URL.Redacted())
+			}
+		}
 		// Default behavior: stop after 10 redirects.
 		if len(via) >= 10 {
 			return errors.New("stopped after 10 redirects")
@@ -79,3 +614,12 @@ func redirectChecker(followNonLocalRedirects bool) func(*http.Request, []*http.R
 		return nil
 	}
 }
+
+func isAllowedHost(host string, allowedHosts []string) bool {
+	for _, h := range allowedHosts {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	return false
+}
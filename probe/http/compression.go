@@ -0,0 +1,105 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+func (opts *Options) expectGzip() bool {
+	return opts.ExpectGzip || opts.ExpectGzipSmaller
+}
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// acceptEncoding returns the Accept-Encoding header the probe sets on its requests, if any.
+func (opts *Options) acceptEncoding() string {
+	if opts.AcceptEncoding != "" {
+		return opts.AcceptEncoding
+	}
+	if opts.expectGzip() {
+		return "gzip"
+	}
+	return ""
+}
+
+// bodyReader returns the reader of the response body. When the probe asked for the encoding and the response
+// is gzip encoded, the body is decoded here, as the transport only decodes responses to requests for which
+// it added Accept-Encoding itself, and the returned countingReader counts the encoded bytes.
+// With RawBody, the body is returned as received.
+func (opts *Options) bodyReader(res *http.Response) (io.Reader, *countingReader, error) {
+	if opts.acceptEncoding() == "" || res.Header.Get("Content-Encoding") != "gzip" {
+		return res.Body, nil, nil
+	}
+	compressed := &countingReader{r: res.Body}
+	if opts.RawBody {
+		return compressed, compressed, nil
+	}
+	gz, err := gzip.NewReader(compressed)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode gzip response body: %v", err)
+	}
+	return gz, compressed, nil
+}
+
+// verifyCompression checks that the response was gzip encoded, and optionally smaller than decoded.
+// compressed counts the encoded bytes that were read for the first uncompressed bytes of the body.
+// With RawBody, body holds the encoded bytes read, which are decoded here to compare the sizes.
+func (opts *Options) verifyCompression(res *http.Response, compressed *countingReader, body []byte, uncompressed int64) error {
+	if !opts.expectGzip() {
+		return nil
+	}
+	if compressed == nil {
+		return fmt.Errorf("expected a gzip encoded response, got Content-Encoding %q", res.Header.Get("Content-Encoding"))
+	}
+	if !opts.ExpectGzipSmaller {
+		return nil
+	}
+	encoded := compressed.n
+	if opts.RawBody {
+		encoded, uncompressed = int64(len(body)), decodedSize(body)
+	}
+	if encoded >= uncompressed {
+		return fmt.Errorf("gzip encoding did not reduce the size: %d bytes encoded, %d bytes decoded", encoded, uncompressed)
+	}
+	return nil
+}
+
+// decodedSize returns the number of bytes that the gzip encoded b decodes to. b may be cut short,
+// e.g. at the output limit, in which case the bytes decoded so far are counted.
+func decodedSize(b []byte) int64 {
+	gz, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return 0
+	}
+	n, _ := io.Copy(io.Discard, gz)
+	return n
+}
@@ -0,0 +1,83 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	api "kmodules.xyz/prober/api"
+
+	"k8s.io/klog/v2"
+)
+
+// conditionalHeaders are the request headers that make a request conditional.
+var conditionalHeaders = []string{"If-None-Match", "If-Modified-Since", "If-Match", "If-Unmodified-Since", "If-Range"}
+
+// conditionalRequestHeaders returns headers with IfNoneMatch and IfModifiedSince set, unless they already are.
+func (opts *Options) conditionalRequestHeaders(headers http.Header) http.Header {
+	if opts.IfNoneMatch != "" && headers.Get("If-None-Match") == "" {
+		headers = headers.Clone()
+		headers.Set("If-None-Match", opts.IfNoneMatch)
+	}
+	if !opts.IfModifiedSince.IsZero() && headers.Get("If-Modified-Since") == "" {
+		headers = headers.Clone()
+		headers.Set("If-Modified-Since", opts.IfModifiedSince.UTC().Format(http.TimeFormat))
+	}
+	return headers
+}
+
+// acceptsConditional reports whether res is the answer to a conditional request that passes the probe.
+func (opts *Options) acceptsConditional(req *http.Request, res *http.Response) bool {
+	if opts.IfNoneMatch == "" && opts.IfModifiedSince.IsZero() && len(opts.AcceptConditionalCodes) == 0 {
+		return false
+	}
+	conditional := false
+	for _, name := range conditionalHeaders {
+		if req.Header.Get(name) != "" {
+			conditional = true
+			break
+		}
+	}
+	if !conditional {
+		return false
+	}
+	codes := opts.AcceptConditionalCodes
+	if len(codes) == 0 {
+		codes = []int{http.StatusNotModified}
+	}
+	for _, code := range codes {
+		if res.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// doConditionalResponse evaluates an accepted response to a conditional request. Such responses,
+// e.g. 304 Not Modified, have no body, so only the response headers are checked.
+func doConditionalResponse(res *http.Response, url *url.URL, opts *Options, d Details) Details {
+	if err := opts.verify(res); err != nil {
+		klog.V(5).Infof("Probe failed for %s: %v", url.String(), err)
+		d.Result, d.Output, d.Reason = api.Failure, err.Error(), api.ReasonAssertionFailed
+		return d
+	}
+	klog.V(5).Infof("Probe succeeded for %s with conditional response %s", url.String(), res.Status)
+	d.Result, d.Output = api.Success, fmt.Sprintf("conditional request answered with %s", res.Status)
+	return d
+}
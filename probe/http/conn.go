@@ -0,0 +1,92 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+
+	"k8s.io/klog/v2"
+)
+
+// ConnGetProber runs HTTP GET checks over connections established by the caller,
+// e.g. through a tunnel. The GetProber returned by the constructors of this package implements it.
+type ConnGetProber interface {
+	// ProbeConn runs an HTTP GET check like Probe, but over conn instead of dialing the URL host.
+	// TLS is negotiated over conn for https URLs. conn is closed when the probe is done, and
+	// redirects that need another connection fail the probe.
+	ProbeConn(conn net.Conn, url *url.URL, headers http.Header, timeout time.Duration) (api.Result, string, error)
+}
+
+var _ ConnGetProber = httpGetProber{}
+
+// errConnUsed is returned when a probe over a supplied connection needs a second connection.
+var errConnUsed = errors.New("the supplied connection has already been used")
+
+// ProbeConn runs an HTTP GET check over conn.
+func (pr httpGetProber) ProbeConn(conn net.Conn, url *url.URL, headers http.Header, timeout time.Duration) (api.Result, string, error) {
+	d := &oneShotDialer{conn: conn}
+	defer d.close()
+	if pr.opts.HTTP3 {
+		return api.Unknown, "", errors.New("HTTP/3 probes can not run over a supplied connection")
+	}
+	transport := pr.transport.Clone()
+	transport.DisableKeepAlives = true
+	transport.Proxy = nil
+	transport.DialContext = d.DialContext
+	transport.DialTLSContext = nil
+
+	opts := pr.opts
+	client, release, err := newClient(transport, pr.followNonLocalRedirects, &opts, timeout)
+	if err != nil {
+		return api.Unknown, err.Error(), err
+	}
+	defer release()
+	res, err := doHTTPGetProbe(url, headers, client, &opts)
+	return res.Result, res.Output, err
+}
+
+// oneShotDialer hands out conn to the first dial only.
+type oneShotDialer struct {
+	lock sync.Mutex
+	conn net.Conn
+	used bool
+}
+
+func (d *oneShotDialer) DialContext(context.Context, string, string) (net.Conn, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.used {
+		return nil, errConnUsed
+	}
+	d.used = true
+	return d.conn, nil
+}
+
+// close closes conn, in case the transport did not use or already closed it.
+func (d *oneShotDialer) close() {
+	if err := d.conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+		klog.V(5).Infof("Unexpected error closing the supplied probe connection: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync/atomic"
+)
+
+// Cost is the approximate resource cost of a probe, e.g. to tune probe frequencies for capacity planning.
+// It covers all requests of the probe, including redirects, retries and samples.
+type Cost struct {
+	// Connections is the number of connections the probe opened.
+	Connections int64
+	// BytesSent is the number of bytes written to those connections, including the TLS handshake.
+	BytesSent int64
+	// BytesReceived is the number of bytes read from those connections, including the TLS handshake.
+	BytesReceived int64
+}
+
+// costCounter tallies the Cost of a probe.
+type costCounter struct {
+	connections atomic.Int64
+	sent        atomic.Int64
+	received    atomic.Int64
+}
+
+// countingTransport returns a clone of transport whose connections are counted by c.
+// The clone has its own connection pool, so the probe does not reuse pooled connections.
+func (c *costCounter) countingTransport(transport *http.Transport) *http.Transport {
+	transport = transport.Clone()
+	dial := transport.DialContext
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		c.connections.Add(1)
+		return &costConn{Conn: conn, cost: c}, nil
+	}
+	return transport
+}
+
+func (c *costCounter) cost() *Cost {
+	return &Cost{
+		Connections:   c.connections.Load(),
+		BytesSent:     c.sent.Load(),
+		BytesReceived: c.received.Load(),
+	}
+}
+
+// costConn counts the bytes read from and written to a connection.
+type costConn struct {
+	net.Conn
+	cost *costCounter
+}
+
+func (c *costConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.cost.received.Add(int64(n))
+	return n, err
+}
+
+func (c *costConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.cost.sent.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,95 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/url"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+)
+
+// Details is the detailed outcome of an HTTP probe.
+type Details struct {
+	Result api.Result
+	Output string
+	// Reason classifies why the probe did not succeed. It is empty for successful probes.
+	Reason api.FailureReason
+	// StatusCode is the status code of the response, or zero if no response was received.
+	StatusCode int
+	// RetryAfter is the delay requested by the Retry-After header of a 429 Too Many Requests response,
+	// or zero if there is none.
+	RetryAfter time.Duration
+	// Timings is the latency breakdown of the probe. It is only set when Options.Trace is enabled.
+	Timings *Timings
+	// TLS describes the TLS connection the response was received over. It is only set when
+	// Options.ReportTLS, Options.TLSMinAcceptedVersion, Options.RequireOCSPStaple or Options.RequireOCSPGood
+	// is enabled and the response was received over TLS.
+	TLS *TLSDetails
+	// Cost is the cost of the probe so far. It is only set when Options.AccountCost is enabled.
+	Cost *Cost
+}
+
+// TLSDetails is the negotiated TLS connection of an HTTP probe, e.g. for a TLS inventory.
+// When redirects are followed, it describes the connection of the last response.
+type TLSDetails struct {
+	// Version is the negotiated TLS version, e.g. "TLS 1.3".
+	Version string
+	// CipherSuite is the negotiated cipher suite, e.g. "TLS_AES_128_GCM_SHA256".
+	CipherSuite string
+	// PeerSubject is the subject of the server's leaf certificate.
+	PeerSubject string
+	// PeerNotAfter is the expiry of the server's leaf certificate.
+	PeerNotAfter time.Time
+	// OCSPStapled reports whether the server stapled an OCSP response to the handshake.
+	OCSPStapled bool
+}
+
+// reportTLS reports whether the TLS connection of a response is described in Details.TLS.
+func (opts *Options) reportTLS() bool {
+	return opts.ReportTLS || opts.TLSMinAcceptedVersion != 0 || opts.RequireOCSPStaple || opts.RequireOCSPGood
+}
+
+func newTLSDetails(state *tls.ConnectionState) *TLSDetails {
+	d := &TLSDetails{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		OCSPStapled: len(state.OCSPResponse) > 0,
+	}
+	if len(state.PeerCertificates) > 0 {
+		leaf := state.PeerCertificates[0]
+		d.PeerSubject = leaf.Subject.String()
+		d.PeerNotAfter = leaf.NotAfter
+	}
+	return d
+}
+
+// DetailedGetProber is a GetProber that can also report the detailed outcome of a probe.
+// The GetProber returned by the constructors of this package implements it.
+type DetailedGetProber interface {
+	GetProber
+	ProbeDetailed(url *url.URL, headers http.Header, timeout time.Duration) (Details, error)
+}
+
+// DetailedPostProber is a PostProber that can also report the detailed outcome of a probe.
+// The PostProber returned by the constructors of this package implements it.
+type DetailedPostProber interface {
+	PostProber
+	ProbeDetailed(url *url.URL, headers http.Header, form url.Values, body string, timeout time.Duration) (Details, error)
+}
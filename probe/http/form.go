@@ -0,0 +1,109 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// EncodeForm builds the url encoded form of an HTTP POST probe from typed values, e.g.
+// {"replicas": 3, "dryRun": true, "zones": []string{"a", "b"}}. Values are encoded as follows:
+//   - strings, including types based on string, and []byte as is,
+//   - bools as "true" or "false",
+//   - integers and floats in decimal notation, without exponent,
+//   - time.Time in RFC 3339 and time.Duration as printed by its String method,
+//   - other fmt.Stringers by their String method,
+//   - slices and arrays of the above as one value per element.
+//
+// It returns an error for an empty key, a nil value, a NaN or infinite float, or a value of another type.
+func EncodeForm(fields map[string]interface{}) (url.Values, error) {
+	form := make(url.Values, len(fields))
+	for key, value := range fields {
+		if key == "" {
+			return nil, fmt.Errorf("form field with an empty key")
+		}
+		values, err := encodeFormValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("form field %s: %w", key, err)
+		}
+		form[key] = values
+	}
+	return form, nil
+}
+
+func encodeFormValue(value interface{}) ([]string, error) {
+	if b, ok := value.([]byte); ok {
+		return []string{string(b)}, nil
+	}
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		s, err := encodeFormScalar(value)
+		if err != nil {
+			return nil, err
+		}
+		return []string{s}, nil
+	}
+	values := make([]string, v.Len())
+	for i := range values {
+		s, err := encodeFormScalar(v.Index(i).Interface())
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %w", i, err)
+		}
+		values[i] = s
+	}
+	return values, nil
+}
+
+func encodeFormScalar(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", fmt.Errorf("nil value")
+	case string:
+		return v, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case time.Duration:
+		return v.String(), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		f := rv.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return "", fmt.Errorf("%v can not be encoded", f)
+		}
+		return strconv.FormatFloat(f, 'f', -1, rv.Type().Bits()), nil
+	}
+	return "", fmt.Errorf("unsupported type %T", value)
+}
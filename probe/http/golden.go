@@ -0,0 +1,99 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// BodyComparison selects how the response body is compared with the golden file of ExpectBodyEqualsFile.
+type BodyComparison string
+
+const (
+	// BodyComparisonExact compares the body byte by byte.
+	BodyComparisonExact BodyComparison = "Exact"
+	// BodyComparisonJSON compares the body as JSON, ignoring formatting and the order of object keys.
+	BodyComparisonJSON BodyComparison = "JSON"
+)
+
+// readGoldenFile loads the golden file of ExpectBodyEqualsFile for a single probe.
+func (opts *Options) readGoldenFile() error {
+	if opts.ExpectBodyEqualsFile == "" {
+		return nil
+	}
+	golden, err := os.ReadFile(opts.ExpectBodyEqualsFile)
+	if err != nil {
+		return fmt.Errorf("failed to read golden file: %v", err)
+	}
+	opts.golden = golden
+	return nil
+}
+
+// verifyGolden compares body with the golden file read by readGoldenFile.
+func (opts *Options) verifyGolden(body []byte) error {
+	if opts.golden == nil {
+		return nil
+	}
+	want, got := opts.golden, body
+	if opts.BodyFileComparison == BodyComparisonJSON {
+		var err error
+		if want, err = normalizeJSON(want); err != nil {
+			return fmt.Errorf("failed to parse golden file %s as JSON: %v", opts.ExpectBodyEqualsFile, err)
+		}
+		if got, err = normalizeJSON(got); err != nil {
+			return fmt.Errorf("failed to parse response body as JSON: %v", err)
+		}
+	}
+	if bytes.Equal(want, got) {
+		return nil
+	}
+	return fmt.Errorf("body differs from golden file %s%s", opts.ExpectBodyEqualsFile, diffSummary(want, got))
+}
+
+// normalizeJSON re-encodes data with sorted object keys and consistent indentation.
+func normalizeJSON(data []byte) ([]byte, error) {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
+
+// diffSummary describes the first line that differs between want and got.
+func diffSummary(want, got []byte) string {
+	wantLines := strings.Split(strings.TrimSuffix(string(want), "\n"), "\n")
+	gotLines := strings.Split(strings.TrimSuffix(string(got), "\n"), "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if i >= len(wantLines) || i >= len(gotLines) || w != g {
+			return fmt.Sprintf(" at line %d (%d lines expected, %d received):\n- %s\n+ %s",
+				i+1, len(wantLines), len(gotLines), w, g)
+		}
+	}
+	// the lines only differ in a trailing newline
+	return fmt.Sprintf(" (%d bytes expected, %d received)", len(want), len(got))
+}
@@ -0,0 +1,117 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+)
+
+// grpcWebTrailerFlag marks the frame of a gRPC-Web response body that holds the trailers.
+const grpcWebTrailerFlag = 0x80
+
+// verifyGRPCWebStatus decodes the gRPC status of a gRPC-Web response, from the trailer frame of the body or,
+// for trailers-only responses, from the headers, and fails unless it is 0 (OK). Bodies of the
+// application/grpc-web-text content type are base64 decoded first. It returns the decoded status.
+func verifyGRPCWebStatus(res *http.Response, body []byte) (string, error) {
+	if strings.HasPrefix(res.Header.Get("Content-Type"), "application/grpc-web-text") {
+		decoded, err := decodeGRPCWebText(body)
+		if err != nil {
+			return "", fmt.Errorf("failed to decode gRPC-Web text body: %v", err)
+		}
+		body = decoded
+	}
+	trailers, err := grpcWebTrailers(body)
+	if err != nil {
+		return "", err
+	}
+	if trailers.Get("grpc-status") == "" {
+		trailers = res.Header
+	}
+	status := trailers.Get("grpc-status")
+	if status == "" {
+		return "", errors.New("gRPC-Web response has no grpc-status")
+	}
+	code, err := strconv.ParseUint(status, 10, 32)
+	if err != nil {
+		return "", fmt.Errorf("gRPC-Web response has an invalid grpc-status %q", status)
+	}
+	out := fmt.Sprintf("grpc-status: %d (%s)", code, codes.Code(code))
+	if msg := trailers.Get("grpc-message"); msg != "" {
+		if unescaped, err := url.PathUnescape(msg); err == nil {
+			msg = unescaped
+		}
+		out += ", grpc-message: " + msg
+	}
+	if codes.Code(code) != codes.OK {
+		return "", fmt.Errorf("%s, expected grpc-status: 0 (OK)", out)
+	}
+	return out, nil
+}
+
+// decodeGRPCWebText decodes a grpc-web-text body, which may be a concatenation of padded base64 chunks.
+func decodeGRPCWebText(body []byte) ([]byte, error) {
+	body = bytes.Join(bytes.Fields(body), nil)
+	if len(body)%4 != 0 {
+		return nil, base64.CorruptInputError(len(body) / 4 * 4)
+	}
+	out := make([]byte, 0, base64.StdEncoding.DecodedLen(len(body)))
+	buf := make([]byte, 3)
+	for i := 0; i < len(body); i += 4 {
+		n, err := base64.StdEncoding.Decode(buf, body[i:i+4])
+		if err != nil {
+			return nil, base64.CorruptInputError(i)
+		}
+		out = append(out, buf[:n]...)
+	}
+	return out, nil
+}
+
+// grpcWebTrailers returns the trailers of the frames of a gRPC-Web body, or empty trailers if it has no trailer frame.
+func grpcWebTrailers(body []byte) (http.Header, error) {
+	for len(body) > 0 {
+		if len(body) < 5 {
+			return nil, errors.New("gRPC-Web response body ends with a truncated frame header")
+		}
+		flag := body[0]
+		n := int(body[1])<<24 | int(body[2])<<16 | int(body[3])<<8 | int(body[4])
+		body = body[5:]
+		if n > len(body) {
+			return nil, fmt.Errorf("gRPC-Web response body ends with a truncated frame of %d bytes", n)
+		}
+		if flag&grpcWebTrailerFlag != 0 {
+			payload := append(body[:n:n], "\r\n"...)
+			header, err := textproto.NewReader(bufio.NewReader(bytes.NewReader(payload))).ReadMIMEHeader()
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse gRPC-Web trailers: %v", err)
+			}
+			return http.Header(header), nil
+		}
+		body = body[n:]
+	}
+	return http.Header{}, nil
+}
@@ -0,0 +1,47 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+)
+
+// hashBody returns a reader that hashes everything read from body, when ExpectBodySHA256 is set.
+func (opts *Options) hashBody(body io.Reader) (io.Reader, hash.Hash) {
+	if opts.ExpectBodySHA256 == "" {
+		return body, nil
+	}
+	h := sha256.New()
+	return io.TeeReader(body, h), h
+}
+
+// verifyBodyHash checks the SHA-256 of the full body against ExpectBodySHA256.
+func (opts *Options) verifyBodyHash(h hash.Hash) error {
+	if h == nil {
+		return nil
+	}
+	sum := hex.EncodeToString(h.Sum(nil))
+	if !strings.EqualFold(sum, strings.TrimSpace(opts.ExpectBodySHA256)) {
+		return fmt.Errorf("expected body SHA-256 %s, got %s", opts.ExpectBodySHA256, sum)
+	}
+	return nil
+}
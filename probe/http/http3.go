@@ -0,0 +1,39 @@
+//go:build http3
+
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/tls"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+)
+
+// newHTTP3Transport returns a QUIC based transport whose handshake is bounded by timeout.
+func newHTTP3Transport(config *tls.Config, timeout time.Duration) (http3RoundTripper, error) {
+	qc := &quic.Config{}
+	if timeout > 0 {
+		qc.HandshakeIdleTimeout = timeout
+	}
+	return &http3.Transport{
+		TLSClientConfig: config,
+		QUICConfig:      qc,
+	}, nil
+}
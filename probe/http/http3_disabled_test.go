@@ -0,0 +1,40 @@
+//go:build !http3
+
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/url"
+	"testing"
+
+	api "kmodules.xyz/prober/api"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestHTTP3ProbeUnsupported(t *testing.T) {
+	target, err := url.Parse("https://127.0.0.1:8443/healthz")
+	require.NoError(t, err)
+
+	prober := NewGetWithOptions(nil, false, Options{HTTP3: true})
+	result, _, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+	assert.Equal(t, api.Unknown, result)
+	assert.ErrorIs(t, err, ErrHTTP3Unsupported)
+}
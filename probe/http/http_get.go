@@ -17,14 +17,13 @@ limitations under the License.
 package http
 
 import (
+	"context"
 	"crypto/tls"
 	"net/http"
 	"net/url"
 	"time"
 
 	api "kmodules.xyz/prober/api"
-
-	utilnet "k8s.io/apimachinery/pkg/util/net"
 )
 
 const (
@@ -45,14 +44,15 @@ func NewHttpGet(followNonLocalRedirects bool) GetProber {
 //
 //	If disabled, redirects to other hosts will trigger a warning result.
 func NewGetWithTLSConfig(config *tls.Config, followNonLocalRedirects bool) GetProber {
-	// We do not want the probe use node's local proxy set.
-	transport := utilnet.SetTransportDefaults(
-		&http.Transport{
-			TLSClientConfig:   config,
-			DisableKeepAlives: true,
-			Proxy:             http.ProxyURL(nil),
-		})
-	return httpGetProber{transport, followNonLocalRedirects}
+	return NewGetWithOptions(config, followNonLocalRedirects, Options{})
+}
+
+// NewGetWithOptions takes tls config and additional prober options as parameter.
+// followNonLocalRedirects configures whether the prober should follow redirects to a different hostname.
+//
+//	If disabled, redirects to other hosts will trigger a warning result.
+func NewGetWithOptions(config *tls.Config, followNonLocalRedirects bool, opts Options) GetProber {
+	return NewGet(WithOptions(opts), WithTLSConfig(config), WithFollowNonLocalRedirects(followNonLocalRedirects))
 }
 
 // GetProber is an interface that defines the Probe function for doing HTTP probe.
@@ -63,16 +63,59 @@ type GetProber interface {
 type httpGetProber struct {
 	transport               *http.Transport
 	followNonLocalRedirects bool
+	opts                    Options
 }
 
 // Probe returns a ProbeRunner capable of running an HTTP check.
 func (pr httpGetProber) Probe(url *url.URL, headers http.Header, timeout time.Duration) (api.Result, string, error) {
-	client := &http.Client{
-		Timeout:       timeout,
-		Transport:     pr.transport,
-		CheckRedirect: redirectChecker(pr.followNonLocalRedirects),
+	d, err := pr.ProbeDetailed(url, headers, timeout)
+	return d.Result, d.Output, err
+}
+
+// ProbeDetailed runs an HTTP check like Probe and reports its detailed outcome.
+func (pr httpGetProber) ProbeDetailed(url *url.URL, headers http.Header, timeout time.Duration) (Details, error) {
+	return pr.probeDetailed(url, headers, probeScope{}, timeout)
+}
+
+// probeDetailed runs an HTTP check with scope, whose pod is used to render templated assertions and whose
+// settings override the options of the prober.
+func (pr httpGetProber) probeDetailed(url *url.URL, headers http.Header, scope probeScope, timeout time.Duration) (Details, error) {
+	opts := pr.opts
+	scope.apply(&opts)
+	client, release, err := newClient(pr.transport, pr.followNonLocalRedirects, &opts, timeout)
+	if err != nil {
+		return Details{Result: api.Unknown, Output: err.Error()}, err
 	}
-	return DoHTTPGetProbe(url, headers, client)
+	defer release()
+	return opts.sample(client, func() (Details, error) {
+		return doHTTPGetProbe(url, headers, client, &opts)
+	})
+}
+
+// NewGetTargetProber adapts a GetProber to the unified api.Prober interface.
+func NewGetTargetProber(p GetProber) api.Prober {
+	return getTargetProber{p}
+}
+
+type getTargetProber struct {
+	GetProber
+}
+
+// Probe runs an HTTP GET check against target.URL.
+func (pr getTargetProber) Probe(ctx context.Context, target api.Target) (api.Result, string, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Unknown, "", err
+	}
+	if p, ok := pr.GetProber.(podGetProber); ok {
+		d, err := p.probeDetailed(target.URL, target.Headers, scopeOf(target), api.TimeoutFor(ctx, target))
+		return d.Result, d.Output, err
+	}
+	return pr.GetProber.Probe(target.URL, target.Headers, api.TimeoutFor(ctx, target))
+}
+
+// podGetProber is implemented by the GetProber of this package to pass the scope of the target through.
+type podGetProber interface {
+	probeDetailed(url *url.URL, headers http.Header, scope probeScope, timeout time.Duration) (Details, error)
 }
 
 // DoHTTPGetProbe checks if a GET request to the url succeeds.
@@ -80,10 +123,15 @@ func (pr httpGetProber) Probe(url *url.URL, headers http.Header, timeout time.Du
 // If the HTTP response code is unsuccessful or HTTP communication fails, it returns Failure.
 // This is exported because some other packages may want to do direct HTTP probes.
 func DoHTTPGetProbe(url *url.URL, headers http.Header, client HTTPInterface) (api.Result, string, error) {
+	d, err := doHTTPGetProbe(url, headers, client, &Options{})
+	return d.Result, d.Output, err
+}
+
+func doHTTPGetProbe(url *url.URL, headers http.Header, client HTTPInterface, opts *Options) (Details, error) {
 	req, err := http.NewRequest(http.MethodGet, url.String(), nil)
 	if err != nil {
 		// Convert errors into failures to catch timeouts.
-		return api.Failure, err.Error(), nil
+		return Details{Result: api.Failure, Output: err.Error(), Reason: api.ReasonConnectionFailed}, nil
 	}
-	return doHTTPProbe(req, url, headers, client)
+	return doHTTPProbe(req, url, headers, client, opts)
 }
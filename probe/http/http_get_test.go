@@ -19,14 +19,32 @@ package http
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"math/big"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 
@@ -34,6 +52,10 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 )
@@ -330,6 +352,171 @@ func TestHTTPProbeChecker_NonLocalRedirects(t *testing.T) {
 	}
 }
 
+func TestHTTPProbeChecker_AllowedRedirectHosts(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer target.Close()
+	targetURL, err := url.Parse(target.URL)
+	require.NoError(t, err)
+	// The redirect goes to the same server under a different hostname, which makes it non-local.
+	loc := "http://localhost:" + targetURL.Port() + "/success"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, loc, http.StatusFound)
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		allowedHosts []string
+		expected     api.Result
+	}{
+		"no allowed hosts":  {nil, api.Warning},
+		"allowed host":      {[]string{"auth.example.com", "localhost"}, api.Success},
+		"allowed uppercase": {[]string{"LOCALHOST"}, api.Success},
+		"disallowed host":   {[]string{"auth.example.com"}, api.Warning},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, Options{AllowedRedirectHosts: tt.allowedHosts})
+			u, err := url.Parse(server.URL + "/redirect")
+			require.NoError(t, err)
+			result, _, err := prober.Probe(u, nil, wait.ForeverTestTimeout)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_RedirectLoop(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		switch r.URL.Path {
+		case "/loop":
+			http.Redirect(w, r, "/loop", http.StatusFound)
+		case "/ping":
+			http.Redirect(w, r, "/pong", http.StatusFound)
+		case "/pong":
+			http.Redirect(w, r, "/ping", http.StatusFound)
+		case "/chain":
+			n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+			http.Redirect(w, r, fmt.Sprintf("/chain?n=%d", n+1), http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		path   string
+		reason api.FailureReason
+		output string
+		calls  int32
+	}{
+		"self redirect": {"/loop", api.ReasonRedirectLoop, "redirect loop detected: GET " + server.URL + "/loop was already requested", 1},
+		"cycle":         {"/ping", api.ReasonRedirectLoop, "redirect loop detected: GET " + server.URL + "/ping was already requested", 2},
+		"no loop":       {"/chain?n=0", api.ReasonConnectionFailed, "stopped after 10 redirects", 10},
+	}
+	for name, tt := range testCases {
+		for _, followNonLocal := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/followNonLocal=%v", name, followNonLocal), func(t *testing.T) {
+				calls.Store(0)
+				prober := NewHttpGet(followNonLocal).(DetailedGetProber)
+				target, err := url.Parse(server.URL + tt.path)
+				require.NoError(t, err)
+				d, err := prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+				assert.NoError(t, err)
+				assert.Equal(t, api.Failure, d.Result)
+				assert.Equal(t, tt.reason, d.Reason)
+				assert.Contains(t, d.Output, tt.output)
+				assert.Equal(t, tt.calls, calls.Load())
+			})
+		}
+	}
+}
+
+func TestHTTPProbeChecker_ExpectRedirectCount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/moved", http.StatusMovedPermanently)
+		case "/moved":
+			http.Redirect(w, r, "/new", http.StatusFound)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	count := func(n int) *int { return &n }
+	testCases := map[string]struct {
+		path   string
+		expect *int
+		result api.Result
+		output string
+	}{
+		"disabled":      {"/old", nil, api.Success, ""},
+		"no redirect":   {"/new", count(0), api.Success, ""},
+		"one redirect":  {"/moved", count(1), api.Success, ""},
+		"two redirects": {"/old", count(2), api.Success, ""},
+		"too many":      {"/old", count(1), api.Failure, "expected 1 redirects, observed 2"},
+		"too few":       {"/new", count(1), api.Failure, "expected 1 redirects, observed 0"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, Options{ExpectRedirectCount: tt.expect})
+			target, err := url.Parse(server.URL + tt.path)
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_ExpectHTTPS(t *testing.T) {
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer secure.Close()
+	secureURL, err := url.Parse(secure.URL)
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/secure":
+			http.Redirect(w, r, secure.URL+"/", http.StatusMovedPermanently)
+		case "/other-host":
+			http.Redirect(w, r, "https://localhost:"+secureURL.Port()+"/", http.StatusMovedPermanently)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		path           string
+		followNonLocal bool
+		result         api.Result
+		output         string
+	}{
+		"redirected":          {"/secure", false, api.Success, ""},
+		"not redirected":      {"/plain", false, api.Failure, "expected the final URL to use https, got " + server.URL + "/plain"},
+		"redirect not taken":  {"/other-host", false, api.Failure, "with a redirect to https://localhost:" + secureURL.Port() + "/ that was not followed"},
+		"redirect other host": {"/other-host", true, api.Success, ""},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(&tls.Config{InsecureSkipVerify: true}, tt.followNonLocal, Options{ExpectHTTPS: true})
+			target, err := url.Parse(server.URL + tt.path)
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+		})
+	}
+}
+
 func TestHTTPProbeChecker_HostHeaderPreservedAfterRedirect(t *testing.T) {
 	successHostHeader := "www.success.com"
 	failHostHeader := "www.fail.com"
@@ -449,3 +636,2544 @@ func TestHTTPProbeChecker_PayloadNormal(t *testing.T) {
 		assert.Equal(t, body, string(normalPayload))
 	})
 }
+
+func TestHTTPProbeChecker_TLSMinAcceptedVersion(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{MaxVersion: tls.VersionTLS12}
+	server.StartTLS()
+	defer server.Close()
+
+	plainServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer plainServer.Close()
+
+	testCases := map[string]struct {
+		url        string
+		minVersion uint16
+		result     api.Result
+		output     string
+		version    string
+	}{
+		"disabled":         {server.URL, 0, api.Success, "", ""},
+		"accepted":         {server.URL, tls.VersionTLS12, api.Success, "", "TLS 1.2"},
+		"below threshold":  {server.URL, tls.VersionTLS13, api.Failure, "negotiated TLS 1.2, minimum accepted TLS 1.3", "TLS 1.2"},
+		"plain connection": {plainServer.URL, tls.VersionTLS12, api.Failure, "not received over TLS", ""},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(&tls.Config{InsecureSkipVerify: true}, false, Options{TLSMinAcceptedVersion: tt.minVersion}).(DetailedGetProber)
+			target, err := url.Parse(tt.url)
+			require.NoError(t, err)
+			d, err := prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, d.Result)
+			assert.Contains(t, d.Output, tt.output)
+			if tt.version == "" {
+				assert.Nil(t, d.TLS)
+			} else if assert.NotNil(t, d.TLS) {
+				assert.Equal(t, tt.version, d.TLS.Version)
+			}
+		})
+	}
+}
+
+func TestHTTPProbeChecker_OCSPStaple(t *testing.T) {
+	newServer := func(staple []byte) *httptest.Server {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		server.TLS.Certificates[0].OCSPStaple = staple
+		return server
+	}
+	withoutStaple := newServer(nil)
+	defer withoutStaple.Close()
+	withStaple := newServer([]byte("not a real ocsp response"))
+	defer withStaple.Close()
+
+	testCases := map[string]struct {
+		url     string
+		opts    Options
+		result  api.Result
+		output  string
+		stapled bool
+	}{
+		"disabled":            {withoutStaple.URL, Options{}, api.Success, "", false},
+		"missing staple":      {withoutStaple.URL, Options{RequireOCSPStaple: true}, api.Failure, "stapled response present: false", false},
+		"staple present":      {withStaple.URL, Options{RequireOCSPStaple: true}, api.Success, "", true},
+		"missing good staple": {withoutStaple.URL, Options{RequireOCSPGood: true}, api.Failure, "stapled response present: false", false},
+		"unparsable staple":   {withStaple.URL, Options{RequireOCSPGood: true}, api.Failure, "stapled response present: true, failed to parse", true},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(&tls.Config{InsecureSkipVerify: true}, false, tt.opts).(DetailedGetProber)
+			target, err := url.Parse(tt.url)
+			require.NoError(t, err)
+			d, err := prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, d.Result)
+			assert.Contains(t, d.Output, tt.output)
+			if !tt.opts.reportTLS() {
+				assert.Nil(t, d.TLS)
+			} else if assert.NotNil(t, d.TLS) {
+				assert.Equal(t, tt.stapled, d.TLS.OCSPStapled)
+			}
+		})
+	}
+}
+
+func TestHTTPProbeChecker_ExpectStatusText(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, bufrw, err := w.(http.Hijacker).Hijack()
+		utilruntime.Must(err)
+		defer conn.Close()
+		_, err = bufrw.WriteString("HTTP/1.1 200 WARMING\r\nContent-Length: 2\r\n\r\nok")
+		utilruntime.Must(err)
+		utilruntime.Must(bufrw.Flush())
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		pattern *regexp.Regexp
+		result  api.Result
+		output  string
+	}{
+		"disabled": {nil, api.Success, "ok"},
+		"match":    {regexp.MustCompile(`^200 WARMING$`), api.Success, "ok"},
+		"mismatch": {regexp.MustCompile(`^200 OK$`), api.Failure, `status line "200 WARMING"`},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, Options{ExpectStatusText: tt.pattern})
+			target, err := url.Parse(server.URL)
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_TolerateBodyReadError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, bufrw, err := w.(http.Hijacker).Hijack()
+		utilruntime.Must(err)
+		defer conn.Close()
+		// promise more bytes than are written before the connection is closed
+		_, err = bufrw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 100\r\n\r\npartial")
+		utilruntime.Must(err)
+		utilruntime.Must(bufrw.Flush())
+	}))
+	defer server.Close()
+
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	t.Run("strict", func(t *testing.T) {
+		prober := NewGetWithOptions(nil, false, Options{})
+		result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+		assert.Error(t, err)
+		assert.Equal(t, api.Failure, result)
+		assert.Empty(t, output)
+	})
+	t.Run("tolerant", func(t *testing.T) {
+		prober := NewGetWithOptions(nil, false, Options{TolerateBodyReadError: true})
+		result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+		assert.NoError(t, err)
+		assert.Equal(t, api.Warning, result)
+		assert.Contains(t, output, "partial")
+		assert.Contains(t, output, "body read error: unexpected EOF")
+	})
+}
+
+// startSOCKS5Server starts a minimal SOCKS5 proxy supporting CONNECT with optional username/password auth.
+// It returns the proxy address and a counter of proxied connections.
+func startSOCKS5Server(t *testing.T, user, password string) (string, *int32) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	var proxied int32
+	handle := func(c net.Conn) error {
+		defer c.Close()
+		buf := make([]byte, 262)
+		if _, err := io.ReadFull(c, buf[:2]); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(c, buf[:buf[1]]); err != nil {
+			return err
+		}
+		if user == "" {
+			_, err := c.Write([]byte{5, 0})
+			if err != nil {
+				return err
+			}
+		} else {
+			if _, err := c.Write([]byte{5, 2}); err != nil {
+				return err
+			}
+			if _, err := io.ReadFull(c, buf[:2]); err != nil {
+				return err
+			}
+			u := make([]byte, buf[1])
+			if _, err := io.ReadFull(c, u); err != nil {
+				return err
+			}
+			if _, err := io.ReadFull(c, buf[:1]); err != nil {
+				return err
+			}
+			p := make([]byte, buf[0])
+			if _, err := io.ReadFull(c, p); err != nil {
+				return err
+			}
+			if string(u) != user || string(p) != password {
+				_, err := c.Write([]byte{1, 1})
+				return err
+			}
+			if _, err := c.Write([]byte{1, 0}); err != nil {
+				return err
+			}
+		}
+		// request: VER CMD RSV ATYP
+		if _, err := io.ReadFull(c, buf[:4]); err != nil {
+			return err
+		}
+		var host string
+		switch buf[3] {
+		case 1:
+			if _, err := io.ReadFull(c, buf[:4]); err != nil {
+				return err
+			}
+			host = net.IP(buf[:4]).String()
+		case 3:
+			if _, err := io.ReadFull(c, buf[:1]); err != nil {
+				return err
+			}
+			n := buf[0]
+			if _, err := io.ReadFull(c, buf[:n]); err != nil {
+				return err
+			}
+			host = string(buf[:n])
+		default:
+			return fmt.Errorf("unsupported address type %d", buf[3])
+		}
+		if _, err := io.ReadFull(c, buf[:2]); err != nil {
+			return err
+		}
+		port := int(buf[0])<<8 | int(buf[1])
+		upstream, err := net.Dial("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+		if err != nil {
+			_, err = c.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+			return err
+		}
+		defer upstream.Close()
+		atomic.AddInt32(&proxied, 1)
+		if _, err := c.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}); err != nil {
+			return err
+		}
+		go func() {
+			_, _ = io.Copy(upstream, c)
+		}()
+		_, err = io.Copy(c, upstream)
+		return err
+	}
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() { _ = handle(c) }()
+		}
+	}()
+	return l.Addr().String(), &proxied
+}
+
+func TestHTTPProbeChecker_SOCKS5(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte("ok"))
+		utilruntime.Must(err)
+	}))
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	t.Run("no auth", func(t *testing.T) {
+		addr, proxied := startSOCKS5Server(t, "", "")
+		prober := NewGetWithOptions(nil, false, Options{SOCKS5: &api.SOCKS5Proxy{Address: addr}})
+		result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+		assert.NoError(t, err)
+		assert.Equal(t, api.Success, result)
+		assert.Equal(t, "ok", output)
+		assert.Equal(t, int32(1), atomic.LoadInt32(proxied))
+	})
+	t.Run("with credentials", func(t *testing.T) {
+		addr, proxied := startSOCKS5Server(t, "user", "pass")
+		prober := NewPostWithOptions(nil, false, Options{SOCKS5: &api.SOCKS5Proxy{Address: addr, Username: "user", Password: "pass"}})
+		result, _, err := prober.Probe(target, nil, nil, "", wait.ForeverTestTimeout)
+		assert.NoError(t, err)
+		assert.Equal(t, api.Success, result)
+		assert.Equal(t, int32(1), atomic.LoadInt32(proxied))
+	})
+	t.Run("wrong credentials", func(t *testing.T) {
+		addr, _ := startSOCKS5Server(t, "user", "pass")
+		proxy := &api.SOCKS5Proxy{Address: addr, Username: "user", Password: "wrong"}
+		prober := NewGetWithOptions(nil, false, Options{SOCKS5: proxy})
+		result, _, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+		assert.NoError(t, err)
+		assert.Equal(t, api.Failure, result)
+		assert.NotContains(t, proxy.String(), "wrong")
+	})
+}
+
+func TestHTTPProbeChecker_Proxy(t *testing.T) {
+	var proxied atomic.Int32
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Add(1)
+		if r.URL.Host != "backend.example.com" {
+			http.Error(w, "unexpected target "+r.URL.String(), http.StatusBadGateway)
+			return
+		}
+		if r.Header.Get("Proxy-Authorization") != "Basic "+base64.StdEncoding.EncodeToString([]byte("user:secret")) {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxyServer.Close()
+	proxyURL, err := url.Parse(proxyServer.URL)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		proxy  *api.HTTPProxy
+		result api.Result
+		output string
+	}{
+		"credentials": {&api.HTTPProxy{URL: proxyURL, Username: "user", Password: "secret"}, api.Success, ""},
+		"wrong credentials": {
+			&api.HTTPProxy{URL: proxyURL, Username: "user", Password: "wrong"}, api.Failure,
+			"HTTP probe failed with statuscode: 407",
+		},
+		"no credentials": {&api.HTTPProxy{URL: proxyURL}, api.Failure, "HTTP probe failed with statuscode: 407"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			proxied.Store(0)
+			assert.NotContains(t, tt.proxy.String(), "secret")
+			prober := NewGetWithOptions(nil, false, Options{Proxy: tt.proxy})
+			target, err := url.Parse("http://backend.example.com/healthz")
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+			assert.Equal(t, int32(1), proxied.Load())
+		})
+	}
+}
+
+func TestHTTPProbeChecker_UseEnvironmentProxy(t *testing.T) {
+	var proxied atomic.Int32
+	proxyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Add(1)
+		_, _ = fmt.Fprintf(w, "proxied %s", r.URL.Host)
+	}))
+	defer proxyServer.Close()
+	t.Setenv("HTTP_PROXY", proxyServer.URL)
+	t.Setenv("NO_PROXY", "direct.example.com")
+
+	testCases := map[string]struct {
+		opts    Options
+		target  string
+		proxied int32
+	}{
+		"enabled":  {Options{UseEnvironmentProxy: true}, "http://backend.example.com/healthz", 1},
+		"no proxy": {Options{UseEnvironmentProxy: true}, "http://direct.example.com/healthz", 0},
+		"disabled": {Options{}, "http://backend.example.com/healthz", 0},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			proxied.Store(0)
+			prober := NewGetWithOptions(nil, false, tt.opts)
+			target, err := url.Parse(tt.target)
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.proxied, proxied.Load())
+			if tt.proxied > 0 {
+				assert.Equal(t, api.Success, result)
+				assert.Equal(t, "proxied backend.example.com", output)
+			} else {
+				assert.Equal(t, api.Failure, result)
+			}
+		})
+	}
+}
+
+func TestHTTPProbeChecker_DialAddress(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := fmt.Fprintf(w, "sni=%s host=%s", r.TLS.ServerName, r.Host)
+		utilruntime.Must(err)
+	}))
+	defer server.Close()
+
+	prober := NewGetWithOptions(&tls.Config{InsecureSkipVerify: true}, false, Options{DialAddress: server.Listener.Addr().String()})
+	target, err := url.Parse("https://replica.example.com/healthz")
+	require.NoError(t, err)
+	result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, api.Success, result)
+	assert.Equal(t, "sni=replica.example.com host=replica.example.com", output)
+}
+
+func TestHTTPProbeChecker_UnixSocket(t *testing.T) {
+	dir, err := os.MkdirTemp("", "probe")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "app.sock")
+	ln, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Daemon", r.Host)
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	}))
+	server.Listener = ln
+	server.Start()
+	defer server.Close()
+
+	testCases := map[string]struct {
+		path   string
+		opts   Options
+		result api.Result
+		output string
+	}{
+		"healthy":          {"/healthz", Options{}, api.Success, `{"status":"ok"}`},
+		"unhealthy":        {"/fail", Options{}, api.Failure, "HTTP probe failed with statuscode: 500"},
+		"body assertion":   {"/healthz", Options{ExpectJSON: []JSONAssertion{{Path: "{.status}", Value: "ok"}}}, api.Success, ""},
+		"body mismatch":    {"/healthz", Options{ExpectJSON: []JSONAssertion{{Path: "{.status}", Value: "down"}}}, api.Failure, "JSON assertions failed"},
+		"header assertion": {"/healthz", Options{ForbidResponseHeaders: []string{"X-Daemon"}}, api.Failure, "X-Daemon"},
+		"missing socket":   {"/healthz", Options{UnixSocket: filepath.Join(dir, "missing.sock")}, api.Failure, "missing.sock"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if tt.opts.UnixSocket == "" {
+				tt.opts.UnixSocket = socket
+			}
+			prober := NewGetWithOptions(nil, false, tt.opts)
+			target, err := url.Parse("http://app.local" + tt.path)
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_ExpectServerContains(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/proxied" {
+			w.Header().Set("Server", "Envoy/1.29.1")
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		path   string
+		expect string
+		result api.Result
+		output string
+	}{
+		"disabled":      {"/", "", api.Success, ""},
+		"contains":      {"/proxied", "envoy", api.Success, ""},
+		"other server":  {"/proxied", "nginx", api.Failure, `expected the Server header to contain "nginx", got "Envoy/1.29.1"`},
+		"missing":       {"/", "envoy", api.Failure, `expected the Server header to contain "envoy", got ""`},
+		"exact version": {"/proxied", "Envoy/1.29.1", api.Success, ""},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, Options{ExpectServerContains: tt.expect})
+			target, err := url.Parse(server.URL + tt.path)
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_ExpectProto(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		proto  string
+		result api.Result
+		output string
+	}{
+		"disabled": {"", api.Success, ""},
+		"match":    {"HTTP/1.1", api.Success, ""},
+		"mismatch": {"HTTP/2.0", api.Failure, "expected protocol HTTP/2.0, got HTTP/1.1"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, Options{ExpectProto: tt.proto})
+			target, err := url.Parse(server.URL)
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_ForbidResponseHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Debug", "true")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		forbidden []string
+		result    api.Result
+		output    string
+	}{
+		"disabled":      {nil, api.Success, ""},
+		"absent":        {[]string{"X-Powered-By"}, api.Success, ""},
+		"present":       {[]string{"X-Powered-By", "X-Debug"}, api.Failure, `forbidden response header X-Debug is present: ["true"]`},
+		"non canonical": {[]string{"x-debug"}, api.Failure, "forbidden response header X-Debug is present"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, Options{ForbidResponseHeaders: tt.forbidden})
+			target, err := url.Parse(server.URL)
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_AcceptInformationalCodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("failed to hijack connection: %v", err)
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n\r\n")
+		_ = rw.Flush()
+		// Keep the upgraded connection open, so a probe reading the body would block.
+		_, _ = rw.ReadByte()
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		codes  []int
+		result api.Result
+		reason api.FailureReason
+		output string
+	}{
+		"not accepted": {nil, api.Failure, api.ReasonUnexpectedStatus, "HTTP probe failed with statuscode: 101"},
+		"accepted":     {[]int{http.StatusSwitchingProtocols}, api.Success, "", ""},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, Options{AcceptInformationalCodes: tt.codes}).(DetailedGetProber)
+			target, err := url.Parse(server.URL)
+			require.NoError(t, err)
+			d, err := prober.ProbeDetailed(target, http.Header{"Connection": {"Upgrade"}, "Upgrade": {"websocket"}}, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, d.Result)
+			assert.Equal(t, tt.reason, d.Reason)
+			assert.Equal(t, http.StatusSwitchingProtocols, d.StatusCode)
+			assert.Equal(t, tt.output, d.Output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_MinOpenDuration(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hold, err := time.ParseDuration(r.URL.Query().Get("hold"))
+		utilruntime.Must(err)
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("failed to hijack connection: %v", err)
+			return
+		}
+		defer conn.Close()
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: websocket\r\n\r\n")
+		_ = rw.Flush()
+		// a frame that the probe discards while it keeps the connection open
+		_, _ = conn.Write([]byte{0x89, 0x00})
+		time.Sleep(hold)
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		hold    string
+		timeout time.Duration
+		result  api.Result
+		reason  api.FailureReason
+		output  string
+	}{
+		"stays open":   {"1s", wait.ForeverTestTimeout, api.Success, "", "connection stayed open for 2"},
+		"closed early": {"50ms", wait.ForeverTestTimeout, api.Failure, api.ReasonAssertionFailed, "expected to stay open for at least 200ms"},
+		"timed out":    {"1s", 100 * time.Millisecond, api.Failure, api.ReasonConnectionFailed, "probe timed out after the connection stayed open for"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, Options{
+				AcceptInformationalCodes: []int{http.StatusSwitchingProtocols},
+				MinOpenDuration:          200 * time.Millisecond,
+			}).(DetailedGetProber)
+			target, err := url.Parse(server.URL + "?hold=" + tt.hold)
+			require.NoError(t, err)
+			d, err := prober.ProbeDetailed(target, http.Header{"Connection": {"Upgrade"}, "Upgrade": {"websocket"}}, tt.timeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, d.Result)
+			assert.Equal(t, tt.reason, d.Reason)
+			assert.Contains(t, d.Output, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_Trace(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	// Use a hostname so that the probe goes through name resolution.
+	target := &url.URL{Scheme: "https", Host: "localhost:" + serverURL.Port()}
+	config := &tls.Config{InsecureSkipVerify: true}
+
+	prober := NewGetWithOptions(config, false, Options{}).(DetailedGetProber)
+	d, err := prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, api.Success, d.Result)
+	assert.Nil(t, d.Timings)
+
+	prober = NewGetWithOptions(config, false, Options{Trace: true}).(DetailedGetProber)
+	d, err = prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, api.Success, d.Result)
+	require.NotNil(t, d.Timings)
+	assert.Positive(t, d.Timings.DNS)
+	assert.Positive(t, d.Timings.Connect)
+	assert.Positive(t, d.Timings.TLSHandshake)
+	assert.GreaterOrEqual(t, d.Timings.TimeToFirstByte, d.Timings.TLSHandshake)
+}
+
+func TestHTTPProbeChecker_ReportTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	config := &tls.Config{InsecureSkipVerify: true, MaxVersion: tls.VersionTLS12}
+
+	prober := NewGetWithOptions(config, false, Options{}).(DetailedGetProber)
+	d, err := prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, api.Success, d.Result)
+	assert.Nil(t, d.TLS)
+
+	prober = NewGetWithOptions(config, false, Options{ReportTLS: true}).(DetailedGetProber)
+	d, err = prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, api.Success, d.Result)
+	require.NotNil(t, d.TLS)
+	leaf := server.Certificate()
+	assert.Equal(t, "TLS 1.2", d.TLS.Version)
+	assert.NotEmpty(t, d.TLS.CipherSuite)
+	assert.Equal(t, leaf.Subject.String(), d.TLS.PeerSubject)
+	assert.True(t, leaf.NotAfter.Equal(d.TLS.PeerNotAfter))
+
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer plain.Close()
+	target, err = url.Parse(plain.URL)
+	require.NoError(t, err)
+	d, err = prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, api.Success, d.Result)
+	assert.Nil(t, d.TLS)
+}
+
+func TestHTTPProbeChecker_TLSVerificationFailure(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		url    string
+		config *tls.Config
+		result api.Result
+		reason api.FailureReason
+		output string
+	}{
+		"verification disabled": {server.URL, &tls.Config{InsecureSkipVerify: true}, api.Success, "", ""},
+		"unknown authority": {
+			server.URL, &tls.Config{}, api.Failure, api.ReasonTLSHandshakeFailed,
+			"TLS certificate verification failed: x509: certificate signed by unknown authority",
+		},
+		"connection refused": {
+			"https://127.0.0.1:1", &tls.Config{}, api.Failure, api.ReasonConnectionFailed, "connection refused",
+		},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithTLSConfig(tt.config, false).(DetailedGetProber)
+			target, err := url.Parse(tt.url)
+			require.NoError(t, err)
+			d, err := prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, d.Result)
+			assert.Equal(t, tt.reason, d.Reason)
+			assert.Contains(t, d.Output, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_VerifyPeerCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	requireOrg := func(org string) func([][]byte, [][]*x509.Certificate) error {
+		return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			cert, err := x509.ParseCertificate(rawCerts[0])
+			if err != nil {
+				return err
+			}
+			for _, o := range cert.Subject.Organization {
+				if o == org {
+					return nil
+				}
+			}
+			return fmt.Errorf("certificate is not issued to %s", org)
+		}
+	}
+
+	testCases := map[string]struct {
+		config *tls.Config
+		verify func([][]byte, [][]*x509.Certificate) error
+		result api.Result
+		reason api.FailureReason
+		output string
+	}{
+		"accepted":             {&tls.Config{RootCAs: roots}, requireOrg("Acme Co"), api.Success, "", ""},
+		"accepted insecure":    {&tls.Config{InsecureSkipVerify: true}, requireOrg("Acme Co"), api.Success, "", ""},
+		"rejected":             {&tls.Config{InsecureSkipVerify: true}, requireOrg("Example Inc"), api.Failure, api.ReasonTLSHandshakeFailed, "TLS peer certificate verification failed: certificate is not issued to Example Inc"},
+		"standard check first": {&tls.Config{}, requireOrg("Acme Co"), api.Failure, api.ReasonTLSHandshakeFailed, "x509: certificate signed by unknown authority"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(tt.config, false, Options{VerifyPeerCertificate: tt.verify}).(DetailedGetProber)
+			target, err := url.Parse(server.URL)
+			require.NoError(t, err)
+			d, err := prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, d.Result)
+			assert.Equal(t, tt.reason, d.Reason)
+			assert.Contains(t, d.Output, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_MaxOutputLength(t *testing.T) {
+	body := `{"status": "ok", "message": "héllo wörld"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		max    int
+		output string
+	}{
+		"disabled":    {0, body},
+		"larger":      {len(body) + 1, body},
+		"truncated":   {10, `{"status":`},
+		"rune border": {len(`{"status": "ok", "message": "h`) + 1, `{"status": "ok", "message": "h`},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// the assertion sees the whole body
+			prober := NewGetWithOptions(nil, false, Options{MaxOutputLength: tt.max, ExpectJSONPath: "$.message", ExpectJSONValue: "héllo wörld"})
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, api.Success, result)
+			assert.Equal(t, tt.output, output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_ExpectJSONValue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ContentType, ContentJson)
+		_, _ = w.Write([]byte(`{"version": "v1.2.3", "generation": 4}`))
+	}))
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	pod := &core.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web-0",
+			Generation:  4,
+			Annotations: map[string]string{"deployed-version": "v1.2.3"},
+		},
+	}
+
+	testCases := map[string]struct {
+		path   string
+		value  string
+		pod    *core.Pod
+		result api.Result
+		output string
+	}{
+		"disabled":          {"", "", nil, api.Success, `"version"`},
+		"literal":           {"$.version", "v1.2.3", nil, api.Success, `"version"`},
+		"braces":            {"{.generation}", "4", nil, api.Success, `"version"`},
+		"literal mismatch":  {"$.version", "v1.2.4", nil, api.Failure, `JSONPath $.version is "v1.2.3", expected "v1.2.4"`},
+		"annotation":        {"$.version", `{{index .Annotations "deployed-version"}}`, pod, api.Success, `"version"`},
+		"generation":        {"$.generation", "{{.Generation}}", pod, api.Success, `"version"`},
+		"template mismatch": {"$.generation", "{{.Generation}}", &core.Pod{}, api.Failure, `JSONPath $.generation is "4", expected "0"`},
+		"template no pod":   {"$.version", `{{index .Annotations "deployed-version"}}`, nil, api.Failure, "the probe has no pod"},
+		"missing field":     {"$.status", "ok", nil, api.Failure, "status is not found"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetTargetProber(NewGetWithOptions(nil, false, Options{ExpectJSONPath: tt.path, ExpectJSONValue: tt.value}))
+			result, output, err := prober.Probe(context.TODO(), api.Target{URL: target, Pod: tt.pod, Timeout: wait.ForeverTestTimeout})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_ExpectResponseHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Served-By", "web-0.cluster-a")
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	pod := &core.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-0"}}
+
+	testCases := map[string]struct {
+		assertion HeaderAssertion
+		pod       *core.Pod
+		result    api.Result
+		output    string
+	}{
+		"literal":         {HeaderAssertion{Name: "x-served-by", Value: "web-0.cluster-a"}, nil, api.Success, "ok"},
+		"template match":  {HeaderAssertion{Name: "X-Served-By", Value: `^{{.Name}}\.`, Match: true}, pod, api.Success, "ok"},
+		"template equal":  {HeaderAssertion{Name: "X-Served-By", Value: "{{.Name}}"}, pod, api.Failure, `response header X-Served-By is "web-0.cluster-a", expected "web-0"`},
+		"other backend":   {HeaderAssertion{Name: "X-Served-By", Value: `^{{.Name}}\.`, Match: true}, &core.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1"}}, api.Failure, `response header X-Served-By is "web-0.cluster-a", expected to match "^web-1\\."`},
+		"missing":         {HeaderAssertion{Name: "X-Backend", Value: "web-0"}, nil, api.Failure, `response header X-Backend is missing, expected "web-0"`},
+		"invalid pattern": {HeaderAssertion{Name: "X-Served-By", Value: "(", Match: true}, nil, api.Failure, "invalid pattern for response header X-Served-By"},
+		"template no pod": {HeaderAssertion{Name: "X-Served-By", Value: "{{.Name}}"}, nil, api.Failure, "the probe has no pod"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetTargetProber(NewGetWithOptions(nil, false, Options{ExpectResponseHeaders: []HeaderAssertion{tt.assertion}}))
+			result, output, err := prober.Probe(context.TODO(), api.Target{URL: target, Pod: tt.pod, Timeout: wait.ForeverTestTimeout})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+		})
+	}
+}
+
+func TestHTTPTargetProbers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = fmt.Fprintf(w, "%s %s", r.Method, body)
+	}))
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	probers := map[string]struct {
+		prober api.Prober
+		output string
+	}{
+		"get":  {NewGetTargetProber(NewHttpGet(false)), "GET "},
+		"post": {NewPostTargetProber(NewHttpPost(false)), "POST ping"},
+	}
+	for name, tt := range probers {
+		t.Run(name, func(t *testing.T) {
+			result, output, err := tt.prober.Probe(context.Background(), api.Target{URL: target, Body: "ping", Timeout: wait.ForeverTestTimeout})
+			assert.NoError(t, err)
+			assert.Equal(t, api.Success, result)
+			assert.Equal(t, tt.output, output)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			result, _, err = tt.prober.Probe(ctx, api.Target{URL: target, Body: "ping", Timeout: wait.ForeverTestTimeout})
+			assert.Error(t, err)
+			assert.Equal(t, api.Unknown, result)
+
+			ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+			defer cancel()
+			result, _, err = tt.prober.Probe(ctx, api.Target{URL: target, Body: "ping", Timeout: wait.ForeverTestTimeout})
+			assert.NoError(t, err)
+			assert.Equal(t, api.Success, result, "the deadline of the context shortens the timeout")
+		})
+	}
+}
+
+type fakeAuthenticator struct {
+	token string
+	err   error
+}
+
+func (a fakeAuthenticator) Authenticate(req *http.Request) error {
+	if a.err != nil {
+		return a.err
+	}
+	req.Header.Set("Authorization", "Negotiate "+a.token)
+	return nil
+}
+
+func TestHTTPProbeChecker_Authenticator(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Authorization") {
+		case "Negotiate valid":
+			if r.URL.Path == "/unhealthy" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.Header().Set("WWW-Authenticate", "Negotiate")
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		auth   Authenticator
+		path   string
+		result api.Result
+		reason api.FailureReason
+		output string
+	}{
+		"authenticated":         {fakeAuthenticator{token: "valid"}, "/", api.Success, "", ""},
+		"unhealthy":             {fakeAuthenticator{token: "valid"}, "/unhealthy", api.Failure, api.ReasonUnexpectedStatus, "statuscode: 500"},
+		"rejected":              {fakeAuthenticator{token: "expired"}, "/", api.Failure, api.ReasonAuthenticationFailed, "statuscode: 401"},
+		"authentication failed": {fakeAuthenticator{err: fmt.Errorf("could not get valid TGT")}, "/", api.Failure, api.ReasonAuthenticationFailed, "authentication failed: could not get valid TGT"},
+		"no authenticator":      {nil, "/", api.Failure, api.ReasonUnexpectedStatus, "statuscode: 401"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, Options{Authenticator: tt.auth}).(DetailedGetProber)
+			target, err := url.Parse(server.URL + tt.path)
+			require.NoError(t, err)
+			headers := http.Header{"User-Agent": {"test"}}
+			d, err := prober.ProbeDetailed(target, headers, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, d.Result)
+			assert.Equal(t, tt.reason, d.Reason)
+			assert.Contains(t, d.Output, tt.output)
+			assert.Empty(t, headers.Get("Authorization"))
+		})
+	}
+}
+
+func TestHTTPProbeChecker_Recorder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("X-Served-By", "replica-1")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(strings.Repeat("x", 2000)))
+	}))
+	defer server.Close()
+
+	var transcript bytes.Buffer
+	prober := NewGetWithOptions(nil, false, Options{Recorder: &transcript})
+	target, err := url.Parse(server.URL + "/healthz?verbose=1")
+	require.NoError(t, err)
+	headers := http.Header{"Authorization": {"Bearer secret"}, "X-Probe": {"1"}}
+	result, _, err := prober.Probe(target, headers, wait.ForeverTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, api.Failure, result)
+
+	out := transcript.String()
+	assert.Contains(t, out, "> GET /healthz?verbose=1 HTTP/1.1\n> Host: "+target.Host+"\n")
+	assert.Contains(t, out, "> Authorization: <redacted>\n")
+	assert.Contains(t, out, "> X-Probe: 1\n")
+	assert.Contains(t, out, "< HTTP/1.1 503 Service Unavailable\n")
+	assert.Contains(t, out, "< Set-Cookie: <redacted>\n")
+	assert.Contains(t, out, "< X-Served-By: replica-1\n")
+	assert.Contains(t, out, "[body truncated, 1024 of 2000 bytes recorded]")
+	assert.NotContains(t, out, "secret")
+
+	transcript.Reset()
+	target, err = url.Parse("http://127.0.0.1:1/")
+	require.NoError(t, err)
+	_, _, _ = prober.Probe(target, nil, wait.ForeverTestTimeout)
+	assert.Contains(t, transcript.String(), "! Get \"http://127.0.0.1:1/\"")
+}
+
+func TestHTTPProbeChecker_ClientCertificate(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequestClientCert}
+	server.StartTLS()
+	defer server.Close()
+	// The test certificate of the server doubles as the client certificate.
+	clientCert := server.TLS.Certificates[0]
+
+	testCases := map[string]struct {
+		cert   func() (*tls.Certificate, error)
+		result api.Result
+		err    string
+	}{
+		"no client certificate": {nil, api.Failure, ""},
+		"client certificate":    {func() (*tls.Certificate, error) { return &clientCert, nil }, api.Success, ""},
+		"missing certificate": {
+			func() (*tls.Certificate, error) { return nil, fmt.Errorf("secret demo/client-cert not found") },
+			api.Unknown, "secret demo/client-cert not found",
+		},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(&tls.Config{InsecureSkipVerify: true}, false, Options{ClientCertificate: tt.cert})
+			target, err := url.Parse(server.URL)
+			require.NoError(t, err)
+			result, _, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.Equal(t, tt.result, result)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHTTPProbeChecker_ClientCertificates(t *testing.T) {
+	caA, keyA := newTestCA(t, "ca-a")
+	caB, keyB := newTestCA(t, "ca-b")
+	certA := newClientCertificate(t, caA, keyA, "client-a")
+	certB := newClientCertificate(t, caB, keyB, "client-b")
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(caB)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintf(w, "client=%s", r.TLS.PeerCertificates[0].Subject.CommonName)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.StartTLS()
+	defer server.Close()
+
+	testCases := map[string]struct {
+		certs  []tls.Certificate
+		result api.Result
+		output string
+	}{
+		"accepted one selected":  {[]tls.Certificate{certA, certB}, api.Success, "client=client-b"},
+		"order does not matter":  {[]tls.Certificate{certB, certA}, api.Success, "client=client-b"},
+		"none accepted, no cert": {[]tls.Certificate{certA}, api.Failure, "certificate required"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(&tls.Config{InsecureSkipVerify: true}, false, Options{ClientCertificates: tt.certs})
+			target, err := url.Parse(server.URL)
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_ExpectGzip(t *testing.T) {
+	body := strings.Repeat(`{"status":"ok"}`, 50)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload := body
+		if r.URL.Path == "/tiny" {
+			payload = "ok"
+		}
+		if r.URL.Path == "/plain" || !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			_, _ = w.Write([]byte(payload))
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		_, _ = gz.Write([]byte(payload))
+		_ = gz.Close()
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		path    string
+		opts    Options
+		result  api.Result
+		output  string
+		headers http.Header
+	}{
+		"disabled":        {"/", Options{}, api.Success, body, nil},
+		"gzip":            {"/", Options{ExpectGzip: true}, api.Success, body, nil},
+		"smaller":         {"/", Options{ExpectGzipSmaller: true}, api.Success, body, nil},
+		"not compressed":  {"/plain", Options{ExpectGzip: true}, api.Failure, `expected a gzip encoded response, got Content-Encoding ""`, nil},
+		"not smaller":     {"/tiny", Options{ExpectGzipSmaller: true}, api.Failure, "gzip encoding did not reduce the size", nil},
+		"custom encoding": {"/", Options{ExpectGzip: true}, api.Failure, "expected a gzip encoded response", http.Header{"Accept-Encoding": {"br"}}},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, tt.opts)
+			target, err := url.Parse(server.URL + tt.path)
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, tt.headers, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_AcceptEncoding(t *testing.T) {
+	body := strings.Repeat(`{"status":"ok"}`, 50)
+	var encoded bytes.Buffer
+	gz := gzip.NewWriter(&encoded)
+	_, _ = gz.Write([]byte(body))
+	_ = gz.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			_, _ = w.Write([]byte(body))
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write(encoded.Bytes())
+	}))
+	defer server.Close()
+
+	sum := func(b []byte) string {
+		h := sha256.Sum256(b)
+		return hex.EncodeToString(h[:])
+	}
+	testCases := map[string]struct {
+		opts   Options
+		result api.Result
+		output string
+	}{
+		"transparent":          {Options{}, api.Success, body},
+		"decoded":              {Options{AcceptEncoding: "gzip", ExpectBodySHA256: sum([]byte(body))}, api.Success, body},
+		"identity":             {Options{AcceptEncoding: "identity", ExpectGzip: true}, api.Failure, "expected a gzip encoded response"},
+		"raw without encoding": {Options{RawBody: true}, api.Success, body},
+		"raw":                  {Options{AcceptEncoding: "gzip", RawBody: true, ExpectBodySHA256: sum(encoded.Bytes())}, api.Success, encoded.String()},
+		"raw decoded hash":     {Options{AcceptEncoding: "gzip", RawBody: true, ExpectBodySHA256: sum([]byte(body))}, api.Failure, "body SHA-256"},
+		"raw smaller":          {Options{RawBody: true, ExpectGzipSmaller: true}, api.Success, encoded.String()},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, tt.opts)
+			target, err := url.Parse(server.URL)
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_ExpectContentLengthMatch(t *testing.T) {
+	large := strings.Repeat("x", maxRespBodyLength+100)
+	var encoded bytes.Buffer
+	gz := gzip.NewWriter(&encoded)
+	_, _ = gz.Write([]byte(strings.Repeat("ok", 100)))
+	_ = gz.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/short":
+			conn, _, err := w.(http.Hijacker).Hijack()
+			utilruntime.Must(err)
+			_, _ = conn.Write([]byte("HTTP/1.1 200 OK\r\nContent-Length: 100\r\n\r\npartial"))
+			_ = conn.Close()
+		case "/large":
+			w.Header().Set("Content-Length", strconv.Itoa(len(large)))
+			_, _ = w.Write([]byte(large))
+		case "/gzip":
+			w.Header().Set("Content-Encoding", "gzip")
+			w.Header().Set("Content-Length", strconv.Itoa(encoded.Len()))
+			_, _ = w.Write(encoded.Bytes())
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		path   string
+		opts   Options
+		result api.Result
+		output string
+	}{
+		"match":            {"/", Options{ExpectContentLengthMatch: true}, api.Success, "ok"},
+		"short body":       {"/short", Options{ExpectContentLengthMatch: true}, api.Failure, "Content-Length mismatch: declared 100 bytes, received 7 bytes"},
+		"tolerated":        {"/short", Options{ExpectContentLengthMatch: true, TolerateBodyReadError: true}, api.Failure, "Content-Length mismatch: declared 100 bytes, received 7 bytes"},
+		"output limit":     {"/large", Options{ExpectContentLengthMatch: true}, api.Success, ""},
+		"full body hashed": {"/large", Options{ExpectContentLengthMatch: true, ExpectBodySHA256: fmt.Sprintf("%x", sha256.Sum256([]byte(large)))}, api.Success, ""},
+		"gzip encoded":     {"/gzip", Options{ExpectContentLengthMatch: true, ExpectGzip: true}, api.Success, "okok"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, tt.opts)
+			target, err := url.Parse(server.URL + tt.path)
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_Retry(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		switch r.URL.Path {
+		case "/unauthorized":
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/flapping":
+			if n < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		default:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		path   string
+		retry  *RetryPolicy
+		result api.Result
+		calls  int32
+	}{
+		"disabled":          {"/flapping", nil, api.Failure, 1},
+		"retry all":         {"/flapping", &RetryPolicy{Attempts: 3}, api.Success, 3},
+		"out of attempts":   {"/down", &RetryPolicy{Attempts: 3}, api.Failure, 3},
+		"retryable reason":  {"/flapping", &RetryPolicy{Attempts: 3, RetryOn: []api.FailureReason{api.ReasonUnexpectedStatus}}, api.Success, 3},
+		"non retryable":     {"/unauthorized", &RetryPolicy{Attempts: 3, RetryOn: []api.FailureReason{api.ReasonConnectionFailed}}, api.Failure, 1},
+		"single attempt":    {"/flapping", &RetryPolicy{Attempts: 1}, api.Failure, 1},
+		"retry with pauses": {"/flapping", &RetryPolicy{Attempts: 5, Interval: 10 * time.Millisecond}, api.Success, 3},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			calls.Store(0)
+			prober := NewGetWithOptions(nil, false, Options{Retry: tt.retry})
+			target, err := url.Parse(server.URL + tt.path)
+			require.NoError(t, err)
+			result, _, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Equal(t, tt.calls, calls.Load())
+		})
+	}
+}
+
+func TestHTTPProbeChecker_RetryTimeout(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if r.URL.Path == "/throttled" {
+			w.Header().Set("Retry-After", "30")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		path  string
+		retry *RetryPolicy
+		calls int32
+	}{
+		"pauses":      {"/down", &RetryPolicy{Attempts: 10, Interval: 250 * time.Millisecond}, 4},
+		"retry after": {"/throttled", &RetryPolicy{Attempts: 3}, 1},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			calls.Store(0)
+			prober := NewGetWithOptions(nil, false, Options{Retry: tt.retry})
+			target, err := url.Parse(server.URL + tt.path)
+			require.NoError(t, err)
+			start := time.Now()
+			result, _, err := prober.Probe(target, nil, time.Second)
+			assert.NoError(t, err)
+			assert.Equal(t, api.Failure, result)
+			assert.Less(t, time.Since(start), time.Second+100*time.Millisecond, "the retries exceed the probe timeout")
+			assert.Equal(t, tt.calls, calls.Load())
+		})
+	}
+}
+
+func TestHTTPProbeChecker_Samples(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		switch r.URL.Path {
+		case "/outlier":
+			if n == 5 {
+				time.Sleep(200 * time.Millisecond)
+			}
+		case "/slow":
+			time.Sleep(100 * time.Millisecond)
+		case "/down":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		path    string
+		opts    Options
+		timeout time.Duration
+		result  api.Result
+		output  string
+		calls   int32
+	}{
+		"fast":          {"/", Options{Samples: 5, MaxLatency: time.Second}, wait.ForeverTestTimeout, api.Success, "ok\n[p95 latency", 5},
+		"p95 outlier":   {"/outlier", Options{Samples: 10, MaxLatency: 100 * time.Millisecond}, wait.ForeverTestTimeout, api.Failure, "p95 latency", 10},
+		"p50 outlier":   {"/outlier", Options{Samples: 10, LatencyPercentile: 50, MaxLatency: 100 * time.Millisecond}, wait.ForeverTestTimeout, api.Success, "[p50 latency", 10},
+		"single sample": {"/slow", Options{MaxLatency: 50 * time.Millisecond}, wait.ForeverTestTimeout, api.Failure, "p95 latency", 1},
+		"failing":       {"/down", Options{Samples: 5}, wait.ForeverTestTimeout, api.Failure, "statuscode: 503", 1},
+		"budget":        {"/slow", Options{Samples: 10}, 250 * time.Millisecond, api.Failure, "", 0},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			calls.Store(0)
+			prober := NewGetWithOptions(nil, false, tt.opts)
+			target, err := url.Parse(server.URL + tt.path)
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, nil, tt.timeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+			if tt.calls == 0 {
+				// the budget runs out before all samples are sent
+				assert.Less(t, int(calls.Load()), tt.opts.Samples)
+			} else {
+				assert.Equal(t, tt.calls, calls.Load())
+			}
+		})
+	}
+}
+
+func TestHTTPProbeChecker_WarmupRequests(t *testing.T) {
+	var calls, conns atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		switch {
+		case r.URL.Path == "/cold" && n == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		case r.URL.Path == "/slow":
+			time.Sleep(100 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	testCases := map[string]struct {
+		path    string
+		opts    Options
+		timeout time.Duration
+		result  api.Result
+		output  string
+		calls   int32
+		conns   int32
+	}{
+		"measured":        {"/", Options{WarmupRequests: 2}, wait.ForeverTestTimeout, api.Success, "ok\n[latency ", 3, 1},
+		"failing warmup":  {"/cold", Options{WarmupRequests: 1}, wait.ForeverTestTimeout, api.Success, "ok", 2, 1},
+		"with samples":    {"/", Options{WarmupRequests: 1, Samples: 3}, wait.ForeverTestTimeout, api.Success, "[p95 latency", 4, 1},
+		"closed":          {"/", Options{WarmupRequests: 2, CloseConnection: true}, wait.ForeverTestTimeout, api.Success, "ok", 3, 3},
+		"out of time":     {"/slow", Options{WarmupRequests: 5}, 250 * time.Millisecond, api.Failure, "warmup ran out of time after", 0, 1},
+		"without warmups": {"/", Options{}, wait.ForeverTestTimeout, api.Success, "ok", 1, 1},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			calls.Store(0)
+			conns.Store(0)
+			prober := NewGetWithOptions(&tls.Config{InsecureSkipVerify: true}, false, tt.opts)
+			target, err := url.Parse(server.URL + tt.path)
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, nil, tt.timeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+			if tt.calls > 0 {
+				assert.Equal(t, tt.calls, calls.Load())
+			} else {
+				// the budget runs out after some of the warmups, the measured request is not sent
+				assert.Less(t, int(calls.Load()), tt.opts.WarmupRequests)
+			}
+			assert.Equal(t, tt.conns, conns.Load())
+		})
+	}
+}
+
+func TestHTTPProbeChecker_ExpectBodySHA256(t *testing.T) {
+	small := "immutable"
+	large := strings.Repeat("x", maxRespBodyLength+100)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/large" {
+			_, _ = w.Write([]byte(large))
+			return
+		}
+		_, _ = w.Write([]byte(small))
+	}))
+	defer server.Close()
+
+	sum := func(s string) string {
+		h := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(h[:])
+	}
+	testCases := map[string]struct {
+		path   string
+		hash   string
+		result api.Result
+		output string
+	}{
+		"match":                   {"/", sum(small), api.Success, small},
+		"upper case":              {"/", strings.ToUpper(sum(small)), api.Success, small},
+		"mismatch":                {"/", sum("other"), api.Failure, "got " + sum(small)},
+		"full large body":         {"/large", sum(large), api.Success, ""},
+		"truncated is not enough": {"/large", sum(large[:maxRespBodyLength]), api.Failure, "got " + sum(large)},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, Options{ExpectBodySHA256: tt.hash})
+			target, err := url.Parse(server.URL + tt.path)
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_TimeoutResult(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		path          string
+		timeoutResult api.Result
+		result        api.Result
+	}{
+		"timeout defaults to failure": {"/slow", "", api.Failure},
+		"timeout as warning":          {"/slow", api.Warning, api.Warning},
+		"other failures still fail":   {"/", api.Warning, api.Failure},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, Options{TimeoutResult: tt.timeoutResult})
+			target, err := url.Parse(server.URL + tt.path)
+			require.NoError(t, err)
+			result, _, err := prober.Probe(target, nil, 50*time.Millisecond)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_SkipBodyRead(t *testing.T) {
+	large := strings.Repeat("x", 10<<20)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		_, _ = w.Write([]byte(large))
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		path   string
+		opts   Options
+		result api.Result
+		output string
+	}{
+		"skipped": {"/", Options{SkipBodyRead: true}, api.Success, ""},
+		"status":  {"/fail", Options{SkipBodyRead: true}, api.Failure, "HTTP probe failed with statuscode: 500"},
+		"with body checks": {
+			"/", Options{SkipBodyRead: true, ExpectJSONPath: "$.status", ExpectBodySHA256: "abc"},
+			api.Unknown, "SkipBodyRead cannot be combined with ExpectBodySHA256, ExpectJSONPath, which check the response body",
+		},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, tt.opts)
+			target, err := url.Parse(server.URL + tt.path)
+			require.NoError(t, err)
+			result, output, _ := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.Equal(t, tt.result, result)
+			assert.Equal(t, tt.output, output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_HTTP3Conflicts(t *testing.T) {
+	target, err := url.Parse("https://127.0.0.1:8443/healthz")
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		opts   Options
+		output string
+	}{
+		"dial address": {Options{HTTP3: true, DialAddress: "10.0.0.1:8443"},
+			"HTTP3 cannot be combined with DialAddress, which only apply to TCP connections"},
+		"proxies": {Options{HTTP3: true, SOCKS5: &api.SOCKS5Proxy{Address: "127.0.0.1:1080"}, Proxy: &api.HTTPProxy{URL: &url.URL{Scheme: "http", Host: "127.0.0.1:3128"}}},
+			"HTTP3 cannot be combined with SOCKS5, Proxy, which only apply to TCP connections"},
+		"local ports": {Options{HTTP3: true, LocalPorts: &api.LocalPortRange{First: 40000, Last: 40010}},
+			"HTTP3 cannot be combined with LocalPorts, which only apply to TCP connections"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, tt.opts)
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.Equal(t, api.Unknown, result)
+			assert.Equal(t, tt.output, output)
+			assert.EqualError(t, err, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_ClassifyError(t *testing.T) {
+	tlsServer := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer tlsServer.Close()
+	refused := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	refusedURL := refused.URL
+	refused.Close()
+
+	// report refused connections as degraded, and everything else as by default
+	classify := func(err error) (api.Result, api.FailureReason) {
+		if errors.Is(err, syscall.ECONNREFUSED) {
+			return api.Warning, "ConnectionRefused"
+		}
+		return DefaultErrorClassifier(err)
+	}
+	testCases := map[string]struct {
+		url      string
+		classify api.ErrorClassifier
+		result   api.Result
+		reason   api.FailureReason
+		output   string
+	}{
+		"default refused":    {refusedURL, nil, api.Failure, api.ReasonConnectionFailed, "connection refused"},
+		"default tls":        {tlsServer.URL, nil, api.Failure, api.ReasonTLSHandshakeFailed, "TLS certificate verification failed"},
+		"classified refused": {refusedURL, classify, api.Warning, "ConnectionRefused", "connection refused"},
+		"classified tls":     {tlsServer.URL, classify, api.Failure, api.ReasonTLSHandshakeFailed, "TLS certificate verification failed"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, Options{ClassifyError: tt.classify}).(DetailedGetProber)
+			target, err := url.Parse(tt.url)
+			require.NoError(t, err)
+			d, err := prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, d.Result)
+			assert.Equal(t, tt.reason, d.Reason)
+			assert.Contains(t, d.Output, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_TLSMinKeyBits(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	rsaServer := httptest.NewTLSServer(handler)
+	defer rsaServer.Close()
+	ecdsaServer := httptest.NewUnstartedServer(handler)
+	ecdsaServer.TLS = &tls.Config{Certificates: []tls.Certificate{newECDSACertificate(t)}}
+	ecdsaServer.StartTLS()
+	defer ecdsaServer.Close()
+	plainServer := httptest.NewServer(handler)
+	defer plainServer.Close()
+
+	testCases := map[string]struct {
+		url     string
+		minBits int
+		result  api.Result
+		output  string
+	}{
+		"rsa strong enough": {rsaServer.URL, 2048, api.Success, ""},
+		"rsa too weak":      {rsaServer.URL, 4096, api.Failure, "leaf certificate key is RSA 2048 bits, minimum 4096 bits"},
+		"ecdsa equivalent":  {ecdsaServer.URL, 3072, api.Success, ""},
+		"ecdsa too weak":    {ecdsaServer.URL, 4096, api.Failure, "leaf certificate key is ECDSA P-256, equivalent to RSA 3072 bits"},
+		"not tls":           {plainServer.URL, 2048, api.Failure, "response was not received over TLS"},
+		"disabled":          {plainServer.URL, 0, api.Success, ""},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(&tls.Config{InsecureSkipVerify: true}, false, Options{TLSMinKeyBits: tt.minBits})
+			target, err := url.Parse(tt.url)
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+		})
+	}
+}
+
+func newECDSACertificate(t *testing.T) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func newTestCA(t *testing.T, cn string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	ca, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return ca, key
+}
+
+func newClientCertificate(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey, cn string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestNewGet_Options(t *testing.T) {
+	var userAgent atomic.Value
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent.Store(r.UserAgent())
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		case "/redirect":
+			http.Redirect(w, r, "http://"+strings.Replace(r.Host, "127.0.0.1", "localhost", 1)+"/", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	tlsServer := httptest.NewTLSServer(handler)
+	defer tlsServer.Close()
+
+	testCases := map[string]struct {
+		options   []Option
+		url       string
+		result    api.Result
+		userAgent string
+	}{
+		"defaults":                     {nil, server.URL, api.Success, DefaultUserAgent},
+		"user agent":                   {[]Option{WithUserAgent("probe/1.0")}, server.URL, api.Success, "probe/1.0"},
+		"timeout":                      {[]Option{WithTimeout(50 * time.Millisecond)}, server.URL + "/slow", api.Failure, ""},
+		"tls verified by default":      {nil, tlsServer.URL, api.Failure, ""},
+		"tls config":                   {[]Option{WithTLSConfig(&tls.Config{InsecureSkipVerify: true})}, tlsServer.URL, api.Success, DefaultUserAgent},
+		"non local redirect":           {nil, server.URL + "/redirect", api.Warning, ""},
+		"follow non local redirects":   {[]Option{WithFollowNonLocalRedirects(true)}, server.URL + "/redirect", api.Success, ""},
+		"options":                      {[]Option{WithOptions(Options{UserAgent: "options/1.0"})}, server.URL, api.Success, "options/1.0"},
+		"options replace earlier ones": {[]Option{WithUserAgent("probe/1.0"), WithOptions(Options{})}, server.URL, api.Success, DefaultUserAgent},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			userAgent.Store("")
+			target, err := url.Parse(tt.url)
+			require.NoError(t, err)
+			result, _, err := NewGet(tt.options...).Probe(target, nil, 0)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			if tt.userAgent != "" {
+				assert.Equal(t, tt.userAgent, userAgent.Load())
+			}
+			result, _, err = NewPost(tt.options...).Probe(target, nil, nil, "", 0)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_ExpectBodyEqualsFile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"version":"v1","status":"ok"}`))
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	writeFile := func(name, content string) string {
+		path := dir + "/" + name
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return path
+	}
+	exact := writeFile("exact.json", `{"version":"v1","status":"ok"}`)
+	reordered := writeFile("reordered.json", "{\n  \"status\": \"ok\",\n  \"version\": \"v1\"\n}\n")
+	changed := writeFile("changed.json", `{"status":"ok","version":"v2"}`)
+
+	testCases := map[string]struct {
+		file       string
+		comparison BodyComparison
+		result     api.Result
+		output     string
+	}{
+		"exact":               {exact, "", api.Success, ""},
+		"exact mismatch":      {reordered, BodyComparisonExact, api.Failure, "body differs from golden file " + reordered + " at line 1 (4 lines expected, 1 received):\n- {\n+ {\"version\""},
+		"json normalized":     {reordered, BodyComparisonJSON, api.Success, ""},
+		"json mismatch":       {changed, BodyComparisonJSON, api.Failure, "at line 3 (4 lines expected, 4 received):\n-   \"version\": \"v2\"\n+   \"version\": \"v1\""},
+		"missing golden file": {dir + "/missing.json", "", api.Unknown, "failed to read golden file"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, Options{ExpectBodyEqualsFile: tt.file, BodyFileComparison: tt.comparison})
+			target, err := url.Parse(server.URL)
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.Equal(t, tt.result, result)
+			assert.Equal(t, tt.result == api.Unknown, err != nil)
+			assert.Contains(t, output, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_ConnectionReuse(t *testing.T) {
+	var closeRequested atomic.Value
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		closeRequested.Store(r.Close && r.Header.Get("Connection") == "close")
+		w.WriteHeader(http.StatusOK)
+	}))
+	var connections int32
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&connections, 1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	testCases := map[string]struct {
+		opts        Options
+		close       bool
+		connections int32
+	}{
+		"default":                    {Options{}, true, 3},
+		"keep alive":                 {Options{EnableKeepAlives: true}, false, 1},
+		"keep alive, close requests": {Options{EnableKeepAlives: true, CloseConnection: true}, true, 3},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			atomic.StoreInt32(&connections, 0)
+			prober := NewGetWithOptions(nil, false, tt.opts)
+			target, err := url.Parse(server.URL)
+			require.NoError(t, err)
+			for i := 0; i < 3; i++ {
+				result, _, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+				require.NoError(t, err)
+				require.Equal(t, api.Success, result)
+				assert.Equal(t, tt.close, closeRequested.Load())
+			}
+			assert.Equal(t, tt.connections, atomic.LoadInt32(&connections))
+		})
+	}
+}
+
+func TestHTTPProbeChecker_MaxClockSkew(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ahead":
+			w.Header().Set("Date", time.Now().Add(time.Hour+30*time.Second).UTC().Format(http.TimeFormat))
+		case "/behind":
+			w.Header().Set("Date", time.Now().Add(-10*time.Minute-30*time.Second).UTC().Format(http.TimeFormat))
+		case "/invalid":
+			w.Header().Set("Date", "yesterday")
+		case "/none":
+			w.Header()["Date"] = nil
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		path    string
+		maxSkew time.Duration
+		result  api.Result
+		output  string
+	}{
+		"in sync":        {"/", time.Minute, api.Success, ""},
+		"disabled":       {"/ahead", 0, api.Success, ""},
+		"ahead":          {"/ahead", time.Minute, api.Failure, "server clock is off by 1h0m"},
+		"behind":         {"/behind", time.Minute, api.Failure, "server clock is off by -10m3"},
+		"within skew":    {"/behind", time.Hour, api.Success, ""},
+		"invalid header": {"/invalid", time.Minute, api.Failure, `invalid Date header "yesterday"`},
+		"no header":      {"/none", time.Minute, api.Failure, "response has no Date header"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, Options{MaxClockSkew: tt.maxSkew})
+			target, err := url.Parse(server.URL + tt.path)
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+		})
+	}
+}
+
+type closeTrackingConn struct {
+	net.Conn
+	closed int32
+}
+
+func (c *closeTrackingConn) Close() error {
+	atomic.StoreInt32(&c.closed, 1)
+	return c.Conn.Close()
+}
+
+func TestHTTPProbeChecker_ProbeConn(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/", http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte("over " + r.Host))
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	tlsServer := httptest.NewTLSServer(handler)
+	defer tlsServer.Close()
+
+	testCases := map[string]struct {
+		addr   string
+		url    string
+		result api.Result
+		output string
+	}{
+		// the URL host is not dialed, the supplied connection is used instead
+		"http":     {server.Listener.Addr().String(), "http://tunnel.example/", api.Success, "over tunnel.example"},
+		"https":    {tlsServer.Listener.Addr().String(), "https://tunnel.example/", api.Success, "over tunnel.example"},
+		"redirect": {server.Listener.Addr().String(), "http://tunnel.example/redirect", api.Failure, "the supplied connection has already been used"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			c, err := net.Dial("tcp", tt.addr)
+			require.NoError(t, err)
+			conn := &closeTrackingConn{Conn: c}
+			target, err := url.Parse(tt.url)
+			require.NoError(t, err)
+
+			prober := NewGetWithOptions(&tls.Config{InsecureSkipVerify: true}, false, Options{}).(ConnGetProber)
+			result, output, err := prober.ProbeConn(conn, target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+			assert.Equal(t, int32(1), atomic.LoadInt32(&conn.closed))
+		})
+	}
+}
+
+func TestHTTPProbeChecker_EmptyResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/partial" {
+			w.Header().Set("Content-Length", "100")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte("cut"))
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Errorf("failed to hijack: %v", err)
+			return
+		}
+		_ = conn.Close()
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		url    string
+		reason api.FailureReason
+		output string
+	}{
+		"closed without response": {server.URL, api.ReasonEmptyResponse, "empty response, the connection was closed before a response was received"},
+		"connection refused":      {"http://127.0.0.1:1", api.ReasonConnectionFailed, "connection refused"},
+		"partial body":            {server.URL + "/partial", api.ReasonBodyReadFailed, ""},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, Options{}).(DetailedGetProber)
+			target, err := url.Parse(tt.url)
+			require.NoError(t, err)
+			d, _ := prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+			assert.Equal(t, api.Failure, d.Result)
+			assert.Equal(t, tt.reason, d.Reason)
+			assert.Contains(t, d.Output, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_ExpectJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ContentType, ContentJson)
+		_, _ = w.Write([]byte(`{"ready": true, "replicas": 3, "phase": "Running", "message": "all replicas available"}`))
+	}))
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		assertions []JSONAssertion
+		result     api.Result
+		output     string
+	}{
+		"all hold": {
+			[]JSONAssertion{
+				{Path: "$.ready", Value: "true"},
+				{Path: "$.replicas", Operator: JSONOperatorGreaterThan, Value: "2"},
+				{Path: "$.replicas", Operator: JSONOperatorLessThan, Value: "10"},
+				{Path: "$.phase", Operator: JSONOperatorNotEqual, Value: "Pending"},
+				{Path: "$.message", Operator: JSONOperatorContains, Value: "available"},
+			},
+			api.Success, `"ready"`,
+		},
+		"each failure reported": {
+			[]JSONAssertion{
+				{Path: "$.ready", Operator: JSONOperatorEqual, Value: "true"},
+				{Path: "$.replicas", Operator: JSONOperatorGreaterThan, Value: "3"},
+				{Path: "$.phase", Operator: JSONOperatorEqual, Value: "Succeeded"},
+			},
+			api.Failure, `2 of 3 JSON assertions failed: $.replicas is "3", expected gt "3"; $.phase is "Running", expected eq "Succeeded"`,
+		},
+		"not a number": {
+			[]JSONAssertion{{Path: "$.phase", Operator: JSONOperatorLessThan, Value: "1"}},
+			api.Failure, `$.phase is "Running", not a number`,
+		},
+		"unknown operator": {
+			[]JSONAssertion{{Path: "$.phase", Operator: "matches", Value: "Run.*"}},
+			api.Failure, `unknown operator "matches" for $.phase`,
+		},
+		"missing field": {
+			[]JSONAssertion{{Path: "$.status", Value: "ok"}},
+			api.Failure, "status is not found",
+		},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, Options{ExpectJSON: tt.assertions})
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_ExpectJSONAll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ContentType, ContentJson)
+		_, _ = w.Write([]byte(`{
+			"dependencies": [{"name": "db", "status": "UP"}, {"name": "cache", "status": "DOWN"}, {"name": "queue", "status": "UP"}],
+			"checks": {"disk": "UP", "memory": "UP"},
+			"components": {"api": "UP", "worker": "DEGRADED", "scheduler": "DOWN"},
+			"version": "1.2.0"
+		}`))
+	}))
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		assertion JSONAllAssertion
+		result    api.Result
+		output    string
+	}{
+		"object all up": {
+			JSONAllAssertion{Path: "$.checks", Value: "UP"},
+			api.Success, `"version"`,
+		},
+		"object entries down": {
+			JSONAllAssertion{Path: "$.components", Value: "UP"},
+			api.Failure, `2 of 3 entries of $.components are not "UP": scheduler is "DOWN", worker is "DEGRADED"`,
+		},
+		"array entries down by name": {
+			JSONAllAssertion{Path: "$.dependencies", Field: "{.status}", NameField: "{.name}", Value: "UP"},
+			api.Failure, `1 of 3 entries of $.dependencies are not "UP": cache is "DOWN"`,
+		},
+		"array entries down by index": {
+			JSONAllAssertion{Path: "$.dependencies", Field: "{.status}", Value: "UP"},
+			api.Failure, `1 of 3 entries of $.dependencies are not "UP": 1 is "DOWN"`,
+		},
+		"missing field": {
+			JSONAllAssertion{Path: "$.dependencies", Field: "{.state}", NameField: "{.name}", Value: "UP"},
+			api.Failure, `3 of 3 entries of $.dependencies are not "UP": db: failed to evaluate JSONPath {.state}`,
+		},
+		"not a collection": {
+			JSONAllAssertion{Path: "$.version", Value: "UP"},
+			api.Failure, "$.version is not a JSON array or object",
+		},
+		"missing path": {
+			JSONAllAssertion{Path: "$.status", Value: "UP"},
+			api.Failure, "status is not found",
+		},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, Options{ExpectJSONAll: []JSONAllAssertion{tt.assertion}})
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_LocalPorts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, port, _ := net.SplitHostPort(r.RemoteAddr)
+		_, _ = w.Write([]byte(port))
+	}))
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	busy, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer busy.Close()
+	busyPort := busy.Addr().(*net.TCPAddr).Port
+
+	prober := NewGetWithOptions(nil, false, Options{LocalPorts: &api.LocalPortRange{First: busyPort}}).(DetailedGetProber)
+	d, err := prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+	assert.ErrorIs(t, err, api.ErrLocalPortsInUse)
+	assert.Equal(t, api.Unknown, d.Result)
+
+	free, err := net.Listen("tcp", ":"+strconv.Itoa(busyPort+1))
+	if err != nil {
+		t.Skipf("port %d next to the busy port is not free: %v", busyPort+1, err)
+	}
+	require.NoError(t, free.Close())
+	prober = NewGetWithOptions(nil, false, Options{LocalPorts: &api.LocalPortRange{First: busyPort, Last: busyPort + 1}}).(DetailedGetProber)
+	d, err = prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, api.Success, d.Result)
+	assert.Equal(t, strconv.Itoa(busyPort+1), d.Output)
+}
+
+func TestHTTPProbeChecker_ForbidServerPush(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	config := &tls.Config{InsecureSkipVerify: true}
+
+	prober := NewGetWithOptions(config, false, Options{ForbidServerPush: true}).(DetailedGetProber)
+	d, err := prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, api.Success, d.Result)
+
+	target = startPushingServer(t, server.TLS.Certificates)
+	d, err = prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, api.Failure, d.Result)
+	assert.Equal(t, api.ReasonAssertionFailed, d.Reason)
+	assert.Contains(t, d.Output, "the server sent 1 PUSH_PROMISE frame(s)")
+}
+
+func TestHTTPProbeChecker_ForbidServerPushAccountCost(t *testing.T) {
+	var closed atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	server.EnableHTTP2 = true
+	server.Config.ConnState = func(conn net.Conn, state http.ConnState) {
+		if state == http.StateClosed {
+			closed.Add(1)
+		}
+	}
+	server.StartTLS()
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	config := &tls.Config{InsecureSkipVerify: true}
+
+	prober := NewGetWithOptions(config, false, Options{ForbidServerPush: true, AccountCost: true}).(DetailedGetProber)
+	d, err := prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, api.Success, d.Result)
+	require.NotNil(t, d.Cost)
+	assert.Equal(t, int64(1), d.Cost.Connections)
+	// the connection of the probe is released once it is done
+	assert.Eventually(t, func() bool { return closed.Load() == 1 }, wait.ForeverTestTimeout, 10*time.Millisecond)
+}
+
+// startPushingServer starts an HTTP/2 server that promises a pushed stream before answering the first request,
+// ignoring that the client disabled push.
+func startPushingServer(t *testing.T, certs []tls.Certificate) *url.URL {
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: certs, NextProtos: []string{http2.NextProtoTLS}})
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if _, err := io.ReadFull(conn, make([]byte, len(http2.ClientPreface))); err != nil {
+			return
+		}
+		framer := http2.NewFramer(conn, conn)
+		if err := framer.WriteSettings(); err != nil {
+			return
+		}
+		for {
+			f, err := framer.ReadFrame()
+			if err != nil {
+				return
+			}
+			if _, ok := f.(*http2.HeadersFrame); ok {
+				break
+			}
+		}
+		var block bytes.Buffer
+		enc := hpack.NewEncoder(&block)
+		for _, field := range []hpack.HeaderField{
+			{Name: ":method", Value: "GET"},
+			{Name: ":scheme", Value: "https"},
+			{Name: ":authority", Value: ln.Addr().String()},
+			{Name: ":path", Value: "/pushed"},
+		} {
+			_ = enc.WriteField(field)
+		}
+		_ = framer.WritePushPromise(http2.PushPromiseParam{StreamID: 1, PromiseID: 2, BlockFragment: block.Bytes(), EndHeaders: true})
+		block.Reset()
+		_ = enc.WriteField(hpack.HeaderField{Name: ":status", Value: "200"})
+		_ = framer.WriteHeaders(http2.HeadersFrameParam{StreamID: 1, BlockFragment: block.Bytes(), EndHeaders: true, EndStream: true})
+		// Wait for the client to give up on the connection.
+		_, _ = io.Copy(io.Discard, conn)
+	}()
+	target, err := url.Parse("https://" + ln.Addr().String())
+	require.NoError(t, err)
+	return target
+}
+
+func TestHTTPProbeChecker_TooManyRequests(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 || r.URL.Path == "/throttled" {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		path   string
+		opts   Options
+		result api.Result
+		output string
+		calls  int32
+	}{
+		"failure by default": {"/throttled", Options{}, api.Failure, "HTTP probe failed with statuscode: 429", 1},
+		"warning":            {"/throttled", Options{TooManyRequestsResult: api.Warning}, api.Warning, "HTTP probe throttled with statuscode: 429, retry after 1s", 1},
+		"success":            {"/throttled", Options{TooManyRequestsResult: api.Success}, api.Success, "HTTP probe throttled with statuscode: 429, retry after 1s", 1},
+		"retry after":        {"/recovers", Options{Retry: &RetryPolicy{Attempts: 2, MaxRetryAfter: 50 * time.Millisecond}}, api.Success, "", 2},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			calls.Store(0)
+			prober := NewGetWithOptions(nil, false, tt.opts).(DetailedGetProber)
+			target, err := url.Parse(server.URL + tt.path)
+			require.NoError(t, err)
+			d, err := prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, d.Result)
+			assert.Equal(t, tt.output, d.Output)
+			assert.Equal(t, tt.calls, calls.Load())
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	testCases := map[string]struct {
+		value    string
+		expected time.Duration
+	}{
+		"empty":   {"", 0},
+		"seconds": {"120", 2 * time.Minute},
+		"date":    {now.Add(time.Minute).Format(http.TimeFormat), time.Minute},
+		"past":    {now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		"invalid": {"soon", 0},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, retryAfter(tt.value, now))
+		})
+	}
+
+	policy := &RetryPolicy{Interval: time.Second}
+	assert.Equal(t, time.Second, policy.pause(Details{}))
+	assert.Equal(t, 5*time.Second, policy.pause(Details{RetryAfter: 5 * time.Second}))
+	assert.Equal(t, DefaultMaxRetryAfter, policy.pause(Details{RetryAfter: time.Hour}))
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestHTTPProbeChecker_WrapTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer injected" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	var wrapped int
+	var requested []string
+	opts := Options{WrapTransport: func(rt http.RoundTripper) http.RoundTripper {
+		wrapped++
+		assert.IsType(t, &http.Transport{}, rt)
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			requested = append(requested, req.URL.String())
+			req = req.Clone(req.Context())
+			req.Header.Set("Authorization", "Bearer injected")
+			return rt.RoundTrip(req)
+		})
+	}}
+
+	result, _, err := NewGet().Probe(target, nil, wait.ForeverTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, api.Failure, result)
+
+	prober := NewGetWithOptions(nil, false, opts)
+	for i := 0; i < 2; i++ {
+		result, _, err = prober.Probe(target, nil, wait.ForeverTestTimeout)
+		require.NoError(t, err)
+		assert.Equal(t, api.Success, result)
+	}
+	assert.Equal(t, 2, wrapped)
+	assert.Equal(t, []string{server.URL, server.URL}, requested)
+}
+
+func TestHTTPProbeChecker_Conditional(t *testing.T) {
+	modified := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-Match") != "" && r.Header.Get("If-Match") != `"v1"` {
+			w.WriteHeader(http.StatusPreconditionFailed)
+			return
+		}
+		since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+		if r.Header.Get("If-None-Match") == `"v1"` || (err == nil && !since.Before(modified)) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte("fresh"))
+	}))
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	testCases := map[string]struct {
+		opts    Options
+		headers http.Header
+		result  api.Result
+		output  string
+	}{
+		"not modified":           {Options{IfNoneMatch: `"v1"`}, nil, api.Success, "conditional request answered with 304 Not Modified"},
+		"modified":               {Options{IfNoneMatch: `"v0"`}, nil, api.Success, "fresh"},
+		"not modified since":     {Options{IfModifiedSince: modified}, nil, api.Success, "conditional request answered with 304 Not Modified"},
+		"request header":         {Options{AcceptConditionalCodes: []int{http.StatusNotModified}}, http.Header{"If-None-Match": {`"v1"`}}, api.Success, "conditional request answered with 304 Not Modified"},
+		"request header wins":    {Options{IfNoneMatch: `"v1"`}, http.Header{"If-None-Match": {`"v0"`}}, api.Success, "fresh"},
+		"precondition accepted":  {Options{AcceptConditionalCodes: []int{http.StatusPreconditionFailed}}, http.Header{"If-Match": {`"v0"`}}, api.Success, "conditional request answered with 412 Precondition Failed"},
+		"not enabled":            {Options{}, http.Header{"If-None-Match": {`"v1"`}}, api.Warning, ""},
+		"code not accepted":      {Options{IfNoneMatch: `"v1"`, AcceptConditionalCodes: []int{http.StatusPreconditionFailed}}, nil, api.Warning, ""},
+		"header check still run": {Options{IfNoneMatch: `"v1"`, ForbidResponseHeaders: []string{"ETag"}}, nil, api.Failure, "forbidden response header Etag"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, tt.opts)
+			result, output, err := prober.Probe(target, tt.headers, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_AccountCost(t *testing.T) {
+	body := strings.Repeat("x", 1000)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "/", http.StatusFound)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	prober := NewGetWithOptions(nil, true, Options{}).(DetailedGetProber)
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	d, err := prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+	require.NoError(t, err)
+	assert.Nil(t, d.Cost)
+
+	prober = NewGetWithOptions(nil, true, Options{AccountCost: true}).(DetailedGetProber)
+	for i := 0; i < 2; i++ {
+		d, err = prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+		require.NoError(t, err)
+		assert.Equal(t, api.Success, d.Result)
+		require.NotNil(t, d.Cost)
+		assert.Equal(t, int64(1), d.Cost.Connections)
+		assert.Greater(t, d.Cost.BytesSent, int64(0))
+		assert.Greater(t, d.Cost.BytesReceived, int64(len(body)))
+	}
+
+	target, err = url.Parse(server.URL + "/redirect")
+	require.NoError(t, err)
+	redirected, err := prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, api.Success, redirected.Result)
+	require.NotNil(t, redirected.Cost)
+	assert.Equal(t, int64(2), redirected.Cost.Connections)
+	assert.Greater(t, redirected.Cost.BytesSent, d.Cost.BytesSent)
+}
+
+// grpcWebFrame returns a gRPC-Web frame with flag and payload.
+func grpcWebFrame(flag byte, payload string) []byte {
+	n := len(payload)
+	return append([]byte{flag, byte(n >> 24), byte(n >> 16), byte(n >> 8), byte(n)}, payload...)
+}
+
+func TestHTTPProbeChecker_ExpectGRPCWebStatus(t *testing.T) {
+	data := grpcWebFrame(0, "\x08\x01")
+	ok := append(append([]byte{}, data...), grpcWebFrame(grpcWebTrailerFlag, "grpc-status: 0\r\ngrpc-message: \r\n")...)
+	notFound := append(append([]byte{}, data...), grpcWebFrame(grpcWebTrailerFlag, "grpc-status:5\r\ngrpc-message:no such service%3A demo\r\n")...)
+	// grpc-web-text may send each frame as its own padded base64 chunk
+	text := base64.StdEncoding.EncodeToString(data) + base64.StdEncoding.EncodeToString(grpcWebFrame(grpcWebTrailerFlag, "grpc-status: 0\r\n"))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/ok":
+			w.Header().Set(ContentType, "application/grpc-web+proto")
+			_, _ = w.Write(ok)
+		case "/not-found":
+			w.Header().Set(ContentType, "application/grpc-web+proto")
+			_, _ = w.Write(notFound)
+		case "/text":
+			w.Header().Set(ContentType, "application/grpc-web-text+proto")
+			_, _ = w.Write([]byte(text))
+		case "/trailers-only":
+			w.Header().Set(ContentType, "application/grpc-web+proto")
+			w.Header().Set("grpc-status", "12")
+		case "/truncated":
+			w.Header().Set(ContentType, "application/grpc-web+proto")
+			_, _ = w.Write(ok[:len(ok)-3])
+		}
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		path   string
+		result api.Result
+		output string
+	}{
+		"ok":            {"/ok", api.Success, "grpc-status: 0 (OK)"},
+		"not found":     {"/not-found", api.Failure, "grpc-status: 5 (NotFound), grpc-message: no such service: demo, expected grpc-status: 0 (OK)"},
+		"text":          {"/text", api.Success, "grpc-status: 0 (OK)"},
+		"trailers only": {"/trailers-only", api.Failure, "grpc-status: 12 (Unimplemented), expected grpc-status: 0 (OK)"},
+		"truncated":     {"/truncated", api.Failure, "gRPC-Web response body ends with a truncated frame of 32 bytes"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, Options{ExpectGRPCWebStatus: true})
+			target, err := url.Parse(server.URL + tt.path)
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Equal(t, tt.output, output)
+		})
+	}
+}
+
+func TestHTTPProbeChecker_Charset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ContentType, r.URL.Query().Get("type"))
+		switch r.URL.Path {
+		case "/utf8":
+			_, _ = w.Write([]byte("grüße"))
+		case "/mojibake":
+			_, _ = w.Write([]byte("gr\xfc\xdfe"))
+		case "/ascii":
+			_, _ = w.Write([]byte("hello"))
+		}
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		path   string
+		opts   Options
+		result api.Result
+		output string
+	}{
+		"valid utf8":              {"/utf8?type=text/plain%3B+charset=utf-8", Options{RequireValidUTF8: true}, api.Success, "grüße"},
+		"valid utf8 undeclared":   {"/utf8?type=text/plain", Options{RequireValidUTF8: true}, api.Success, "grüße"},
+		"invalid utf8":            {"/mojibake?type=text/plain%3B+charset=utf-8", Options{RequireValidUTF8: true}, api.Failure, "response body is not valid UTF-8: invalid byte 0xfc at offset 2"},
+		"other charset":           {"/mojibake?type=text/plain%3B+charset=ISO-8859-1", Options{RequireValidUTF8: true}, api.Failure, "response declares charset ISO-8859-1, expected UTF-8"},
+		"ascii is utf8":           {"/ascii?type=text/plain%3B+charset=us-ascii", Options{RequireValidUTF8: true}, api.Success, "hello"},
+		"expected charset":        {"/mojibake?type=text/plain%3B+charset=ISO-8859-1", Options{ExpectCharset: "latin1"}, api.Success, "gr\xfc\xdfe"},
+		"expected charset alias":  {"/utf8?type=text/plain%3B+charset=UTF8", Options{ExpectCharset: "utf-8"}, api.Success, "grüße"},
+		"unexpected charset":      {"/utf8?type=text/plain%3B+charset=utf-8", Options{ExpectCharset: "iso-8859-1"}, api.Failure, "response declares charset utf-8, expected iso-8859-1"},
+		"missing charset":         {"/utf8?type=text/plain", Options{ExpectCharset: "utf-8"}, api.Failure, "response declares no charset, expected utf-8"},
+		"expected charset checks": {"/mojibake?type=text/plain%3B+charset=utf-8", Options{ExpectCharset: "utf-8"}, api.Failure, "response body is not valid UTF-8: invalid byte 0xfc at offset 2"},
+		"invalid ascii":           {"/utf8?type=text/plain%3B+charset=us-ascii", Options{ExpectCharset: "us-ascii"}, api.Failure, "response body is not valid US-ASCII: byte 0xc3 at offset 2"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewGetWithOptions(nil, false, tt.opts)
+			target, err := url.Parse(server.URL + tt.path)
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Equal(t, tt.output, output)
+		})
+	}
+}
+
+func TestValidUTF8_Truncated(t *testing.T) {
+	body := []byte("grü")
+	assert.Error(t, validUTF8(body[:len(body)-1], false))
+	assert.NoError(t, validUTF8(body[:len(body)-1], true))
+	assert.Error(t, validUTF8([]byte("\xfcx"), true))
+}
+
+func TestHTTPProbeChecker_MalformedRedirect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/no-location":
+			w.WriteHeader(http.StatusFound)
+		case "/multiple-choices":
+			w.WriteHeader(http.StatusMultipleChoices)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		path   string
+		opts   Options
+		result api.Result
+		reason api.FailureReason
+		output string
+	}{
+		"missing location":  {"/no-location", Options{}, api.Failure, api.ReasonMalformedRedirect, "malformed redirect: 302 Found response has no Location header"},
+		"configured result": {"/no-location", Options{MalformedRedirectResult: api.Warning}, api.Warning, "", "malformed redirect: 302 Found response has no Location header"},
+		"not a redirect":    {"/multiple-choices", Options{}, api.Warning, "", ""},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			for _, follow := range []bool{false, true} {
+				prober := NewGetWithOptions(nil, follow, tt.opts).(DetailedGetProber)
+				target, err := url.Parse(server.URL + tt.path)
+				require.NoError(t, err)
+				d, err := prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+				assert.NoError(t, err)
+				assert.Equal(t, tt.result, d.Result)
+				assert.Equal(t, tt.reason, d.Reason)
+				assert.Equal(t, tt.output, d.Output)
+			}
+		})
+	}
+}
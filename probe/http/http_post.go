@@ -17,7 +17,10 @@ limitations under the License.
 package http
 
 import (
+	"context"
 	"crypto/tls"
+	"fmt"
+	"io"
 	"net/http"
 	"net/url"
 	"strings"
@@ -26,7 +29,6 @@ import (
 	api "kmodules.xyz/prober/api"
 
 	"github.com/gabriel-vasile/mimetype"
-	utilnet "k8s.io/apimachinery/pkg/util/net"
 )
 
 // New creates PostProber that will skip TLS verification while probing.
@@ -43,14 +45,15 @@ func NewHttpPost(followNonLocalRedirects bool) PostProber {
 //
 //	If disabled, redirects to other hosts will trigger a warning result.
 func NewPostWithTLSConfig(config *tls.Config, followNonLocalRedirects bool) PostProber {
-	// We do not want the probe use node's local proxy set.
-	transport := utilnet.SetTransportDefaults(
-		&http.Transport{
-			TLSClientConfig:   config,
-			DisableKeepAlives: true,
-			Proxy:             http.ProxyURL(nil),
-		})
-	return httpPostProber{transport, followNonLocalRedirects}
+	return NewPostWithOptions(config, followNonLocalRedirects, Options{})
+}
+
+// NewPostWithOptions takes tls config and additional prober options as parameter.
+// followNonLocalRedirects configures whether the prober should follow redirects to a different hostname.
+//
+//	If disabled, redirects to other hosts will trigger a warning result.
+func NewPostWithOptions(config *tls.Config, followNonLocalRedirects bool, opts Options) PostProber {
+	return NewPost(WithOptions(opts), WithTLSConfig(config), WithFollowNonLocalRedirects(followNonLocalRedirects))
 }
 
 // PostProber is an interface that defines the Probe function for doing HTTP probe.
@@ -61,16 +64,59 @@ type PostProber interface {
 type httpPostProber struct {
 	transport               *http.Transport
 	followNonLocalRedirects bool
+	opts                    Options
 }
 
 // Probe returns a ProbeRunner capable of running an HTTP check.
 func (pr httpPostProber) Probe(url *url.URL, headers http.Header, form url.Values, body string, timeout time.Duration) (api.Result, string, error) {
-	client := &http.Client{
-		Timeout:       timeout,
-		Transport:     pr.transport,
-		CheckRedirect: redirectChecker(pr.followNonLocalRedirects),
+	d, err := pr.ProbeDetailed(url, headers, form, body, timeout)
+	return d.Result, d.Output, err
+}
+
+// ProbeDetailed runs an HTTP check like Probe and reports its detailed outcome.
+func (pr httpPostProber) ProbeDetailed(url *url.URL, headers http.Header, form url.Values, body string, timeout time.Duration) (Details, error) {
+	return pr.probeDetailed(url, headers, form, body, probeScope{}, timeout)
+}
+
+// probeDetailed runs an HTTP check with scope, whose pod is used to render templated assertions and whose
+// settings override the options of the prober.
+func (pr httpPostProber) probeDetailed(url *url.URL, headers http.Header, form url.Values, body string, scope probeScope, timeout time.Duration) (Details, error) {
+	opts := pr.opts
+	scope.apply(&opts)
+	client, release, err := newClient(pr.transport, pr.followNonLocalRedirects, &opts, timeout)
+	if err != nil {
+		return Details{Result: api.Unknown, Output: err.Error()}, err
+	}
+	defer release()
+	return opts.sample(client, func() (Details, error) {
+		return doHTTPPostProbe(url, headers, client, form, body, &opts)
+	})
+}
+
+// NewPostTargetProber adapts a PostProber to the unified api.Prober interface.
+func NewPostTargetProber(p PostProber) api.Prober {
+	return postTargetProber{p}
+}
+
+type postTargetProber struct {
+	PostProber
+}
+
+// Probe runs an HTTP POST check against target.URL, sending target.Form or target.Body.
+func (pr postTargetProber) Probe(ctx context.Context, target api.Target) (api.Result, string, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Unknown, "", err
+	}
+	if p, ok := pr.PostProber.(podPostProber); ok {
+		d, err := p.probeDetailed(target.URL, target.Headers, target.Form, target.Body, scopeOf(target), api.TimeoutFor(ctx, target))
+		return d.Result, d.Output, err
 	}
-	return DoHTTPPostProbe(url, headers, client, form, body)
+	return pr.PostProber.Probe(target.URL, target.Headers, target.Form, target.Body, api.TimeoutFor(ctx, target))
+}
+
+// podPostProber is implemented by the PostProber of this package to pass the scope of the target through.
+type podPostProber interface {
+	probeDetailed(url *url.URL, headers http.Header, form url.Values, body string, scope probeScope, timeout time.Duration) (Details, error)
 }
 
 // DoHTTPPostProbe checks if a POST request to the url succeeds.
@@ -78,25 +124,100 @@ func (pr httpPostProber) Probe(url *url.URL, headers http.Header, form url.Value
 // If the HTTP response code is unsuccessful or HTTP communication fails, it returns Failure.
 // This is exported because some other packages may want to do direct HTTP probes.
 func DoHTTPPostProbe(addr *url.URL, headers http.Header, client HTTPInterface, form url.Values, body string) (api.Result, string, error) {
+	d, err := doHTTPPostProbe(addr, headers, client, form, body, &Options{})
+	return d.Result, d.Output, err
+}
+
+// DoHTTPPostFormProbe is like DoHTTPPostProbe, but sends the form built from typed values by EncodeForm.
+// A form that can not be encoded makes the result Unknown without sending the request.
+func DoHTTPPostFormProbe(addr *url.URL, headers http.Header, client HTTPInterface, fields map[string]interface{}) (api.Result, string, error) {
+	form, err := EncodeForm(fields)
+	if err != nil {
+		return api.Unknown, err.Error(), err
+	}
+	return DoHTTPPostProbe(addr, headers, client, form, "")
+}
+
+// BodyFunc returns a fresh body for a POST probe and its content type. An empty content type is
+// detected from the body.
+type BodyFunc func() (contentType string, body io.Reader, err error)
+
+// DoHTTPPostBodyFuncProbe is like DoHTTPPostProbe, but sends the body returned by bodyFunc, which is called
+// for every probe. An error of bodyFunc makes the result Unknown without sending the request.
+func DoHTTPPostBodyFuncProbe(addr *url.URL, headers http.Header, client HTTPInterface, bodyFunc BodyFunc) (api.Result, string, error) {
+	d, err := doHTTPPostProbe(addr, headers, client, nil, "", &Options{BodyFunc: bodyFunc})
+	return d.Result, d.Output, err
+}
+
+// generateBody calls BodyFunc and reads the body it returns.
+func (opts *Options) generateBody() (string, string, error) {
+	contentType, body, err := opts.BodyFunc()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate request body: %w", err)
+	}
+	if body == nil {
+		return contentType, "", nil
+	}
+	b, err := io.ReadAll(body)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read generated request body: %w", err)
+	}
+	if contentType == "" && len(b) > 0 {
+		contentType = mimetype.Detect(b).String()
+	}
+	return contentType, string(b), nil
+}
+
+func doHTTPPostProbe(addr *url.URL, headers http.Header, client HTTPInterface, form url.Values, body string, opts *Options) (Details, error) {
 	var req *http.Request
 	var err error
 
+	// Copy the headers as they may be shared by concurrent probes.
+	headers = headers.Clone()
 	if headers == nil {
 		headers = http.Header{}
 	}
 
-	if form != nil {
-		req, err = http.NewRequest(http.MethodPost, addr.String(), strings.NewReader(form.Encode()))
+	var payload string
+	if opts.UploadSize > 0 {
+		if opts.Signer != nil {
+			return Details{Result: api.Unknown, Output: errUploadSigned.Error()}, errUploadSigned
+		}
+		req, err = http.NewRequest(http.MethodPost, addr.String(), opts.uploadBody())
 		if err != nil {
 			// Convert errors into failures to catch timeouts.
-			return api.Failure, err.Error(), nil
+			return Details{Result: api.Failure, Output: err.Error(), Reason: api.ReasonConnectionFailed}, nil
+		}
+		req.ContentLength = -1
+		headers.Set(ContentType, "application/octet-stream")
+	} else if opts.BodyFunc != nil {
+		var contentType string
+		contentType, payload, err = opts.generateBody()
+		if err != nil {
+			return Details{Result: api.Unknown, Output: err.Error()}, err
+		}
+		req, err = http.NewRequest(http.MethodPost, addr.String(), strings.NewReader(payload))
+		if err != nil {
+			// Convert errors into failures to catch timeouts.
+			return Details{Result: api.Failure, Output: err.Error(), Reason: api.ReasonConnectionFailed}, nil
+		}
+		if contentType != "" {
+			headers.Set(ContentType, contentType)
+		}
+	} else if form != nil {
+		payload = form.Encode()
+		req, err = http.NewRequest(http.MethodPost, addr.String(), strings.NewReader(payload))
+		if err != nil {
+			// Convert errors into failures to catch timeouts.
+			return Details{Result: api.Failure, Output: err.Error(), Reason: api.ReasonConnectionFailed}, nil
 		}
 		headers.Set(ContentType, ContentUrlEncodedForm)
 	} else if len(body) > 0 {
+		payload = body
 		req, err = http.NewRequest(http.MethodPost, addr.String(), strings.NewReader(body))
 		if err != nil {
 			// Convert errors into failures to catch timeouts.
-			return api.Failure, err.Error(), nil
+			return Details{Result: api.Failure, Output: err.Error(), Reason: api.ReasonConnectionFailed}, nil
 		}
 		mime := mimetype.Detect([]byte(body))
 		headers.Set(ContentType, mime.String())
@@ -104,9 +225,21 @@ func DoHTTPPostProbe(addr *url.URL, headers http.Header, client HTTPInterface, f
 		req, err = http.NewRequest(http.MethodPost, addr.String(), nil)
 		if err != nil {
 			// Convert errors into failures to catch timeouts.
-			return api.Failure, err.Error(), nil
+			return Details{Result: api.Failure, Output: err.Error(), Reason: api.ReasonConnectionFailed}, nil
+		}
+	}
+
+	if opts.Signer != nil {
+		headers.Set(opts.Signer.header(), opts.Signer.sign([]byte(payload)))
+	}
+	if len(opts.RequestTrailers) > 0 {
+		if opts.UploadSize <= 0 {
+			// the transport drops the trailers of a request with a known length, unknown makes it send the body chunked
+			req.Body = io.NopCloser(strings.NewReader(payload))
+			req.ContentLength = -1
 		}
+		req.Trailer = opts.RequestTrailers.Clone()
 	}
 
-	return doHTTPProbe(req, addr, headers, client)
+	return doHTTPProbe(req, addr, headers, client, opts)
 }
@@ -19,9 +19,14 @@ package http
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net"
 	"net/http"
 	"net/http/httptest"
@@ -37,6 +42,7 @@ import (
 	"github.com/gabriel-vasile/mimetype"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	core "k8s.io/api/core/v1"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 )
@@ -433,3 +439,243 @@ func TestHTTPPostProbeChecker_PayloadNormal(t *testing.T) {
 		assert.Equal(t, body, string(normalPayload))
 	})
 }
+
+func TestHTTPPostProbeChecker_HMACSignature(t *testing.T) {
+	key := []byte("secret")
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		utilruntime.Must(err)
+		mac := hmac.New(sha256.New, key)
+		mac.Write(body)
+		header := r.URL.Query().Get("header")
+		if r.Header.Get(header) != hex.EncodeToString(mac.Sum(nil)) {
+			http.Error(w, "bad signature", http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	testCases := map[string]struct {
+		signer *HMACSigner
+		header string
+		form   url.Values
+		body   string
+		result api.Result
+	}{
+		"body":           {&HMACSigner{Key: key}, DefaultSignatureHeader, nil, `{"ping":"pong"}`, api.Success},
+		"form":           {&HMACSigner{Key: key}, DefaultSignatureHeader, url.Values{"k": {"v"}}, "", api.Success},
+		"empty body":     {&HMACSigner{Key: key}, DefaultSignatureHeader, nil, "", api.Success},
+		"custom header":  {&HMACSigner{Key: key, Header: "X-Hub-Signature"}, "X-Hub-Signature", nil, "hello", api.Success},
+		"wrong key":      {&HMACSigner{Key: []byte("other")}, DefaultSignatureHeader, nil, "hello", api.Failure},
+		"without signer": {nil, DefaultSignatureHeader, nil, "hello", api.Failure},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewPostWithOptions(nil, false, Options{Signer: tt.signer})
+			target, err := url.Parse(server.URL + "/?header=" + tt.header)
+			require.NoError(t, err)
+			result, _, err := prober.Probe(target, nil, tt.form, tt.body, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+		})
+	}
+	assert.NotContains(t, fmt.Sprint(&HMACSigner{Key: key}), string(key))
+}
+
+func TestHTTPPostProbeChecker_Upload(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n, err := io.Copy(io.Discard, r.Body)
+		utilruntime.Must(err)
+		if r.URL.Query().Get("short") != "" {
+			n--
+		}
+		_, _ = fmt.Fprintf(w, `{"bytesReceived": %d, "encoding": "%s", "type": "%s"}`,
+			n, strings.Join(r.TransferEncoding, ","), r.Header.Get(ContentType))
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		query  string
+		opts   Options
+		result api.Result
+		output string
+	}{
+		"acknowledged": {
+			"", Options{UploadSize: 5 << 20, ExpectUploadAckPath: "$.bytesReceived"},
+			api.Success, `{"bytesReceived": 5242880, "encoding": "chunked", "type": "application/octet-stream"}`,
+		},
+		"short": {
+			"?short=1", Options{UploadSize: 5 << 20, ExpectUploadAckPath: "$.bytesReceived"},
+			api.Failure, "upload acknowledged 5242879 bytes, expected 5242880 bytes",
+		},
+		"not a number": {
+			"", Options{UploadSize: 10, ExpectUploadAckPath: "$.encoding"},
+			api.Failure, `acknowledged byte count $.encoding is "chunked", not a number`,
+		},
+		"without assertion": {
+			"?short=1", Options{UploadSize: 10},
+			api.Success, `{"bytesReceived": 9, "encoding": "chunked", "type": "application/octet-stream"}`,
+		},
+		"signed": {
+			"", Options{UploadSize: 10, Signer: &HMACSigner{Key: []byte("secret")}},
+			api.Unknown, "UploadSize cannot be combined with Signer",
+		},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewPostWithOptions(nil, false, tt.opts)
+			target, err := url.Parse(server.URL + tt.query)
+			require.NoError(t, err)
+			result, output, _ := prober.Probe(target, nil, nil, "ignored", wait.ForeverTestTimeout)
+			assert.Equal(t, tt.result, result)
+			assert.Equal(t, tt.output, output)
+		})
+	}
+}
+
+func TestHTTPPostProbeChecker_RequestTrailers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		utilruntime.Must(err)
+		// trailers are only available once the body has been read
+		_, _ = fmt.Fprintf(w, "body=%s length=%d encoding=%v trailer=%s", body, r.ContentLength, r.TransferEncoding, r.Trailer.Get("X-Checksum"))
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		trailers http.Header
+		form     url.Values
+		body     string
+		output   string
+	}{
+		"without trailers": {nil, nil, "ping", "body=ping length=4 encoding=[] trailer="},
+		"body":             {http.Header{"X-Checksum": {"abc"}}, nil, "ping", "body=ping length=-1 encoding=[chunked] trailer=abc"},
+		"form":             {http.Header{"X-Checksum": {"abc"}}, url.Values{"k": {"v"}}, "", "body=k=v length=-1 encoding=[chunked] trailer=abc"},
+		"empty body":       {http.Header{"X-Checksum": {"abc"}}, nil, "", "body= length=-1 encoding=[chunked] trailer=abc"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			prober := NewPostWithOptions(nil, false, Options{RequestTrailers: tt.trailers})
+			target, err := url.Parse(server.URL)
+			require.NoError(t, err)
+			result, output, err := prober.Probe(target, nil, tt.form, tt.body, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, api.Success, result)
+			assert.Equal(t, tt.output, output)
+		})
+	}
+}
+
+func TestEncodeForm(t *testing.T) {
+	at := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	form, err := EncodeForm(map[string]interface{}{
+		"name":     "web",
+		"scheme":   core.URISchemeHTTPS,
+		"raw":      []byte("abc"),
+		"replicas": 3,
+		"port":     uint16(8443),
+		"ratio":    0.25,
+		"big":      1e21,
+		"dryRun":   true,
+		"since":    at,
+		"interval": 90 * time.Second,
+		"zones":    []string{"a", "b"},
+		"ports":    [2]int{80, 443},
+		"empty":    []string{},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, url.Values{
+		"name":     {"web"},
+		"scheme":   {"HTTPS"},
+		"raw":      {"abc"},
+		"replicas": {"3"},
+		"port":     {"8443"},
+		"ratio":    {"0.25"},
+		"big":      {"1000000000000000000000"},
+		"dryRun":   {"true"},
+		"since":    {"2024-03-01T12:30:00Z"},
+		"interval": {"1m30s"},
+		"zones":    {"a", "b"},
+		"ports":    {"80", "443"},
+		"empty":    {},
+	}, form)
+
+	testCases := map[string]struct {
+		fields map[string]interface{}
+		err    string
+	}{
+		"empty key":       {map[string]interface{}{"": "x"}, "form field with an empty key"},
+		"nil value":       {map[string]interface{}{"a": nil}, "form field a: nil value"},
+		"nan":             {map[string]interface{}{"a": math.NaN()}, "form field a: NaN can not be encoded"},
+		"unsupported":     {map[string]interface{}{"a": map[string]string{}}, "form field a: unsupported type map[string]string"},
+		"element invalid": {map[string]interface{}{"a": []interface{}{1, struct{}{}}}, "form field a: element 1: unsupported type struct {}"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := EncodeForm(tt.fields)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestDoHTTPPostFormProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		utilruntime.Must(r.ParseForm())
+		_, _ = fmt.Fprintf(w, "%s %s", r.Header.Get(ContentType), r.PostForm.Encode())
+	}))
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	client := &http.Client{Timeout: wait.ForeverTestTimeout}
+
+	result, output, err := DoHTTPPostFormProbe(target, nil, client, map[string]interface{}{"replicas": 3, "dryRun": false})
+	assert.NoError(t, err)
+	assert.Equal(t, api.Success, result)
+	assert.Equal(t, "application/x-www-form-urlencoded dryRun=false&replicas=3", output)
+
+	result, _, err = DoHTTPPostFormProbe(target, nil, client, map[string]interface{}{"replicas": math.Inf(1)})
+	assert.EqualError(t, err, "form field replicas: +Inf can not be encoded")
+	assert.Equal(t, api.Unknown, result)
+}
+
+func TestHTTPPostProbeChecker_BodyFunc(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		utilruntime.Must(err)
+		_, _ = fmt.Fprintf(w, "%s %s", r.Header.Get(ContentType), body)
+	}))
+	defer server.Close()
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	nonce := 0
+	fresh := func() (string, io.Reader, error) {
+		nonce++
+		return ContentJson, strings.NewReader(fmt.Sprintf(`{"nonce":%d}`, nonce)), nil
+	}
+	prober := NewPostWithOptions(nil, false, Options{BodyFunc: fresh})
+	for i := 1; i <= 2; i++ {
+		result, output, err := prober.Probe(target, nil, url.Values{"ignored": {"true"}}, "ignored", wait.ForeverTestTimeout)
+		assert.NoError(t, err)
+		assert.Equal(t, api.Success, result)
+		assert.Equal(t, fmt.Sprintf(`%s {"nonce":%d}`, ContentJson, i), output)
+	}
+
+	detected := func() (string, io.Reader, error) {
+		return "", strings.NewReader("plain text"), nil
+	}
+	result, output, err := DoHTTPPostBodyFuncProbe(target, nil, &http.Client{Timeout: wait.ForeverTestTimeout}, detected)
+	assert.NoError(t, err)
+	assert.Equal(t, api.Success, result)
+	assert.Equal(t, "text/plain; charset=utf-8 plain text", output)
+
+	failing := func() (string, io.Reader, error) {
+		return "", nil, errors.New("clock not synced")
+	}
+	result, output, err = NewPostWithOptions(nil, false, Options{BodyFunc: failing}).Probe(target, nil, nil, "", wait.ForeverTestTimeout)
+	assert.EqualError(t, err, "failed to generate request body: clock not synced")
+	assert.Equal(t, api.Unknown, result)
+	assert.Equal(t, "failed to generate request body: clock not synced", output)
+}
@@ -0,0 +1,296 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// JSONOperator compares the value at the path of a JSONAssertion with its expected value.
+type JSONOperator string
+
+const (
+	// JSONOperatorEqual requires the value to equal the expected value, compared as printed by JSONPath.
+	JSONOperatorEqual JSONOperator = "eq"
+	// JSONOperatorNotEqual requires the value to differ from the expected value.
+	JSONOperatorNotEqual JSONOperator = "ne"
+	// JSONOperatorGreaterThan requires the value to be a number greater than the expected value.
+	JSONOperatorGreaterThan JSONOperator = "gt"
+	// JSONOperatorLessThan requires the value to be a number less than the expected value.
+	JSONOperatorLessThan JSONOperator = "lt"
+	// JSONOperatorContains requires the value to contain the expected value as a substring.
+	JSONOperatorContains JSONOperator = "contains"
+)
+
+// JSONAssertion is a condition on the value at a JSONPath of the response body, e.g.
+// {Path: "$.replicas", Operator: JSONOperatorGreaterThan, Value: "2"}.
+type JSONAssertion struct {
+	// Path is a JSONPath expression, e.g. "$.ready" or "{.status.phase}".
+	Path string
+	// Operator defaults to JSONOperatorEqual.
+	Operator JSONOperator
+	// Value is the expected value. Like ExpectJSONValue, it may be a text/template rendered against the probed pod.
+	Value string
+}
+
+// JSONAllAssertion requires every entry of the JSON array or object at Path to have the same value, e.g.
+// {Path: "$.dependencies", Field: "{.status}", NameField: "{.name}", Value: "UP"} for a health endpoint
+// that lists the status of its dependencies.
+type JSONAllAssertion struct {
+	// Path is a JSONPath expression selecting the array or object, e.g. "$.dependencies".
+	Path string
+	// Field is a JSONPath expression evaluated against every entry, e.g. "{.status}".
+	// Empty compares the entries themselves.
+	// +optional
+	Field string
+	// NameField is a JSONPath expression evaluated against every entry of an array to name it in the failure,
+	// e.g. "{.name}". Defaults to the index of the entry. The entries of an object are named by their keys.
+	// +optional
+	NameField string
+	// Value is the expected value of every entry, compared as printed by JSONPath. Like ExpectJSONValue,
+	// it may be a text/template rendered against the probed pod.
+	Value string
+}
+
+func (a JSONAssertion) operator() JSONOperator {
+	if a.Operator == "" {
+		return JSONOperatorEqual
+	}
+	return a.Operator
+}
+
+// verifyBody evaluates the assertions configured in opts against the response body.
+// It returns an error describing the first assertion that does not hold.
+func (opts *Options) verifyBody(body []byte) error {
+	if err := opts.verifyGolden(body); err != nil {
+		return err
+	}
+	if err := opts.verifyJSON(body); err != nil {
+		return err
+	}
+	for _, assertion := range opts.BodyAssertions {
+		if err := assertion.AssertBody(body); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (opts *Options) verifyJSON(body []byte) error {
+	if opts.ExpectJSONPath == "" && len(opts.ExpectJSON) == 0 && len(opts.ExpectJSONAll) == 0 {
+		return nil
+	}
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return fmt.Errorf("failed to parse response body as JSON: %v", err)
+	}
+	if opts.ExpectJSONPath != "" {
+		got, err := evalJSONPath(opts.ExpectJSONPath, data)
+		if err != nil {
+			return err
+		}
+		want, err := opts.renderExpected(opts.ExpectJSONValue)
+		if err != nil {
+			return err
+		}
+		if got != want {
+			return fmt.Errorf("JSONPath %s is %q, expected %q", opts.ExpectJSONPath, got, want)
+		}
+	}
+	if err := opts.verifyJSONAssertions(data); err != nil {
+		return err
+	}
+	for _, a := range opts.ExpectJSONAll {
+		if err := opts.evalJSONAllAssertion(a, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyJSONAssertions evaluates all ExpectJSON assertions against data and reports every one that does not hold.
+func (opts *Options) verifyJSONAssertions(data interface{}) error {
+	var failed []string
+	for _, a := range opts.ExpectJSON {
+		if err := opts.evalJSONAssertion(a, data); err != nil {
+			failed = append(failed, err.Error())
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d JSON assertions failed: %s", len(failed), len(opts.ExpectJSON), strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+func (opts *Options) evalJSONAssertion(a JSONAssertion, data interface{}) error {
+	got, err := evalJSONPath(a.Path, data)
+	if err != nil {
+		return err
+	}
+	want, err := opts.renderExpected(a.Value)
+	if err != nil {
+		return err
+	}
+	var ok bool
+	switch a.Operator {
+	case JSONOperatorEqual, "":
+		ok = got == want
+	case JSONOperatorNotEqual:
+		ok = got != want
+	case JSONOperatorContains:
+		ok = strings.Contains(got, want)
+	case JSONOperatorGreaterThan, JSONOperatorLessThan:
+		g, err := strconv.ParseFloat(got, 64)
+		if err != nil {
+			return fmt.Errorf("%s is %q, not a number", a.Path, got)
+		}
+		w, err := strconv.ParseFloat(want, 64)
+		if err != nil {
+			return fmt.Errorf("expected value %q of %s is not a number", want, a.Path)
+		}
+		ok = g > w
+		if a.Operator == JSONOperatorLessThan {
+			ok = g < w
+		}
+	default:
+		return fmt.Errorf("unknown operator %q for %s", a.Operator, a.Path)
+	}
+	if !ok {
+		return fmt.Errorf("%s is %q, expected %s %q", a.Path, got, a.operator(), want)
+	}
+	return nil
+}
+
+// evalJSONAllAssertion checks every entry of the array or object at a.Path and reports those without the expected value.
+func (opts *Options) evalJSONAllAssertion(a JSONAllAssertion, data interface{}) error {
+	want, err := opts.renderExpected(a.Value)
+	if err != nil {
+		return err
+	}
+	collection, err := findJSONPath(a.Path, data)
+	if err != nil {
+		return err
+	}
+	type entry struct {
+		name  string
+		value interface{}
+	}
+	var entries []entry
+	switch c := collection.(type) {
+	case []interface{}:
+		for i, v := range c {
+			name := strconv.Itoa(i)
+			if a.NameField != "" {
+				if name, err = evalJSONPath(a.NameField, v); err != nil {
+					return fmt.Errorf("entry %d of %s: %v", i, a.Path, err)
+				}
+			}
+			entries = append(entries, entry{name, v})
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(c))
+		for key := range c {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			entries = append(entries, entry{key, c[key]})
+		}
+	default:
+		return fmt.Errorf("%s is not a JSON array or object", a.Path)
+	}
+
+	field := a.Field
+	if field == "" {
+		field = "{@}"
+	}
+	var failed []string
+	for _, e := range entries {
+		got, err := evalJSONPath(field, e.value)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", e.name, err))
+		} else if got != want {
+			failed = append(failed, fmt.Sprintf("%s is %q", e.name, got))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d entries of %s are not %q: %s", len(failed), len(entries), a.Path, want, strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// findJSONPath returns the single value at path in the parsed JSON data.
+func findJSONPath(path string, data interface{}) (interface{}, error) {
+	if !strings.HasPrefix(path, "{") {
+		path = "{" + path + "}"
+	}
+	jp := jsonpath.New("expect")
+	if err := jp.Parse(path); err != nil {
+		return nil, fmt.Errorf("invalid JSONPath %s: %v", path, err)
+	}
+	results, err := jp.FindResults(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate JSONPath %s: %v", path, err)
+	}
+	if len(results) != 1 || len(results[0]) != 1 {
+		return nil, fmt.Errorf("JSONPath %s does not select a single value", path)
+	}
+	return results[0][0].Interface(), nil
+}
+
+// evalJSONPath returns the value at path in the parsed JSON data, printed the way kubectl prints JSONPath results.
+func evalJSONPath(path string, data interface{}) (string, error) {
+	if !strings.HasPrefix(path, "{") {
+		path = "{" + path + "}"
+	}
+	jp := jsonpath.New("expect")
+	if err := jp.Parse(path); err != nil {
+		return "", fmt.Errorf("invalid JSONPath %s: %v", path, err)
+	}
+	var buf bytes.Buffer
+	if err := jp.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to evaluate JSONPath %s: %v", path, err)
+	}
+	return buf.String(), nil
+}
+
+// renderExpected renders an expected value against the probed pod, if it is a template.
+func (opts *Options) renderExpected(value string) (string, error) {
+	if !strings.Contains(value, "{{") {
+		return value, nil
+	}
+	tpl, err := template.New("expect").Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid expected value template: %v", err)
+	}
+	if opts.pod == nil {
+		return "", fmt.Errorf("expected value %q is a template, but the probe has no pod", value)
+	}
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, opts.pod); err != nil {
+		return "", fmt.Errorf("failed to render expected value: %v", err)
+	}
+	return buf.String(), nil
+}
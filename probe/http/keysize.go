@@ -0,0 +1,65 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"fmt"
+)
+
+// ecdsaRSAEquivalentBits maps ECDSA key sizes to the RSA key size of comparable strength (NIST SP 800-57).
+var ecdsaRSAEquivalentBits = map[int]int{
+	224: 2048,
+	256: 3072,
+	384: 7680,
+	521: 15360,
+}
+
+// verifyKeySize checks that the public key of the leaf certificate is at least as strong as a minBits RSA key.
+func verifyKeySize(state *tls.ConnectionState, minBits int) error {
+	if state == nil {
+		return fmt.Errorf("TLS key size check failed: response was not received over TLS")
+	}
+	if len(state.PeerCertificates) == 0 {
+		return fmt.Errorf("TLS key size check failed: no peer certificate")
+	}
+	var (
+		desc string
+		bits int
+	)
+	switch key := state.PeerCertificates[0].PublicKey.(type) {
+	case *rsa.PublicKey:
+		bits = key.N.BitLen()
+		desc = fmt.Sprintf("RSA %d bits", bits)
+	case *ecdsa.PublicKey:
+		size := key.Curve.Params().BitSize
+		bits = ecdsaRSAEquivalentBits[size]
+		desc = fmt.Sprintf("ECDSA %s, equivalent to RSA %d bits", key.Curve.Params().Name, bits)
+	case ed25519.PublicKey:
+		bits = 3072
+		desc = "Ed25519, equivalent to RSA 3072 bits"
+	default:
+		return fmt.Errorf("TLS key size check failed: unsupported public key type %T", key)
+	}
+	if bits < minBits {
+		return fmt.Errorf("TLS key size check failed: leaf certificate key is %s, minimum %d bits", desc, minBits)
+	}
+	return nil
+}
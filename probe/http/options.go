@@ -0,0 +1,564 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+
+	core "k8s.io/api/core/v1"
+)
+
+const (
+	// DefaultSignatureHeader is the header that carries the request signature when no header is configured.
+	DefaultSignatureHeader = "X-Signature"
+	// DefaultUserAgent is the User-Agent of probes when neither the request headers nor the options set one.
+	DefaultUserAgent = "kmodules.xyz/client-go/release-11.0"
+)
+
+// Options holds the optional settings of the HTTP probers.
+// The zero value keeps the default probe behavior.
+type Options struct {
+	// Timeout bounds probes that are run with a zero timeout. Defaults to no timeout.
+	// +optional
+	Timeout time.Duration
+	// UserAgent is sent with probes whose request headers do not set a User-Agent. Defaults to DefaultUserAgent.
+	// +optional
+	UserAgent string
+
+	// Signer signs the body of POST requests.
+	// +optional
+	Signer *HMACSigner
+
+	// RequestTrailers are sent as trailers after the body of POST requests, e.g. for servers of streaming
+	// protocols that expect them. HTTP/1.1 only carries trailers with a chunked body, so the body is then
+	// sent with chunked transfer encoding and without a Content-Length, which servers that require one reject.
+	// +optional
+	RequestTrailers http.Header
+
+	// BodyFunc generates the body of every POST probe instead of its form or body, e.g. for payloads with
+	// timestamps or nonces that a server rejects when replayed. The body is read into memory before the
+	// request is sent, so it can be signed by Signer. An error of BodyFunc makes the probe Unknown.
+	// UploadSize takes precedence.
+	// +optional
+	BodyFunc BodyFunc
+
+	// UploadSize makes POST probes upload a generated body of this many bytes instead of their form or body,
+	// e.g. to verify the write path of an upload service end-to-end. The body is streamed with chunked
+	// transfer encoding rather than held in memory, so it cannot be signed by Signer.
+	// +optional
+	UploadSize int64
+	// ExpectUploadAckPath is a JSONPath expression, e.g. "$.bytesReceived", at which the JSON response body
+	// must acknowledge all UploadSize bytes. A mismatch fails the probe, reporting both byte counts.
+	// +optional
+	ExpectUploadAckPath string
+
+	// TLSMinAcceptedVersion fails the probe when the negotiated TLS version is lower, e.g. tls.VersionTLS12.
+	// Unlike tls.Config.MinVersion, the connection is still made, so the result is a Failure rather than a dial error.
+	// The negotiated version is reported in Details.TLS, whether the check passes or not.
+	// +optional
+	TLSMinAcceptedVersion uint16
+	// TLSMinKeyBits fails the probe when the public key of the server's leaf certificate is weaker than an RSA key
+	// of this size, e.g. 2048. ECDSA keys are compared by their RSA equivalent strength, P-256 counting as 3072 bits,
+	// P-384 as 7680 bits and P-521 as 15360 bits. The failure reports the observed key algorithm and size.
+	// +optional
+	TLSMinKeyBits int
+
+	// RequireOCSPStaple fails the probe when the server does not staple an OCSP response to the TLS handshake.
+	// Whether a response was stapled is reported in Details.TLS.OCSPStapled.
+	// +optional
+	RequireOCSPStaple bool
+	// RequireOCSPGood additionally parses the stapled response and fails the probe unless it reports the
+	// certificate status as good. It implies RequireOCSPStaple.
+	// +optional
+	RequireOCSPGood bool
+
+	// ExpectStatusText must match the status line of the response (e.g. "200 WARMING").
+	// +optional
+	ExpectStatusText *regexp.Regexp
+
+	// TolerateBodyReadError evaluates the probe on the partially read body when reading the response body fails.
+	// A successful probe then returns a Warning whose output includes the partial body and the read error.
+	// By default such errors fail the probe.
+	// +optional
+	TolerateBodyReadError bool
+
+	// SOCKS5 routes the probe connections through a SOCKS5 proxy. Defaults to dialing directly.
+	// +optional
+	SOCKS5 *api.SOCKS5Proxy
+
+	// Proxy sends the probe requests through an HTTP forward proxy, optionally with credentials.
+	// By default probes never use a proxy, including the one configured in the environment, see UseEnvironmentProxy.
+	// +optional
+	Proxy *api.HTTPProxy
+	// UseEnvironmentProxy sends the probe requests through the proxy configured by the HTTP_PROXY, HTTPS_PROXY
+	// and NO_PROXY environment variables, read when the prober is created. Requests to localhost are never proxied.
+	// Proxy takes precedence when both are set.
+	// +optional
+	UseEnvironmentProxy bool
+
+	// DialAddress is the host:port to connect to instead of the host of the probe URL.
+	// The URL host is still used for the request line, the Host header and TLS SNI,
+	// so a specific replica can be probed by IP while validating hostname based routing.
+	// +optional
+	DialAddress string
+
+	// UnixSocket is the path of a Unix domain socket to connect to instead of the host of the probe URL,
+	// e.g. "/var/run/app.sock" for daemons serving HTTP over a socket. The URL host is then a placeholder,
+	// used for the Host header only, e.g. http://localhost/healthz. It takes precedence over DialAddress,
+	// SOCKS5 and LocalPorts, which do not apply to Unix sockets.
+	// +optional
+	UnixSocket string
+
+	// LocalPorts binds the probe connections to a local port of the range instead of an ephemeral port,
+	// e.g. for firewall rules keyed off the client source port. With a SOCKS5 proxy, the connection to
+	// the proxy is bound. A probe finding no free port is Unknown.
+	// +optional
+	LocalPorts *api.LocalPortRange
+
+	// EnableKeepAlives keeps the connections of the prober pooled for reuse by later probes.
+	// By default every probe opens a new connection that is closed after the response.
+	// +optional
+	EnableKeepAlives bool
+	// CloseConnection sends each request with "Connection: close", so the server closes the connection
+	// after responding, while the transport stays pooled when EnableKeepAlives is set.
+	// +optional
+	CloseConnection bool
+
+	// ExpectProto is the protocol the response must be received over, e.g. "HTTP/2.0".
+	// +optional
+	ExpectProto string
+
+	// HTTP3 sends the probe over HTTP/3 (QUIC) instead of TCP. The QUIC handshake is bounded by the probe timeout.
+	// It requires a binary built with the http3 build tag; otherwise probes return Unknown with ErrHTTP3Unsupported.
+	// It cannot be combined with DialAddress, UnixSocket, LocalPorts, SOCKS5, Proxy and UseEnvironmentProxy,
+	// which only apply to TCP connections. A probe configured with both is Unknown.
+	// +optional
+	HTTP3 bool
+
+	// WrapTransport wraps the transport of the probe request, e.g. to inject credentials, cache or record
+	// requests. It is called for every probe with the transport of the prober, or the HTTP/3 transport with HTTP3,
+	// and must pass the requests on to it to reach the target.
+	// +optional
+	WrapTransport func(http.RoundTripper) http.RoundTripper
+
+	// ForbidServerPush sends the probe over HTTP/2 and fails it if the server pushes a stream. The probe disables
+	// push like every Go client, so this catches servers that push regardless. It only applies to https URLs;
+	// proxies are not used, and HTTP3 takes precedence.
+	// +optional
+	ForbidServerPush bool
+
+	// AllowedRedirectHosts are hosts that redirects are followed to even when non-local redirects are not.
+	// Redirects to any other host stop at the redirect response, which results in a Warning.
+	// Hosts are matched case-insensitively against the hostname of the redirect location, without the port.
+	// +optional
+	AllowedRedirectHosts []string
+
+	// ExpectRedirectCount is the exact number of redirects that must be followed to reach the final response.
+	// A redirect that is not followed, e.g. to a non-local host, is not counted. Disabled when nil.
+	// +optional
+	ExpectRedirectCount *int
+
+	// ExpectHTTPS fails the probe unless the final URL, after following redirects, uses the https scheme,
+	// e.g. to confirm that an HTTP endpoint redirects to HTTPS. Redirects to other hosts are only followed
+	// with followNonLocalRedirects or AllowedRedirectHosts. The failure reports the final URL.
+	// +optional
+	ExpectHTTPS bool
+
+	// TimeoutResult is the result of a probe that times out connecting, or waiting for or reading the response.
+	// Defaults to Failure. Set it to Warning so that slow but alive targets are reported as degraded rather than down.
+	// Other errors still fail the probe.
+	// +optional
+	TimeoutResult api.Result
+	// ClassifyError maps the errors of the request, e.g. a refused connection, a TLS handshake error, a timeout
+	// or a failure to read the response body, to the result and failure reason of the probe. By default, the
+	// errors of the request fail the probe as DefaultErrorClassifier does, body read errors fail it with
+	// ReasonBodyReadFailed, and timeouts are TimeoutResult if set. TimeoutResult is not used when it is set.
+	// The output still describes the error.
+	// +optional
+	ClassifyError api.ErrorClassifier
+	// TooManyRequestsResult is the result of a 429 Too Many Requests response, e.g. Warning for a rate limited
+	// endpoint that is alive but throttled. Defaults to Failure with ReasonUnexpectedStatus. Only failures are
+	// retried by Retry, which then honors the Retry-After header of the response.
+	// +optional
+	TooManyRequestsResult api.Result
+	// MalformedRedirectResult is the result of a redirect response (301, 302, 303, 307 or 308) without a
+	// Location header, which the client can not follow. Defaults to Failure with ReasonMalformedRedirect,
+	// instead of the Warning of other redirects that are not followed.
+	// +optional
+	MalformedRedirectResult api.Result
+
+	// Samples sends the request this many times in a row, e.g. 10, and checks the LatencyPercentile of
+	// their latencies against MaxLatency, for a more stable latency gate than a single request.
+	// The samples share the probe timeout as their budget, and the first one that does not pass fails
+	// the probe. The output of the last sample is reported, together with the latency percentile.
+	// +optional
+	Samples int
+	// WarmupRequests are throwaway requests sent before the measured ones, e.g. to exclude the cost of
+	// setting up the connection and TLS session from MaxLatency. Their outcome is ignored, and they share
+	// the probe timeout with the measured requests. They only help if the connection is reused, so they turn on
+	// keep-alives for this prober like EnableKeepAlives does, and CloseConnection defeats them. Without Samples or MaxLatency,
+	// the output reports the latency of the measured request.
+	// +optional
+	WarmupRequests int
+	// LatencyPercentile is the percentile of the sampled latencies checked against MaxLatency. Defaults to 95.
+	// +optional
+	LatencyPercentile float64
+	// MaxLatency fails the probe when the LatencyPercentile of the sampled latencies exceeds it.
+	// Without Samples, it applies to the latency of a single request.
+	// +optional
+	MaxLatency time.Duration
+
+	// Retry attempts a failed probe again, optionally only for some failure reasons. Each attempt is bounded
+	// by the probe timeout, and the result of the last attempt is returned. Defaults to a single attempt.
+	// +optional
+	Retry *RetryPolicy
+
+	// ExpectGzip requests a gzip encoded response, unless the probe or AcceptEncoding sets Accept-Encoding,
+	// and fails the probe when the response is not gzip encoded, reporting the Content-Encoding seen.
+	// The body is decoded before other assertions run, unless RawBody is set.
+	// +optional
+	ExpectGzip bool
+	// ExpectGzipSmaller additionally fails the probe when the encoded body is not smaller than the decoded one.
+	// It implies ExpectGzip. Only the part of the body read by the probe is compared.
+	// +optional
+	ExpectGzipSmaller bool
+	// AcceptEncoding sets the Accept-Encoding header of the request, unless the probe sets it itself,
+	// e.g. "gzip" or "identity". Without it, the transport asks for gzip and transparently decodes the response.
+	// A gzip encoded response is decoded before the assertions run, unless RawBody is set.
+	// +optional
+	AcceptEncoding string
+	// RawBody runs the body assertions and the body hash on the body as received, without decoding its
+	// Content-Encoding, e.g. to hash the compressed bytes. It also turns off the transparent gzip handling
+	// of the transport, so the response is only encoded when asked for with AcceptEncoding or ExpectGzip.
+	// +optional
+	RawBody bool
+
+	// ExpectBodySHA256 fails the probe unless the hex encoded SHA-256 of the response body matches,
+	// e.g. for endpoints serving immutable content. The hash covers the full body, after gzip decoding
+	// unless RawBody is set, so the probe reads the body to the end instead of stopping at the output limit.
+	// A partial body tolerated with TolerateBodyReadError fails the check.
+	// +optional
+	ExpectBodySHA256 string
+
+	// MaxClockSkew fails the probe when the Date header of the response differs from the local clock by more,
+	// e.g. to detect clock skew of the server, or when the header is missing. The Date header has a resolution
+	// of one second. The failure reports the observed skew.
+	// +optional
+	MaxClockSkew time.Duration
+
+	// ExpectContentLengthMatch fails the probe when the response declares a Content-Length that differs
+	// from the number of body bytes received, e.g. to detect truncating proxies. The failure reports both.
+	// The check is skipped when the probe stops reading at its output limit, unless ExpectBodySHA256 makes
+	// it read the full body. A body decoded by the transport, which drops the Content-Length, is not checked.
+	// +optional
+	ExpectContentLengthMatch bool
+
+	// ExpectServerContains fails the probe unless the Server header of the response contains this text,
+	// ignoring case, e.g. "envoy" to check the reverse proxy in front of the target whatever its version.
+	// The failure reports the Server header received.
+	// +optional
+	ExpectServerContains string
+
+	// ForbidResponseHeaders fails the probe if the response carries any of these headers, e.g. "X-Debug".
+	// +optional
+	ForbidResponseHeaders []string
+	// ExpectResponseHeaders are assertions on the values of response headers, e.g. that X-Served-By names the
+	// probed pod, to detect routing bugs that send the probe to another backend. The failure reports the
+	// expected and the actual value.
+	// +optional
+	ExpectResponseHeaders []HeaderAssertion
+
+	// AcceptInformationalCodes are 1xx status codes that pass the probe, e.g. 101 for endpoints that
+	// switch protocols. Other 1xx responses fail the probe. The body of a 1xx response is not read.
+	// +optional
+	AcceptInformationalCodes []int
+
+	// IfNoneMatch is sent as the If-None-Match header of probes whose headers do not set one, e.g. the ETag of
+	// a cached representation, to check that the server answers conditional requests with 304 Not Modified.
+	// +optional
+	IfNoneMatch string
+	// IfModifiedSince is sent as the If-Modified-Since header of probes whose headers do not set one.
+	// +optional
+	IfModifiedSince time.Time
+	// AcceptConditionalCodes are the status codes that pass a conditional probe, i.e. one whose request carries
+	// an If-None-Match, If-Modified-Since, If-Match, If-Unmodified-Since or If-Range header. It defaults to
+	// 304 Not Modified when IfNoneMatch or IfModifiedSince is set. Only the response headers of these responses
+	// are checked, and the output reports that the conditional request was answered, e.g. "conditional request
+	// answered with 304 Not Modified". Other responses are evaluated as usual.
+	// +optional
+	AcceptConditionalCodes []int
+	// MinOpenDuration keeps the connection of an accepted 101 Switching Protocols response open for this long,
+	// e.g. to check that a WebSocket server keeps sessions open instead of closing them right after the
+	// handshake. The probe fails if the server closes the connection sooner, or if the probe times out first,
+	// so the timeout must be longer. The output reports how long the connection stayed open.
+	// +optional
+	MinOpenDuration time.Duration
+
+	// Trace records the DNS, connect, TLS handshake and time to first byte phases of the probe
+	// in Details.Timings. It is disabled by default to keep the overhead off the default path.
+	// +optional
+	Trace bool
+	// ReportTLS reports the negotiated TLS version, cipher suite, the subject and expiry of the server's
+	// certificate and whether an OCSP response was stapled in Details.TLS. It only reports; use TLSMinAcceptedVersion or TLSMinKeyBits to assert.
+	// +optional
+	ReportTLS bool
+
+	// VerifyPeerCertificate is called during the TLS handshake to run custom certificate checks,
+	// e.g. for a custom extension. It has the semantics of tls.Config.VerifyPeerCertificate:
+	// it runs in addition to the standard verification, and verifiedChains is empty when
+	// InsecureSkipVerify is set, in which case it is the only verification. An error fails
+	// the probe with ReasonTLSHandshakeFailed.
+	// +optional
+	VerifyPeerCertificate func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error
+
+	// ClientCertificate returns the TLS client certificate for mTLS, e.g. probe.SecretCertificate.ClientCertificate.
+	// It is called before every probe, so it should cache; an error makes the probe Unknown without connecting.
+	// +optional
+	ClientCertificate func() (*tls.Certificate, error)
+	// ClientCertificates are candidate TLS client certificates, e.g. one per client CA in environments with several.
+	// When the server requests a certificate, the first one it accepts by its acceptable CAs, signature schemes and
+	// key type is sent, or none if it accepts none. It is ignored when ClientCertificate is set.
+	// +optional
+	ClientCertificates []tls.Certificate
+
+	// ExpectCharset is the charset the Content-Type of the response must declare, e.g. "utf-8". Names are
+	// case insensitive, and common aliases such as "utf8" and "latin1" match. The body must also be valid
+	// in that charset; this is checked for UTF-8 and US-ASCII.
+	// +optional
+	ExpectCharset string
+	// RequireValidUTF8 fails the probe if the response body is not valid UTF-8, e.g. to catch mojibake, or if
+	// the Content-Type declares a charset other than UTF-8 or its subset US-ASCII. The failure reports the
+	// offset of the first invalid byte. Only the first maxRespBodyLength bytes of the body are checked.
+	// +optional
+	RequireValidUTF8 bool
+
+	// ExpectJSONPath is a JSONPath expression, e.g. "$.version" or "{.status.phase}", whose value in the
+	// JSON response body must equal ExpectJSONValue. Only the first maxRespBodyLength bytes of the body are read.
+	// +optional
+	ExpectJSONPath string
+	// ExpectJSONValue is the expected value at ExpectJSONPath, compared as printed by JSONPath.
+	// It may be a text/template that is rendered against the probed pod, e.g.
+	// {{index .Annotations "deployed-version"}}. Templates need a pod, so they only work for
+	// probes run through the api.Prober adapters with Target.Pod set, as done by probe.Prober.
+	// +optional
+	ExpectJSONValue string
+	// ExpectJSON are assertions on several fields of the JSON response body, such as $.ready eq true and
+	// $.replicas gt 2. The body is parsed once, all assertions must hold, and the failure reports every
+	// assertion that does not. Only the first maxRespBodyLength bytes of the body are read.
+	// +optional
+	ExpectJSON []JSONAssertion
+	// ExpectJSONAll are assertions that every entry of a JSON array or object in the response body has a value,
+	// e.g. that a composite health endpoint reports all of its dependencies "UP". The failure reports the entries
+	// that do not.
+	// +optional
+	ExpectJSONAll []JSONAllAssertion
+
+	// BodyAssertions are further checks of the response body, run after ExpectJSONPath, ExpectJSON and ExpectJSONAll,
+	// e.g. protobuf.Assertion for binary APIs. The first one that fails fails the probe with its error.
+	// +optional
+	BodyAssertions []BodyAssertion
+
+	// ExpectGRPCWebStatus treats the response as gRPC-Web and fails the probe unless its grpc-status is 0 (OK).
+	// The status is decoded from the trailer frame at the end of the body, base64 decoded first for the
+	// application/grpc-web-text content type, or from the headers of trailers-only responses. The output
+	// reports the decoded status and grpc-message instead of the body.
+	// +optional
+	ExpectGRPCWebStatus bool
+
+	// ExpectBodyEqualsFile is the path of a golden file the response body must equal, e.g. for contract checks.
+	// The file is read on every probe, and a probe whose file can not be read is Unknown. A mismatch reports
+	// the first differing line. Only the first maxRespBodyLength bytes of the body are read.
+	// +optional
+	ExpectBodyEqualsFile string
+	// BodyFileComparison selects how the body is compared with ExpectBodyEqualsFile. Defaults to BodyComparisonExact.
+	// +optional
+	BodyFileComparison BodyComparison
+
+	// MaxOutputLength caps the length in bytes of the returned output, e.g. to keep events small.
+	// Assertions still run on the whole body that was read. Zero means no cap.
+	// +optional
+	MaxOutputLength int
+	// SkipBodyRead closes the response body without reading it, e.g. for frequent status probes of endpoints
+	// with large bodies. The probe then only checks the status and headers, and its output is empty on success.
+	// It cannot be combined with the options that check the body: ExpectGzip, ExpectGzipSmaller, ExpectBodySHA256,
+	// ExpectContentLengthMatch, ExpectCharset, RequireValidUTF8, ExpectJSONPath, ExpectJSON, ExpectJSONAll,
+	// BodyAssertions, ExpectBodyEqualsFile, ExpectUploadAckPath and ExpectGRPCWebStatus. A probe configured
+	// with both is Unknown.
+	// +optional
+	SkipBodyRead bool
+
+	// Authenticator authenticates the probe requests, e.g. with SPNEGO from the spnego subpackage.
+	// If it fails, or the target responds with 401 Unauthorized, the probe fails with
+	// ReasonAuthenticationFailed rather than a connection or status failure.
+	// +optional
+	Authenticator Authenticator
+
+	// Recorder receives a transcript of every probe exchange for debugging: the request line and
+	// headers, and the status line, headers and the first 1KB of the body of the response.
+	// Credentials in Authorization, Proxy-Authorization, Cookie, Set-Cookie and the signature
+	// header are redacted. It may be shared by concurrent probes; each transcript is a single Write.
+	// +optional
+	Recorder io.Writer
+
+	// AccountCost reports the approximate cost of every probe in Details.Cost: the connections it opened and
+	// the bytes it sent and received over them, e.g. to tune probe frequencies. The probes then do not reuse
+	// pooled connections even with EnableKeepAlives. It is not supported with HTTP3.
+	// +optional
+	AccountCost bool
+
+	// pod is the pod targeted by a single probe, used to render ExpectJSONValue, ExpectJSON, ExpectJSONAll
+	// and ExpectResponseHeaders.
+	pod *core.Pod
+	// sensitiveHeaders are the request headers of a single probe whose values are redacted.
+	sensitiveHeaders []string
+	// cost tallies the cost of a single probe with AccountCost.
+	cost *costCounter
+	// golden is the content of ExpectBodyEqualsFile read for a single probe.
+	golden []byte
+}
+
+// probeScope is what a single probe run through the api.Prober adapters knows about its target
+// beyond the request.
+type probeScope struct {
+	pod              *core.Pod
+	sensitiveHeaders []string
+	// signer and statusText override Options.Signer and Options.ExpectStatusText if set.
+	signer     *HMACSigner
+	statusText *regexp.Regexp
+	// forbidHeaders are forbidden in addition to Options.ForbidResponseHeaders.
+	forbidHeaders []string
+	// bodySHA256 overrides Options.ExpectBodySHA256 if set.
+	bodySHA256 string
+	// closeConnection sets Options.CloseConnection.
+	closeConnection bool
+	// trailers override Options.RequestTrailers if set.
+	trailers http.Header
+	// server overrides Options.ExpectServerContains if set.
+	server string
+	// charset overrides Options.ExpectCharset if set, and requireUTF8 sets Options.RequireValidUTF8.
+	charset     string
+	requireUTF8 bool
+}
+
+// scopeOf returns the scope of a probe of target.
+func scopeOf(target api.Target) probeScope {
+	scope := probeScope{
+		pod:              target.Pod,
+		sensitiveHeaders: target.SensitiveHeaders,
+		statusText:       target.ExpectStatusText,
+		forbidHeaders:    target.ForbidResponseHeaders,
+		bodySHA256:       target.ExpectBodySHA256,
+		closeConnection:  target.CloseConnection,
+		trailers:         target.Trailers,
+		server:           target.ExpectServerContains,
+		charset:          target.ExpectCharset,
+		requireUTF8:      target.RequireValidUTF8,
+	}
+	if len(target.SigningKey) > 0 {
+		scope.signer = &HMACSigner{Key: target.SigningKey, Header: target.SignatureHeader}
+	}
+	return scope
+}
+
+// apply sets the scope on opts, the copy of the options of the prober made for a single probe.
+func (scope probeScope) apply(opts *Options) {
+	opts.pod, opts.sensitiveHeaders = scope.pod, scope.sensitiveHeaders
+	if scope.signer != nil {
+		opts.Signer = scope.signer
+	}
+	if scope.statusText != nil {
+		opts.ExpectStatusText = scope.statusText
+	}
+	if len(scope.forbidHeaders) > 0 {
+		opts.ForbidResponseHeaders = slices.Concat(opts.ForbidResponseHeaders, scope.forbidHeaders)
+	}
+	if scope.bodySHA256 != "" {
+		opts.ExpectBodySHA256 = scope.bodySHA256
+	}
+	if scope.closeConnection {
+		opts.CloseConnection = true
+	}
+	if len(scope.trailers) > 0 {
+		opts.RequestTrailers = scope.trailers
+	}
+	if scope.server != "" {
+		opts.ExpectServerContains = scope.server
+	}
+	if scope.charset != "" {
+		opts.ExpectCharset = scope.charset
+	}
+	if scope.requireUTF8 {
+		opts.RequireValidUTF8 = true
+	}
+}
+
+func (opts *Options) userAgent() string {
+	if opts.UserAgent != "" {
+		return opts.UserAgent
+	}
+	return DefaultUserAgent
+}
+
+// Authenticator adds credentials to the requests of HTTP probes.
+type Authenticator interface {
+	// Authenticate sets the credentials on req, typically as the Authorization header.
+	Authenticate(req *http.Request) error
+}
+
+// BodyAssertion checks the body of the responses to HTTP probes, e.g. the assertions of the protobuf subpackage.
+type BodyAssertion interface {
+	// AssertBody returns an error describing why body does not pass the assertion.
+	AssertBody(body []byte) error
+}
+
+// HMACSigner sets a hex encoded HMAC-SHA256 of the request body in a request header.
+type HMACSigner struct {
+	// Key is the shared secret. It is never logged.
+	Key []byte
+	// Header is the name of the header that carries the signature. Defaults to DefaultSignatureHeader.
+	Header string
+}
+
+func (s *HMACSigner) header() string {
+	if s.Header == "" {
+		return DefaultSignatureHeader
+	}
+	return s.Header
+}
+
+func (s *HMACSigner) sign(body []byte) string {
+	mac := hmac.New(sha256.New, s.Key)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// String implements fmt.Stringer so that the key is redacted if the signer is ever printed.
+func (s *HMACSigner) String() string {
+	return fmt.Sprintf("HMACSigner{Header: %s, Key: <redacted>}", s.header())
+}
@@ -0,0 +1,120 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package protobuf asserts on HTTP probe responses encoded as Protobuf messages.
+// It is a separate package so that users of the HTTP probers do not depend on the Protobuf runtime.
+package protobuf
+
+import (
+	"fmt"
+	"strings"
+
+	httpprobe "kmodules.xyz/prober/probe/http"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// Assertion is a httpprobe.BodyAssertion that decodes the response body as a Protobuf message
+// and compares one of its fields with an expected value.
+type Assertion struct {
+	// Message is the type the response body is decoded as.
+	Message protoreflect.MessageType
+	// Field is the dot separated path of the checked field, by proto field names, e.g. "status"
+	// or "spec.replicas". Every element but the last must be a singular message field.
+	Field string
+	// Value is the expected value of Field in its text form: enums by value name, bools as "true"
+	// or "false", and numbers in decimal. Unset fields have their default value.
+	Value string
+}
+
+var _ httpprobe.BodyAssertion = &Assertion{}
+
+// NewAssertion returns an Assertion for the message type registered under the fully qualified
+// name messageName, e.g. "grpc.health.v1.HealthCheckResponse". The Go package of the message
+// must be linked into the binary for it to be registered.
+func NewAssertion(messageName, field, value string) (*Assertion, error) {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(messageName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find protobuf message %s: %v", messageName, err)
+	}
+	a := &Assertion{
+		Message: mt,
+		Field:   field,
+		Value:   value,
+	}
+	if _, err := a.lookup(mt.New()); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// AssertBody decodes body as a.Message and checks that a.Field has the value a.Value.
+func (a *Assertion) AssertBody(body []byte) error {
+	name := a.Message.Descriptor().FullName()
+	msg := a.Message.New()
+	if err := proto.Unmarshal(body, msg.Interface()); err != nil {
+		return fmt.Errorf("failed to decode response body as %s: %v", name, err)
+	}
+	got, err := a.lookup(msg)
+	if err != nil {
+		return err
+	}
+	if got != a.Value {
+		return fmt.Errorf("protobuf field %s of %s is %q, expected %q", a.Field, name, got, a.Value)
+	}
+	return nil
+}
+
+// lookup returns the text form of a.Field in msg.
+func (a *Assertion) lookup(msg protoreflect.Message) (string, error) {
+	path := strings.Split(a.Field, ".")
+	for i, elem := range path {
+		md := msg.Descriptor()
+		fd := md.Fields().ByName(protoreflect.Name(elem))
+		if fd == nil {
+			return "", fmt.Errorf("message %s has no field %s", md.FullName(), elem)
+		}
+		if fd.IsList() || fd.IsMap() {
+			return "", fmt.Errorf("field %s of message %s is repeated, which is not supported", elem, md.FullName())
+		}
+		v := msg.Get(fd)
+		if i == len(path)-1 {
+			return formatValue(fd, v)
+		}
+		if fd.Message() == nil {
+			return "", fmt.Errorf("field %s of message %s is not a message", elem, md.FullName())
+		}
+		msg = v.Message()
+	}
+	return "", fmt.Errorf("empty protobuf field path")
+}
+
+func formatValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) (string, error) {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name()), nil
+		}
+		return fmt.Sprint(v.Enum()), nil
+	case protoreflect.BytesKind:
+		return string(v.Bytes()), nil
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return "", fmt.Errorf("field %s is a message, expected a scalar field", fd.FullName())
+	}
+	return v.String(), nil
+}
@@ -0,0 +1,122 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package protobuf
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	api "kmodules.xyz/prober/api"
+	httpprobe "kmodules.xyz/prober/probe/http"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestAssertion(t *testing.T) {
+	health, err := proto.Marshal(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING})
+	require.NoError(t, err)
+	file, err := proto.Marshal(&descriptorpb.FileDescriptorProto{
+		Name:    proto.String("probe.proto"),
+		Options: &descriptorpb.FileOptions{JavaPackage: proto.String("xyz.kmodules.probe")},
+	})
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		switch r.URL.Path {
+		case "/health":
+			_, _ = w.Write(health)
+		case "/file":
+			_, _ = w.Write(file)
+		default:
+			_, _ = w.Write([]byte("\xff\xff\xff"))
+		}
+	}))
+	defer server.Close()
+
+	testCases := map[string]struct {
+		path    string
+		message string
+		field   string
+		value   string
+		result  api.Result
+		output  string
+	}{
+		"enum by name": {
+			"/health", "grpc.health.v1.HealthCheckResponse", "status", "SERVING",
+			api.Success, "",
+		},
+		"enum mismatch": {
+			"/health", "grpc.health.v1.HealthCheckResponse", "status", "NOT_SERVING",
+			api.Failure, `protobuf field status of grpc.health.v1.HealthCheckResponse is "SERVING", expected "NOT_SERVING"`,
+		},
+		"nested field": {
+			"/file", "google.protobuf.FileDescriptorProto", "options.java_package", "xyz.kmodules.probe",
+			api.Success, "",
+		},
+		"unset field has its default": {
+			"/file", "google.protobuf.FileDescriptorProto", "options.optimize_for", "SPEED",
+			api.Success, "",
+		},
+		"not a protobuf message": {
+			"/garbage", "grpc.health.v1.HealthCheckResponse", "status", "SERVING",
+			api.Failure, "failed to decode response body as grpc.health.v1.HealthCheckResponse",
+		},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			assertion, err := NewAssertion(tt.message, tt.field, tt.value)
+			require.NoError(t, err)
+			target, err := url.Parse(server.URL + tt.path)
+			require.NoError(t, err)
+			prober := httpprobe.NewGetWithOptions(nil, false, httpprobe.Options{
+				BodyAssertions: []httpprobe.BodyAssertion{assertion},
+			})
+			result, output, err := prober.Probe(target, nil, wait.ForeverTestTimeout)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.result, result)
+			assert.Contains(t, output, tt.output)
+		})
+	}
+}
+
+func TestNewAssertion(t *testing.T) {
+	testCases := map[string]struct {
+		message string
+		field   string
+		err     string
+	}{
+		"unknown message": {"example.v1.Missing", "status", "failed to find protobuf message example.v1.Missing"},
+		"unknown field":   {"grpc.health.v1.HealthCheckResponse", "state", "message grpc.health.v1.HealthCheckResponse has no field state"},
+		"repeated field":  {"google.protobuf.FileDescriptorProto", "dependency", "field dependency of message google.protobuf.FileDescriptorProto is repeated"},
+		"message field":   {"google.protobuf.FileDescriptorProto", "options", "expected a scalar field"},
+		"through scalar":  {"google.protobuf.FileDescriptorProto", "name.length", "field name of message google.protobuf.FileDescriptorProto is not a message"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewAssertion(tt.message, tt.field, "")
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
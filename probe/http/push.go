@@ -0,0 +1,115 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+
+	"golang.org/x/net/http2"
+)
+
+// errServerPush is returned by probes with ForbidServerPush when the server pushed a stream.
+var errServerPush = errors.New("server push received")
+
+// pushTransport sends requests over HTTP/2 and counts the PUSH_PROMISE frames the server sends.
+// Like every Go client, it disables push in its SETTINGS, so the HTTP/2 transport fails the connection
+// with PROTOCOL_ERROR when a server pushes anyway; the count tells that failure apart from others.
+type pushTransport struct {
+	h2     *http2.Transport
+	pushes atomic.Int32
+}
+
+// newPushTransport returns the transport of a ForbidServerPush probe. It dials through transport,
+// so that DialAddress, LocalPorts and UnixSocket still apply, and uses its TLS configuration.
+func newPushTransport(transport *http.Transport) *pushTransport {
+	t := &pushTransport{}
+	t.h2 = &http2.Transport{
+		TLSClientConfig: transport.TLSClientConfig,
+		DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+			dial := transport.DialContext
+			if dial == nil {
+				dial = (&net.Dialer{}).DialContext
+			}
+			conn, err := dial(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			tlsConn := tls.Client(conn, cfg)
+			if err := tlsConn.HandshakeContext(ctx); err != nil {
+				conn.Close()
+				return nil, err
+			}
+			return &pushConn{Conn: tlsConn, pushes: &t.pushes}, nil
+		},
+	}
+	return t
+}
+
+func (t *pushTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := t.h2.RoundTrip(req)
+	if n := t.pushes.Load(); n > 0 {
+		if err == nil {
+			res.Body.Close()
+		}
+		return nil, fmt.Errorf("%w: the server sent %d PUSH_PROMISE frame(s) although the probe disabled push", errServerPush, n)
+	}
+	return res, err
+}
+
+// pushConn counts the PUSH_PROMISE frames read from an HTTP/2 connection by following the frame headers.
+type pushConn struct {
+	net.Conn
+	pushes *atomic.Int32
+
+	header    [9]byte
+	read      int // bytes of the current frame header read so far
+	remaining int // bytes of the current frame payload not read yet
+}
+
+func (c *pushConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.scan(p[:n])
+	return n, err
+}
+
+func (c *pushConn) scan(b []byte) {
+	for len(b) > 0 {
+		if c.remaining > 0 {
+			n := min(c.remaining, len(b))
+			c.remaining -= n
+			b = b[n:]
+			continue
+		}
+		n := copy(c.header[c.read:], b)
+		c.read += n
+		b = b[n:]
+		if c.read < len(c.header) {
+			return
+		}
+		c.read = 0
+		c.remaining = int(c.header[0])<<16 | int(c.header[1])<<8 | int(c.header[2])
+		if http2.FrameType(c.header[3]) == http2.FramePushPromise {
+			c.pushes.Add(1)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+const (
+	// maxRecordedBodyLength is the length of the response body written by the recorder.
+	maxRecordedBodyLength = 1 << 10 // 1KB
+	redacted              = "<redacted>"
+)
+
+// sensitiveHeaders are the headers whose values are never recorded.
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// record writes the transcript of a probe exchange to opts.Recorder. Either res or err is set.
+// The transcript is written with a single Write, so that concurrent probes sharing the writer
+// are not interleaved by writers that write atomically.
+func (opts *Options) record(req *http.Request, res *http.Response, body []byte, err error) {
+	if opts.Recorder == nil {
+		return
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "* %s probe of %s\n", time.Now().UTC().Format(time.RFC3339Nano), req.URL.Redacted())
+	fmt.Fprintf(&buf, "> %s %s %s\n", req.Method, req.URL.RequestURI(), req.Proto)
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	fmt.Fprintf(&buf, "> Host: %s\n", host)
+	opts.recordHeaders(&buf, "> ", req.Header)
+	buf.WriteString(">\n")
+	if err != nil {
+		fmt.Fprintf(&buf, "! %v\n\n", err)
+	} else {
+		fmt.Fprintf(&buf, "< %s %s\n", res.Proto, res.Status)
+		opts.recordHeaders(&buf, "< ", res.Header)
+		buf.WriteString("<\n")
+		if len(body) > maxRecordedBodyLength {
+			buf.Write(body[:maxRecordedBodyLength])
+			fmt.Fprintf(&buf, "\n[body truncated, %d of %d bytes recorded]", maxRecordedBodyLength, len(body))
+		} else {
+			buf.Write(body)
+		}
+		buf.WriteString("\n\n")
+	}
+	if _, err := opts.Recorder.Write(buf.Bytes()); err != nil {
+		klog.Errorf("Failed to record probe of %s: %v", req.URL.Redacted(), err)
+	}
+}
+
+// isSensitiveHeader returns whether the values of the header named k are redacted.
+func (opts *Options) isSensitiveHeader(k string) bool {
+	k = http.CanonicalHeaderKey(k)
+	if sensitiveHeaders[k] || (opts.Signer != nil && http.CanonicalHeaderKey(opts.Signer.header()) == k) {
+		return true
+	}
+	for _, h := range opts.sensitiveHeaders {
+		if http.CanonicalHeaderKey(h) == k {
+			return true
+		}
+	}
+	return false
+}
+
+// redactHeaders returns a copy of header for logging, with the values of sensitive headers redacted.
+func (opts *Options) redactHeaders(header http.Header) http.Header {
+	out := make(http.Header, len(header))
+	for k, values := range header {
+		if opts.isSensitiveHeader(k) {
+			values = []string{redacted}
+		}
+		out[k] = values
+	}
+	return out
+}
+
+func (opts *Options) recordHeaders(buf *bytes.Buffer, prefix string, header http.Header) {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		secret := opts.isSensitiveHeader(k)
+		for _, v := range header[k] {
+			if secret {
+				v = redacted
+			}
+			fmt.Fprintf(buf, "%s%s: %s\n", prefix, k, v)
+		}
+	}
+}
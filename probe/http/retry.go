@@ -0,0 +1,127 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+
+	"k8s.io/klog/v2"
+)
+
+// RetryPolicy retries HTTP probes that fail, e.g. to ride out a refused connection while a target restarts.
+type RetryPolicy struct {
+	// Attempts is the maximum number of attempts, including the first one. Less than 2 disables retries.
+	Attempts int
+	// Interval is the pause between attempts.
+	// +optional
+	Interval time.Duration
+	// RetryOn are the failure reasons that are retried, e.g. ReasonConnectionFailed but not
+	// ReasonAuthenticationFailed, which does not fix itself. A failure for another reason is returned
+	// right away. Empty retries every failure.
+	// +optional
+	RetryOn []api.FailureReason
+	// MaxRetryAfter bounds the pause after a 429 Too Many Requests response, which is the longer of Interval
+	// and the delay requested by its Retry-After header. Defaults to DefaultMaxRetryAfter.
+	// +optional
+	MaxRetryAfter time.Duration
+}
+
+// DefaultMaxRetryAfter is the default bound of the pause requested by a Retry-After header.
+const DefaultMaxRetryAfter = 30 * time.Second
+
+// retryable reports whether the probe that resulted in d is attempted again.
+func (p *RetryPolicy) retryable(d Details) bool {
+	if d.Result != api.Failure {
+		return false
+	}
+	if len(p.RetryOn) == 0 {
+		return true
+	}
+	for _, reason := range p.RetryOn {
+		if reason == d.Reason {
+			return true
+		}
+	}
+	return false
+}
+
+// retry runs probe until it does not fail with a retryable reason, or opts.Retry runs out of attempts.
+// The attempts and the pauses between them share the timeout of client as their budget: every attempt
+// gets the time that is left, and no attempt is made once the pause before it would use up the rest.
+func (opts *Options) retry(client *http.Client, probe func() (Details, error)) (Details, error) {
+	timeout, start := client.Timeout, time.Now()
+	d, err := probe()
+	if opts.Retry == nil {
+		return d, err
+	}
+	defer func() { client.Timeout = timeout }()
+
+	for attempt := 2; attempt <= opts.Retry.Attempts && opts.Retry.retryable(d); attempt++ {
+		pause := opts.Retry.pause(d)
+		if timeout > 0 && timeout-time.Since(start) <= pause {
+			klog.V(5).Infof("Not retrying probe that failed with %s, the timeout of %v runs out before attempt %d", d.Reason, timeout, attempt)
+			break
+		}
+		klog.V(5).Infof("Retrying probe that failed with %s, attempt %d of %d", d.Reason, attempt, opts.Retry.Attempts)
+		time.Sleep(pause)
+		if timeout > 0 {
+			// a zero or negative client.Timeout would mean no timeout at all
+			remaining := timeout - time.Since(start)
+			if remaining <= 0 {
+				klog.V(5).Infof("Not retrying probe that failed with %s, the timeout of %v ran out during the pause", d.Reason, timeout)
+				break
+			}
+			client.Timeout = remaining
+		}
+		d, err = probe()
+	}
+	return d, err
+}
+
+// pause returns the time to wait before attempting the probe that resulted in d again.
+func (p *RetryPolicy) pause(d Details) time.Duration {
+	max := p.MaxRetryAfter
+	if max <= 0 {
+		max = DefaultMaxRetryAfter
+	}
+	if d.RetryAfter > p.Interval && p.Interval < max {
+		return min(d.RetryAfter, max)
+	}
+	return p.Interval
+}
+
+// retryAfter parses the value of a Retry-After header, either a number of seconds or an HTTP date,
+// into the delay from now. Invalid values and dates in the past are zero.
+func retryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
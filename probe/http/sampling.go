@@ -0,0 +1,114 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+)
+
+const defaultLatencyPercentile = 95
+
+func (opts *Options) latencyPercentile() float64 {
+	if opts.LatencyPercentile <= 0 || opts.LatencyPercentile > 100 {
+		return defaultLatencyPercentile
+	}
+	return opts.LatencyPercentile
+}
+
+// sample runs opts.WarmupRequests throwaway requests with probe, then runs probe opts.Samples times, retried
+// with opts.Retry, and checks the latency percentile of the samples against opts.MaxLatency. The warmups and
+// the samples share the timeout of client as their budget. The first sample that does not pass is returned as is.
+func (opts *Options) sample(client *http.Client, probe func() (Details, error)) (Details, error) {
+	measure := func() (Details, error) {
+		return opts.retry(client, probe)
+	}
+	if opts.Samples <= 1 && opts.MaxLatency <= 0 && opts.WarmupRequests <= 0 {
+		return measure()
+	}
+	n := opts.Samples
+	if n < 1 {
+		n = 1
+	}
+	timeout, start := client.Timeout, time.Now()
+	outOfTime := func() bool {
+		if timeout <= 0 {
+			return false
+		}
+		remaining := timeout - time.Since(start)
+		client.Timeout = remaining
+		return remaining <= 0
+	}
+	timedOut := func(msg string) Details {
+		result, reason := opts.errorResult(context.DeadlineExceeded, api.ReasonConnectionFailed)
+		return Details{Result: result, Output: msg, Reason: reason}
+	}
+
+	for i := 0; i < opts.WarmupRequests; i++ {
+		if outOfTime() {
+			return timedOut(fmt.Sprintf("warmup ran out of time after %d of %d requests", i, opts.WarmupRequests)), nil
+		}
+		// the outcome of a warmup does not matter, only the connection it leaves open
+		_, _ = probe()
+	}
+
+	latencies := make([]time.Duration, 0, n)
+	var d Details
+	for i := 0; i < n; i++ {
+		if outOfTime() {
+			return timedOut(fmt.Sprintf("latency sampling ran out of time after %d of %d samples", i, n)), nil
+		}
+		sampleStart := time.Now()
+		var err error
+		d, err = measure()
+		if err != nil || (d.Result != api.Success && d.Result != api.Warning) {
+			return d, err
+		}
+		latencies = append(latencies, time.Since(sampleStart))
+	}
+
+	if n == 1 && opts.MaxLatency <= 0 {
+		d.Output += fmt.Sprintf("\n[latency %v]", latencies[0])
+		return d, nil
+	}
+	p := opts.latencyPercentile()
+	latency := percentile(latencies, p)
+	if opts.MaxLatency > 0 && latency > opts.MaxLatency {
+		d.Result, d.Reason = api.Failure, api.ReasonAssertionFailed
+		d.Output = fmt.Sprintf("p%g latency %v over %d samples exceeds %v", p, latency, n, opts.MaxLatency)
+		return d, nil
+	}
+	d.Output += fmt.Sprintf("\n[p%g latency %v over %d samples]", p, latency, n)
+	return d, nil
+}
+
+// percentile returns the p-th percentile of latencies by the nearest-rank method.
+func percentile(latencies []time.Duration, p float64) time.Duration {
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
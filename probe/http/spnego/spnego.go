@@ -0,0 +1,82 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package spnego authenticates HTTP probes with Kerberos via SPNEGO (Authorization: Negotiate).
+// It is a separate package so that users of the HTTP probers do not depend on the Kerberos libraries.
+package spnego
+
+import (
+	"fmt"
+	"net/http"
+
+	httpprobe "kmodules.xyz/prober/probe/http"
+
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+)
+
+// Authenticator is a httpprobe.Authenticator that sets a SPNEGO token on every probe request.
+type Authenticator struct {
+	client *client.Client
+	spn    string
+}
+
+var _ httpprobe.Authenticator = &Authenticator{}
+
+// NewWithKeytab creates an Authenticator that logs in as username@realm with the keys in keytabFile.
+// krb5ConfFile is the path of the krb5.conf to use. spn is the service principal of the probed
+// endpoint, e.g. "HTTP/api.example.com"; if empty, it is derived from the host of each request.
+func NewWithKeytab(username, realm, keytabFile, krb5ConfFile, spn string) (*Authenticator, error) {
+	cfg, err := config.Load(krb5ConfFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load krb5 config %s: %v", krb5ConfFile, err)
+	}
+	kt, err := keytab.Load(keytabFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load keytab %s: %v", keytabFile, err)
+	}
+	return &Authenticator{
+		client: client.NewWithKeytab(username, realm, kt, cfg, client.DisablePAFXFAST(true)),
+		spn:    spn,
+	}, nil
+}
+
+// NewWithCCache creates an Authenticator that uses the tickets of the credentials cache in ccacheFile,
+// e.g. as populated by kinit. krb5ConfFile and spn are as for NewWithKeytab.
+func NewWithCCache(ccacheFile, krb5ConfFile, spn string) (*Authenticator, error) {
+	cfg, err := config.Load(krb5ConfFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load krb5 config %s: %v", krb5ConfFile, err)
+	}
+	cc, err := credentials.LoadCCache(ccacheFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load credentials cache %s: %v", ccacheFile, err)
+	}
+	cl, err := client.NewFromCCache(cc, cfg, client.DisablePAFXFAST(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kerberos client from credentials cache %s: %v", ccacheFile, err)
+	}
+	return &Authenticator{client: cl, spn: spn}, nil
+}
+
+// Authenticate obtains a service ticket, logging in first if needed, and sets it as the
+// Authorization header of req.
+func (a *Authenticator) Authenticate(req *http.Request) error {
+	return spnego.SetSPNEGOHeader(a.client, req, a.spn)
+}
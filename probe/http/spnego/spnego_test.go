@@ -0,0 +1,132 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spnego
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+	httpprobe "kmodules.xyz/prober/probe/http"
+
+	"github.com/jcmturner/gokrb5/v8/iana/etypeID"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// writeKrb5Conf writes a krb5.conf for the EXAMPLE.COM realm served by the KDC at kdc.
+func writeKrb5Conf(t *testing.T, kdc string) string {
+	path := filepath.Join(t.TempDir(), "krb5.conf")
+	conf := fmt.Sprintf(`[libdefaults]
+  default_realm = EXAMPLE.COM
+  dns_lookup_kdc = false
+  dns_lookup_realm = false
+  udp_preference_limit = 1
+
+[realms]
+  EXAMPLE.COM = {
+    kdc = %s
+  }
+`, kdc)
+	require.NoError(t, os.WriteFile(path, []byte(conf), 0o600))
+	return path
+}
+
+// writeKeytab writes a keytab with a key of the probe@EXAMPLE.COM principal.
+func writeKeytab(t *testing.T) string {
+	kt := keytab.New()
+	require.NoError(t, kt.AddEntry("probe", "EXAMPLE.COM", "secret", time.Now(), 1, etypeID.AES256_CTS_HMAC_SHA1_96))
+	b, err := kt.Marshal()
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "probe.keytab")
+	require.NoError(t, os.WriteFile(path, b, 0o600))
+	return path
+}
+
+func TestNew_MissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	missing := filepath.Join(dir, "missing")
+	conf := writeKrb5Conf(t, "127.0.0.1:88")
+	kt := writeKeytab(t)
+
+	testCases := map[string]struct {
+		create func() (*Authenticator, error)
+		err    string
+	}{
+		"keytab: missing krb5.conf": {
+			func() (*Authenticator, error) { return NewWithKeytab("probe", "EXAMPLE.COM", kt, missing, "") },
+			"failed to load krb5 config " + missing,
+		},
+		"keytab: missing keytab": {
+			func() (*Authenticator, error) { return NewWithKeytab("probe", "EXAMPLE.COM", missing, conf, "") },
+			"failed to load keytab " + missing,
+		},
+		"ccache: missing krb5.conf": {
+			func() (*Authenticator, error) { return NewWithCCache(missing, missing, "") },
+			"failed to load krb5 config " + missing,
+		},
+		"ccache: missing ccache": {
+			func() (*Authenticator, error) { return NewWithCCache(missing, conf, "") },
+			"failed to load credentials cache " + missing,
+		},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			a, err := tt.create()
+			assert.Nil(t, a)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestAuthenticate_KDCUnreachable(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// nothing listens on the KDC address, so logging in fails
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	kdc := l.Addr().String()
+	l.Close()
+
+	a, err := NewWithKeytab("probe", "EXAMPLE.COM", writeKeytab(t), writeKrb5Conf(t, kdc), "HTTP/localhost")
+	require.NoError(t, err)
+	prober := httpprobe.NewGetWithOptions(nil, false, httpprobe.Options{Authenticator: a}).(httpprobe.DetailedGetProber)
+	target, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	d, err := prober.ProbeDetailed(target, nil, wait.ForeverTestTimeout)
+	assert.NoError(t, err)
+	assert.Equal(t, api.Failure, d.Result)
+	assert.Equal(t, api.ReasonAuthenticationFailed, d.Reason)
+	assert.Contains(t, d.Output, "authentication failed")
+	assert.Zero(t, atomic.LoadInt32(&requests))
+}
@@ -0,0 +1,83 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timings is the latency breakdown of an HTTP probe. Phases that did not happen, such as DNS for
+// an IP address or TLS for plain HTTP, are zero. When redirects are followed, the phases of the
+// last connection are reported.
+type Timings struct {
+	DNS          time.Duration
+	Connect      time.Duration
+	TLSHandshake time.Duration
+	// TimeToFirstByte is measured from the start of the probe to the first byte of the response.
+	TimeToFirstByte time.Duration
+}
+
+// phaseTracer records the phase timings of a request via httptrace.
+// The hooks may be called from different goroutines.
+type phaseTracer struct {
+	mu                            sync.Mutex
+	start                         time.Time
+	dnsStart, connStart, tlsStart time.Time
+	timings                       Timings
+}
+
+func (t *phaseTracer) mark(at *time.Time) func() {
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		*at = time.Now()
+	}
+}
+
+func (t *phaseTracer) since(from *time.Time, d *time.Duration) func() {
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		*d = time.Since(*from)
+	}
+}
+
+// trace wraps req with a context that reports its phases to t.
+func (t *phaseTracer) trace(req *http.Request) *http.Request {
+	t.start = time.Now()
+	trace := &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { t.mark(&t.dnsStart)() },
+		DNSDone:              func(httptrace.DNSDoneInfo) { t.since(&t.dnsStart, &t.timings.DNS)() },
+		ConnectStart:         func(string, string) { t.mark(&t.connStart)() },
+		ConnectDone:          func(string, string, error) { t.since(&t.connStart, &t.timings.Connect)() },
+		TLSHandshakeStart:    t.mark(&t.tlsStart),
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { t.since(&t.tlsStart, &t.timings.TLSHandshake)() },
+		GotFirstResponseByte: t.since(&t.start, &t.timings.TimeToFirstByte),
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
+
+func (t *phaseTracer) result() *Timings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	timings := t.timings
+	return &timings
+}
@@ -0,0 +1,61 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+)
+
+// holdOpen keeps the connection upgraded by res open for MinOpenDuration, discarding what the server sends,
+// and fails the probe if the server closes it before, or deadline comes first. The client does not time out
+// reads of upgraded connections, so deadline carries the probe timeout. The caller closes the connection.
+func (opts *Options) holdOpen(res *http.Response, d Details, deadline time.Time) Details {
+	start := time.Now()
+	closed := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(io.Discard, res.Body)
+		closed <- err
+	}()
+	open := time.NewTimer(opts.MinOpenDuration)
+	defer open.Stop()
+	var timedOut <-chan time.Time
+	if !deadline.IsZero() {
+		timeout := time.NewTimer(time.Until(deadline))
+		defer timeout.Stop()
+		timedOut = timeout.C
+	}
+
+	select {
+	case <-open.C:
+		d.Result, d.Output = api.Success, fmt.Sprintf("connection stayed open for %v", time.Since(start).Round(time.Millisecond))
+	case <-timedOut:
+		d.Result, d.Reason = opts.errorResult(context.DeadlineExceeded, api.ReasonConnectionFailed)
+		d.Output = fmt.Sprintf("probe timed out after the connection stayed open for %v, expected at least %v",
+			time.Since(start).Round(time.Millisecond), opts.MinOpenDuration)
+	case <-closed:
+		d.Result, d.Reason = api.Failure, api.ReasonAssertionFailed
+		d.Output = fmt.Sprintf("connection closed after %v, expected to stay open for at least %v",
+			time.Since(start).Round(time.Millisecond), opts.MinOpenDuration)
+	}
+	return d
+}
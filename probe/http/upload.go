@@ -0,0 +1,77 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// uploadPattern is repeated to fill the body of upload probes.
+const uploadPattern = "kmodules.xyz/prober upload probe\n"
+
+// errUploadSigned is returned for probes with both UploadSize and Signer, as the upload is not held in memory to be signed.
+var errUploadSigned = errors.New("UploadSize cannot be combined with Signer")
+
+// patternReader endlessly repeats uploadPattern.
+type patternReader struct {
+	off int
+}
+
+func (r *patternReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = uploadPattern[r.off]
+		r.off = (r.off + 1) % len(uploadPattern)
+	}
+	return len(p), nil
+}
+
+// uploadBody returns a body of UploadSize bytes, generated while it is sent.
+func (opts *Options) uploadBody() io.Reader {
+	return io.LimitReader(&patternReader{}, opts.UploadSize)
+}
+
+// verifyUploadAck checks that the JSON response body acknowledges all UploadSize bytes at ExpectUploadAckPath.
+func (opts *Options) verifyUploadAck(body []byte) error {
+	if opts.UploadSize <= 0 || opts.ExpectUploadAckPath == "" {
+		return nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	// keep large byte counts from being printed in exponent notation
+	dec.UseNumber()
+	var data interface{}
+	if err := dec.Decode(&data); err != nil {
+		return fmt.Errorf("failed to parse response body as JSON: %v", err)
+	}
+	got, err := evalJSONPath(opts.ExpectUploadAckPath, data)
+	if err != nil {
+		return err
+	}
+	acked, err := strconv.ParseInt(strings.TrimSpace(got), 10, 64)
+	if err != nil {
+		return fmt.Errorf("acknowledged byte count %s is %q, not a number", opts.ExpectUploadAckPath, got)
+	}
+	if acked != opts.UploadSize {
+		return fmt.Errorf("upload acknowledged %d bytes, expected %d bytes", acked, opts.UploadSize)
+	}
+	return nil
+}
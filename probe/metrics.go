@@ -0,0 +1,106 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+
+	"k8s.io/klog/v2"
+)
+
+// ProbeEvent describes a finished probe. It is passed to Prober.MetricsHook.
+type ProbeEvent struct {
+	Kind   string
+	Target string
+	Result api.Result
+	// Duration is the time the probe took, including the wait for a concurrency slot.
+	Duration time.Duration
+	Err      error
+	// Labels are the labels attached to the context of the probe with WithLabels.
+	Labels map[string]string
+	// Skipped reports that the probe was not run because the pod does not meet Prober.Gate.
+	// Its Result is Success.
+	Skipped bool
+}
+
+type labelsKey struct{}
+
+// WithLabels returns a context that attaches labels, e.g. cluster or tenant, to the probes run with it.
+// The labels are passed to Prober.MetricsHook and included in the log output of the probes.
+// They are merged with labels already attached to ctx, overriding those with the same key.
+func WithLabels(ctx context.Context, labels map[string]string) context.Context {
+	parent := LabelsFrom(ctx)
+	merged := make(map[string]string, len(parent)+len(labels))
+	for k, v := range parent {
+		merged[k] = v
+	}
+	for k, v := range labels {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, labelsKey{}, merged)
+}
+
+// LabelsFrom returns the labels attached to ctx with WithLabels. The returned map must not be modified.
+func LabelsFrom(ctx context.Context) map[string]string {
+	labels, _ := ctx.Value(labelsKey{}).(map[string]string)
+	return labels
+}
+
+// observe logs a finished probe and passes it to the metrics hook.
+func (pb *Prober) observe(ctx context.Context, kind string, target api.Target, result api.Result, err error, duration time.Duration) {
+	pb.notify(ctx, target, ProbeEvent{Kind: kind, Result: result, Duration: duration, Err: err})
+}
+
+// observeSkipped is observe for a probe that was not run because the pod does not meet Prober.Gate.
+func (pb *Prober) observeSkipped(ctx context.Context, kind string, target api.Target) {
+	pb.notify(ctx, target, ProbeEvent{Kind: kind, Result: api.Success, Skipped: true})
+}
+
+// notify logs ev and passes it to MetricsHook, filling in its target and labels.
+func (pb *Prober) notify(ctx context.Context, target api.Target, ev ProbeEvent) {
+	logger := klog.V(5)
+	if !logger.Enabled() && pb.MetricsHook == nil {
+		return
+	}
+	ev.Labels = LabelsFrom(ctx)
+	ev.Target = describeTarget(target)
+	if logger.Enabled() {
+		kv := []interface{}{"kind", ev.Kind, "target", ev.Target, "result", ev.Result, "duration", ev.Duration}
+		keys := make([]string, 0, len(ev.Labels))
+		for k := range ev.Labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			kv = append(kv, k, ev.Labels[k])
+		}
+		if ev.Skipped {
+			kv = append(kv, "skipped", GateNotMet)
+		}
+		if ev.Err != nil {
+			kv = append(kv, "err", ev.Err)
+		}
+		logger.InfoS("Probe finished", kv...)
+	}
+	if pb.MetricsHook != nil {
+		pb.MetricsHook(ev)
+	}
+}
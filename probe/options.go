@@ -0,0 +1,126 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"crypto/tls"
+	"time"
+
+	dnsprobe "kmodules.xyz/prober/probe/dns"
+	execprobe "kmodules.xyz/prober/probe/exec"
+	grpcprobe "kmodules.xyz/prober/probe/grpc"
+	httpprobe "kmodules.xyz/prober/probe/http"
+	tcpprobe "kmodules.xyz/prober/probe/tcp"
+)
+
+// Option configures a Prober created by NewProber.
+type Option func(*Prober)
+
+// WithHTTPOptions configures the HTTP GET and POST probers. The options are applied after the defaults of
+// NewProber, skipping TLS verification and not following redirects to other hosts, so they can override them.
+func WithHTTPOptions(options ...httpprobe.Option) Option {
+	return func(pb *Prober) {
+		opts := append(defaultHTTPOptions(), options...)
+		pb.HttpGet = httpprobe.NewGet(opts...)
+		pb.HttpPost = httpprobe.NewPost(opts...)
+	}
+}
+
+// WithTCPOptions configures the TCP prober.
+func WithTCPOptions(opts tcpprobe.Options) Option {
+	return func(pb *Prober) {
+		pb.Tcp = tcpprobe.NewWithOptions(opts)
+	}
+}
+
+// WithExecOptions configures the exec prober.
+func WithExecOptions(opts execprobe.Options) Option {
+	return func(pb *Prober) {
+		pb.Exec = execprobe.NewWithOptions(opts)
+	}
+}
+
+// WithGRPCOptions configures the gRPC prober.
+func WithGRPCOptions(opts grpcprobe.Options) Option {
+	return func(pb *Prober) {
+		pb.Grpc = grpcprobe.NewWithOptions(opts)
+	}
+}
+
+// WithDNSOptions configures the DNS prober.
+func WithDNSOptions(opts dnsprobe.Options) Option {
+	return func(pb *Prober) {
+		pb.DNS = dnsprobe.NewWithOptions(opts)
+	}
+}
+
+// WithMaxConcurrentProbesPerTarget sets Prober.MaxConcurrentProbesPerTarget and Prober.LimitMode.
+func WithMaxConcurrentProbesPerTarget(n int, mode LimitMode) Option {
+	return func(pb *Prober) {
+		pb.MaxConcurrentProbesPerTarget = n
+		pb.LimitMode = mode
+	}
+}
+
+// WithHistorySize sets Prober.HistorySize.
+func WithHistorySize(size int) Option {
+	return func(pb *Prober) {
+		pb.HistorySize = size
+	}
+}
+
+// WithMetricsHook sets Prober.MetricsHook.
+func WithMetricsHook(hook func(ProbeEvent)) Option {
+	return func(pb *Prober) {
+		pb.MetricsHook = hook
+	}
+}
+
+// WithEventRecorder sets Prober.EventRecorder.
+func WithEventRecorder(recorder EventRecorder) Option {
+	return func(pb *Prober) {
+		pb.EventRecorder = recorder
+	}
+}
+
+// WithDefaultTimeout sets Prober.DefaultTimeout.
+func WithDefaultTimeout(timeout time.Duration) Option {
+	return func(pb *Prober) {
+		pb.DefaultTimeout = timeout
+	}
+}
+
+// WithRequestIDHeader sets Prober.RequestIDHeader.
+func WithRequestIDHeader(name string) Option {
+	return func(pb *Prober) {
+		pb.RequestIDHeader = name
+	}
+}
+
+// WithGate sets Prober.Gate to the annotation name, and value if not empty.
+func WithGate(name, value string) Option {
+	return func(pb *Prober) {
+		pb.Gate = &AnnotationGate{Name: name, Value: value}
+	}
+}
+
+func defaultHTTPOptions() []httpprobe.Option {
+	return []httpprobe.Option{
+		httpprobe.WithTLSConfig(&tls.Config{InsecureSkipVerify: true}),
+		httpprobe.WithFollowNonLocalRedirects(false),
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+	api_v1 "kmodules.xyz/prober/api/v1"
+
+	core "k8s.io/api/core/v1"
+)
+
+// ProbePlan is a set of named probes that are run together against a pod, e.g. a readiness and
+// a liveness probe for a combined health view.
+type ProbePlan struct {
+	// Probes are the probes of the plan by name, e.g. "readiness" and "liveness". Nil handlers are skipped.
+	Probes map[string]*api_v1.Handler
+	// Parallel runs the probes concurrently. By default, they run one after another in the order of their names.
+	Parallel bool
+}
+
+// PlanResult is the outcome of a single probe of a ProbePlan.
+type PlanResult struct {
+	// Result is the result of the probe. A probe that can not be resolved against the pod is Unknown.
+	Result api.Result
+	// Err describes why the probe did not pass, as returned by Prober.RunProbe. It is nil if it passed.
+	Err error
+}
+
+// Run runs the probes of the plan against pod with pb, each bounded by timeout, and returns their
+// outcomes by name. A probe that does not pass does not stop the others.
+func (plan ProbePlan) Run(ctx context.Context, pb *Prober, pod *core.Pod, timeout time.Duration) map[string]PlanResult {
+	names := make([]string, 0, len(plan.Probes))
+	for name, handler := range plan.Probes {
+		if handler != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	results := make(map[string]PlanResult, len(names))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, name := range names {
+		run := func(name string) {
+			r := runPlanProbe(ctx, pb, plan.Probes[name], pod, timeout)
+			mu.Lock()
+			defer mu.Unlock()
+			results[name] = r
+		}
+		if !plan.Parallel {
+			run(name)
+			continue
+		}
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			run(name)
+		}(name)
+	}
+	wg.Wait()
+	return results
+}
+
+func runPlanProbe(ctx context.Context, pb *Prober, handler *api_v1.Handler, pod *core.Pod, timeout time.Duration) PlanResult {
+	cp, err := pb.Compile(handler, pod)
+	if err != nil {
+		return PlanResult{Result: api.Unknown, Err: err}
+	}
+	result, err := cp.run(ctx, timeout)
+	return PlanResult{Result: result, Err: err}
+}
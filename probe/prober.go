@@ -23,12 +23,13 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
-	"strings"
 	"time"
 
 	api "kmodules.xyz/prober/api"
 	api_v1 "kmodules.xyz/prober/api/v1"
+	dnsprobe "kmodules.xyz/prober/probe/dns"
 	execprobe "kmodules.xyz/prober/probe/exec"
+	grpcprobe "kmodules.xyz/prober/probe/grpc"
 	httpprobe "kmodules.xyz/prober/probe/http"
 	tcpprobe "kmodules.xyz/prober/probe/tcp"
 
@@ -39,28 +40,103 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"k8s.io/klog/v2"
 )
 
+const defaultClusterDomain = "cluster.local"
+
+// ProberInterface is the set of methods of Prober used to run probes. Code that depends on it
+// instead of *Prober can be tested with the fake.Prober from the fake subpackage.
+type ProberInterface interface {
+	RunProbe(probes *api_v1.Handler, pod *core.Pod, timeout time.Duration) error
+	RunProbeContext(ctx context.Context, probes *api_v1.Handler, pod *core.Pod, timeout time.Duration) error
+	RunPodProbes(pod *core.Pod, handlers map[string]*api_v1.Handler, timeout time.Duration) map[string]error
+}
+
+var _ ProberInterface = &Prober{}
+
 type Prober struct {
 	HttpGet  httpprobe.GetProber
 	HttpPost httpprobe.PostProber
 	Tcp      tcpprobe.Prober
 	Exec     execprobe.Prober
 	Config   *rest.Config
+	// Grpc runs probes of KindGRPC. Handler has no gRPC action, so these are only run through RunKind.
+	Grpc grpcprobe.Prober
+	// DNS runs probes of KindDNS, resolving Target.Host. Like Grpc, it is only run through RunKind.
+	DNS dnsprobe.Prober
+
+	// DefaultTimeout bounds the probes run with a zero or negative timeout, e.g. by callers that use one timeout
+	// everywhere. A positive timeout passed to RunProbe and the other Run methods, or set in Target.Timeout for
+	// RunKind, takes precedence. Zero defaults to api.DefaultProbeTimeout. A deadline of the context still
+	// shortens the timeout.
+	DefaultTimeout time.Duration
+
+	// MaxConcurrentProbesPerTarget bounds the number of probes this Prober runs
+	// concurrently against the same host:port. Zero or negative means unlimited.
+	MaxConcurrentProbesPerTarget int
+	// LimitMode selects whether probes over the limit wait for a free slot (the default)
+	// or fail fast with an Unknown result.
+	LimitMode LimitMode
+	// HistorySize is the number of recent probe results retained for RecentResults.
+	// Zero disables the history.
+	HistorySize int
+	// UsePodHostname makes HTTP probes without an explicit host address the pod by its
+	// fully qualified hostname (<hostname>.<subdomain>.<namespace>.svc.<cluster-domain>),
+	// so that the Host header and TLS SNI match per pod DNS, e.g. for StatefulSet pods.
+	// The name must be resolvable by the prober. Pods without spec.hostname and spec.subdomain
+	// are still addressed by their IP.
+	UsePodHostname bool
+	// ClusterDomain is the DNS domain of the cluster used with UsePodHostname. Defaults to "cluster.local".
+	ClusterDomain string
+	// TemplateRequestBody renders the body and form values of HTTP POST probes as text/templates
+	// against the probed pod before sending them, e.g. {"pod":"{{.Name}}","namespace":"{{.Namespace}}"}.
+	// Rendering errors make the probe Unknown.
+	TemplateRequestBody bool
+	// ExpandHeaderEnv expands references to environment variables of the prober process in the header values
+	// of HTTP probes, e.g. "X-API-Key: ${PROBE_API_KEY}", when the probe is compiled. "$$" is a literal "$",
+	// other "$" are kept as is. A reference to a variable that is not set makes the probe Unknown. The values
+	// of headers that reference a variable are redacted in logs.
+	ExpandHeaderEnv bool
+	// RequestIDHeader, if set, is a header such as "X-Request-ID" that is set to a new UUID on every HTTP probe,
+	// e.g. to correlate probe traffic in the logs of the server. The ID is appended to the probe output as
+	// "[request id <uuid>]", so it is part of the errors of failed probes and of their RecentResults.
+	RequestIDHeader string
+	// MetricsHook is called after every probe, e.g. to record metrics. It must be safe for concurrent use.
+	MetricsHook func(ProbeEvent)
+	// EventRecorder, if set, records an Event against the probed pod when its probe starts failing (Warning)
+	// or recovers (Normal), e.g. to show probe state in kubectl describe pod. A probe that fails on its first
+	// run records a Warning, one that passes records nothing. The state of the 4096 most recently run probes
+	// is kept, so that the probes of deleted pods are eventually forgotten. Probes without a pod, such as
+	// those run through RunKind without Target.Pod, do not record Events.
+	EventRecorder EventRecorder
+	// Gate, if set, only runs probes against pods that carry its annotation. The probes of other pods,
+	// and those without a pod, pass without being run. They are reported as Success to RecentResults, with
+	// Skipped set and the Reason GateNotMet, and to MetricsHook, with Skipped set.
+	Gate *AnnotationGate
+
+	limiter targetLimiter
+	history resultHistory
+	states  probeStates
+	secrets secretCache
 }
 
 // NewProber creates a Prober instance that can be used to run httpGet, httpPost, tcp or exec probe.
-func NewProber(config *rest.Config) *Prober {
-	const followNonLocalRedirects = false
-
-	return &Prober{
-		HttpGet:  httpprobe.NewHttpGet(followNonLocalRedirects),
-		HttpPost: httpprobe.NewHttpPost(followNonLocalRedirects),
+// HTTP probes skip TLS verification and do not follow redirects to other hosts unless configured otherwise
+// with WithHTTPOptions.
+func NewProber(config *rest.Config, options ...Option) *Prober {
+	pb := &Prober{
+		HttpGet:  httpprobe.NewGet(defaultHTTPOptions()...),
+		HttpPost: httpprobe.NewPost(defaultHTTPOptions()...),
 		Tcp:      tcpprobe.New(),
 		Exec:     execprobe.New(),
 		Config:   config,
+		Grpc:     grpcprobe.New(),
+		DNS:      dnsprobe.New(),
+	}
+	for _, option := range options {
+		option(pb)
 	}
+	return pb
 }
 
 func RunProbe(config *rest.Config, probes *api_v1.Handler, podName, namespace string) error {
@@ -79,85 +155,75 @@ func RunProbe(config *rest.Config, probes *api_v1.Handler, podName, namespace st
 		}
 	}
 
-	return prober.executeProbe(probes, pod, api.DefaultProbeTimeout)
+	return prober.RunProbe(probes, pod, api.DefaultProbeTimeout)
 }
 
-func (pb *Prober) executeProbe(p *api_v1.Handler, pod *core.Pod, timeout time.Duration) error {
-	if p.Exec != nil {
-		klog.V(5).Infof("Exec-Probe Pod: %v, Container: %v, Command: %v", formatPod(pod), p.ContainerName, p.Exec.Command)
-		res, resp, err := pb.Exec.Probe(pb.Config, pod, p.ContainerName, p.Exec.Command)
-		if res != api.Success && res != api.Warning {
-			return handleProbeFailure("exec", res, resp, err)
-		}
+// RunProbe runs the probe described by probes against pod using the configuration of this Prober.
+// A zero or negative timeout uses DefaultTimeout.
+func (pb *Prober) RunProbe(probes *api_v1.Handler, pod *core.Pod, timeout time.Duration) error {
+	return pb.executeProbe(probes, pod, timeout)
+}
+
+// RunProbeContext is like RunProbe, but runs the probe with ctx, e.g. to attach labels with WithLabels.
+func (pb *Prober) RunProbeContext(ctx context.Context, probes *api_v1.Handler, pod *core.Pod, timeout time.Duration) error {
+	cp, err := pb.Compile(probes, pod)
+	if err != nil {
+		return err
 	}
-	if p.HTTPGet != nil {
-		res, resp, err := pb.executeHttpGet(p, pod, timeout)
-		if res != api.Success && res != api.Warning {
-			return handleProbeFailure("httpGet", res, resp, err)
+	return cp.Run(ctx, timeout)
+}
+
+// RunPodProbes runs the probe in handlers for each container of pod, keyed by container name,
+// and returns the outcome per container. A nil error means the probe of that container passed.
+// A failing container does not stop the others from being probed. Handlers for containers that
+// are not part of pod result in an error for that name.
+func (pb *Prober) RunPodProbes(pod *core.Pod, handlers map[string]*api_v1.Handler, timeout time.Duration) map[string]error {
+	results := make(map[string]error, len(handlers))
+	for _, c := range pod.Spec.Containers {
+		handler, ok := handlers[c.Name]
+		if !ok || handler == nil {
+			continue
 		}
-	}
-	if p.HTTPPost != nil {
-		res, resp, err := pb.executeHttpPost(p, pod, timeout)
-		if res != api.Success && res != api.Warning {
-			return handleProbeFailure("httpPost", res, resp, err)
+		if handler.ContainerName != c.Name {
+			h := *handler
+			h.ContainerName = c.Name
+			handler = &h
 		}
+		results[c.Name] = pb.executeProbe(handler, pod, timeout)
 	}
-	if p.TCPSocket != nil {
-		res, resp, err := pb.executeTcpProbe(p, pod, timeout)
-		if res != api.Success && res != api.Warning {
-			return handleProbeFailure("tcp", res, resp, err)
+	for name, handler := range handlers {
+		if _, ok := results[name]; !ok && handler != nil {
+			results[name] = fmt.Errorf("container %s not found in pod %s", name, formatPod(pod))
 		}
 	}
-	return nil
+	return results
 }
 
-func (pb *Prober) executeHttpGet(p *api_v1.Handler, pod *core.Pod, timeout time.Duration) (api.Result, string, error) {
-	scheme := strings.ToLower(string(p.HTTPGet.Scheme))
-	host := p.HTTPGet.Host
-	if host == "" {
-		host = pod.Status.PodIP
-	}
-	port, err := extractPort(p.HTTPGet.Port, pod, p.ContainerName)
-	if err != nil {
-		return api.Unknown, "", err
-	}
-	path := p.HTTPGet.Path
-	klog.V(5).Infof("HTTP-Probe Host: %v://%v, Port: %v, Path: %v", scheme, host, port, path)
-	targetURL := formatURL(scheme, host, port, path)
-	headers := buildHeader(p.HTTPGet.HTTPHeaders)
-	klog.V(5).Infof("HTTP-Probe Headers: %v", headers)
-	return pb.HttpGet.Probe(targetURL, headers, timeout)
+func (pb *Prober) executeProbe(p *api_v1.Handler, pod *core.Pod, timeout time.Duration) error {
+	return pb.RunProbeContext(context.TODO(), p, pod, timeout)
 }
 
-func (pb *Prober) executeHttpPost(p *api_v1.Handler, pod *core.Pod, timeout time.Duration) (api.Result, string, error) {
-	scheme := strings.ToLower(string(p.HTTPPost.Scheme))
-	host := p.HTTPPost.Host
-	if host == "" {
-		host = pod.Status.PodIP
+// timeout returns the timeout of a probe run with timeout, falling back to DefaultTimeout and then api.DefaultProbeTimeout.
+func (pb *Prober) timeout(timeout time.Duration) time.Duration {
+	if timeout > 0 {
+		return timeout
 	}
-	port, err := extractPort(p.HTTPPost.Port, pod, p.ContainerName)
-	if err != nil {
-		return api.Unknown, "", err
+	if pb.DefaultTimeout > 0 {
+		return pb.DefaultTimeout
 	}
-	path := p.HTTPPost.Path
-	klog.V(5).Infof("HTTP-Probe Host: %v://%v, Port: %v, Path: %v", scheme, host, port, path)
-	targetURL := formatURL(scheme, host, port, path)
-	headers := buildHeader(p.HTTPPost.HTTPHeaders)
-	klog.V(5).Infof("HTTP-Probe Headers: %v", headers)
-	return pb.HttpPost.Probe(targetURL, headers, toValues(p.HTTPPost.Form), p.HTTPPost.Body, timeout)
+	return api.DefaultProbeTimeout
 }
 
-func (pb *Prober) executeTcpProbe(p *api_v1.Handler, pod *core.Pod, timeout time.Duration) (api.Result, string, error) {
-	port, err := extractPort(p.TCPSocket.Port, pod, p.ContainerName)
-	if err != nil {
-		return api.Unknown, "", err
-	}
-	host := p.TCPSocket.Host
-	if host == "" {
-		host = pod.Status.PodIP
+// podHost returns the address of pod used by HTTP probes without an explicit host.
+func (pb *Prober) podHost(pod *core.Pod) string {
+	if pb.UsePodHostname && pod.Spec.Hostname != "" && pod.Spec.Subdomain != "" {
+		domain := pb.ClusterDomain
+		if domain == "" {
+			domain = defaultClusterDomain
+		}
+		return fmt.Sprintf("%s.%s.%s.svc.%s", pod.Spec.Hostname, pod.Spec.Subdomain, pod.Namespace, domain)
 	}
-	klog.V(5).Infof("TCP-Probe Host: %v, Port: %v, Timeout: %v", host, port, timeout)
-	return pb.Tcp.Probe(host, port, timeout)
+	return pod.Status.PodIP
 }
 
 func toValues(formEntry []api_v1.FormEntry) url.Values {
@@ -224,7 +290,7 @@ func extractPort(param intstr.IntOrString, pod *core.Pod, containerName string)
 func handleProbeFailure(probeType string, result api.Result, resp string, probeErr error) error {
 	switch result {
 	case api.Unknown:
-		return fmt.Errorf("failed to execute %q probe. Error: %v", probeType, probeErr)
+		return fmt.Errorf("failed to execute %q probe. Error: %w", probeType, probeErr)
 	case api.Failure:
 		return fmt.Errorf("failed to execute %q probe. Error: %v. Response: %s", probeType, probeErr, resp)
 	}
@@ -256,8 +322,11 @@ func formatURL(scheme string, host string, port int, path string) *url.URL {
 }
 
 // formatPod returns a string representing a pod in a consistent human readable format,
-// with pod UID as part of the string.
+// with pod UID as part of the string. A nil pod is formatted as "<nil>".
 func formatPod(pod *v1.Pod) string {
+	if pod == nil {
+		return "<nil>"
+	}
 	return podDesc(pod.Name, pod.Namespace, pod.UID)
 }
 
@@ -1,16 +1,45 @@
 package probe
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"io"
+	"math/big"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	api "kmodules.xyz/prober/api"
 	prober_v1 "kmodules.xyz/prober/api/v1"
+	execprobe "kmodules.xyz/prober/probe/exec"
+	grpcprobe "kmodules.xyz/prober/probe/grpc"
+	httpprobe "kmodules.xyz/prober/probe/http"
+	tcpprobe "kmodules.xyz/prober/probe/tcp"
 
 	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -384,6 +413,19 @@ func TestRunProbe(t *testing.T) {
 			},
 			expectedErrMsg: `failed to execute "tcp" probe. Error: <nil>. Response: dial tcp 127.0.0.1:8899: connect: connection refused`,
 		},
+		{
+			name: "TCP: protocol of the action",
+			probe: &prober_v1.Handler{
+				TCPSocket: &core.TCPSocketAction{
+					Host: "127.0.0.1",
+					Port: intstr.FromInt(8920),
+				},
+				TCPSocketOptions: &prober_v1.TCPSocketOptions{Protocol: "redis"},
+			},
+			handler:        genericHandler(http.StatusOK),
+			pod:            pod,
+			expectedErrMsg: `failed to execute "tcp" probe. Error: <nil>. Response: redis protocol error: HTTP/1.1 400 Bad Request`,
+		},
 		{
 			name: "TCP: invalid pod",
 			probe: &prober_v1.Handler{
@@ -430,7 +472,1383 @@ func TestRunProbe(t *testing.T) {
 				if err.Error() != test.expectedErrMsg {
 					t.Errorf("Expected error message: %v, Found: %v", test.expectedErrMsg, err.Error())
 				}
+			} else if test.expectedErrMsg != "" {
+				t.Errorf("Expected error message: %v, Found: <nil>", test.expectedErrMsg)
+			}
+		})
+	}
+}
+
+func TestRegisterProbe(t *testing.T) {
+	var got api.Target
+	RegisterProbe("dummy", func(ctx context.Context, pb *Prober, target api.Target) (api.Result, string, error) {
+		got = target
+		return api.Warning, "dummy output", nil
+	})
+
+	prober := NewProber(nil)
+	res, out, err := prober.RunKind(context.TODO(), "dummy", api.Target{Host: "example.com", Port: 1234})
+	if res != api.Warning || out != "dummy output" || err != nil {
+		t.Errorf("Expected (%v, %q, <nil>), Found: (%v, %q, %v)", api.Warning, "dummy output", res, out, err)
+	}
+	if got.Host != "example.com" || got.Port != 1234 {
+		t.Errorf("Expected target to be passed through, Found: %+v", got)
+	}
+
+	res, _, err = prober.RunKind(context.TODO(), "not-registered", api.Target{})
+	if res != api.Unknown || err == nil {
+		t.Errorf("Expected unknown result with error for unregistered kind, Found: %v, %v", res, err)
+	}
+}
+
+func TestDefaultTimeout(t *testing.T) {
+	var got time.Duration
+	RegisterProbe("timed", func(ctx context.Context, pb *Prober, target api.Target) (api.Result, string, error) {
+		got = target.Timeout
+		return api.Success, "", nil
+	})
+
+	tests := []struct {
+		name     string
+		prober   *Prober
+		timeout  time.Duration
+		expected time.Duration
+	}{
+		{"explicit", NewProber(nil, WithDefaultTimeout(time.Minute)), 5 * time.Second, 5 * time.Second},
+		{"prober default", NewProber(nil, WithDefaultTimeout(time.Minute)), 0, time.Minute},
+		{"package default", NewProber(nil), 0, api.DefaultProbeTimeout},
+		{"negative", NewProber(nil, WithDefaultTimeout(time.Minute)), -time.Second, time.Minute},
+	}
+	for _, tt := range tests {
+		got = 0
+		if _, _, err := tt.prober.RunKind(context.TODO(), "timed", api.Target{Timeout: tt.timeout}); err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if got != tt.expected {
+			t.Errorf("%s: Expected timeout %v, Found: %v", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestMaxConcurrentProbesPerTarget(t *testing.T) {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	RegisterProbe("blocking", func(ctx context.Context, pb *Prober, target api.Target) (api.Result, string, error) {
+		started <- struct{}{}
+		<-unblock
+		return api.Success, "", nil
+	})
+	target := api.Target{Host: "127.0.0.1", Port: 8920, Timeout: 100 * time.Millisecond}
+
+	for _, mode := range []LimitMode{LimitModeFailFast, LimitModeWait} {
+		t.Run(string(mode), func(t *testing.T) {
+			prober := NewProber(nil)
+			prober.MaxConcurrentProbesPerTarget = 1
+			prober.LimitMode = mode
+
+			done := make(chan api.Result)
+			go func() {
+				res, _, _ := prober.RunKind(context.TODO(), "blocking", target)
+				done <- res
+			}()
+			<-started
+
+			res, _, err := prober.RunKind(context.TODO(), "blocking", target)
+			if res != api.Unknown || err == nil {
+				t.Errorf("Expected unknown result over the limit, Found: %v, %v", res, err)
+			}
+			// a different target is not affected by the limit
+			res, _, err = prober.RunKind(context.TODO(), KindTCP, api.Target{Host: "127.0.0.1", Port: 8899, Timeout: time.Second})
+			if res != api.Failure || err != nil {
+				t.Errorf("Expected other target to be probed, Found: %v, %v", res, err)
+			}
+
+			close(unblock)
+			if res := <-done; res != api.Success {
+				t.Errorf("Expected first probe to succeed, Found: %v", res)
+			}
+			unblock = make(chan struct{})
+		})
+	}
+}
+
+func TestMaxConcurrentProbesPerTarget_Wait(t *testing.T) {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	timeouts := make(chan time.Duration, 1)
+	RegisterProbe("queued", func(ctx context.Context, pb *Prober, target api.Target) (api.Result, string, error) {
+		if target.Port == 1 {
+			started <- struct{}{}
+			<-unblock
+		} else {
+			timeouts <- target.Timeout
+		}
+		return api.Success, "", nil
+	})
+	prober := NewProber(nil, WithMaxConcurrentProbesPerTarget(1, LimitModeWait))
+	// both probes share the slot of the host, which does not depend on the port in this test
+	slot := func(target api.Target) api.Target {
+		target.URL = &url.URL{Host: "127.0.0.1:8920"}
+		return target
+	}
+
+	done := make(chan struct{})
+	go func() {
+		_, _, _ = prober.RunKind(context.TODO(), "queued", slot(api.Target{Port: 1, Timeout: time.Second}))
+		close(done)
+	}()
+	<-started
+	time.AfterFunc(200*time.Millisecond, func() { close(unblock) })
+	res, _, err := prober.RunKind(context.TODO(), "queued", slot(api.Target{Port: 2, Timeout: time.Second}))
+	if res != api.Success || err != nil {
+		t.Errorf("Expected the queued probe to run once the slot is free, Found: %v, %v", res, err)
+	}
+	if timeout := <-timeouts; timeout > 800*time.Millisecond || timeout <= 0 {
+		t.Errorf("Expected the wait for the slot to count against the timeout, Found a timeout of %v", timeout)
+	}
+	<-done
+
+	prober.limiter.lock.Lock()
+	defer prober.limiter.lock.Unlock()
+	if len(prober.limiter.slots) != 0 {
+		t.Errorf("Expected idle slots to be dropped, Found: %v", prober.limiter.slots)
+	}
+}
+
+func TestRecentResults(t *testing.T) {
+	results := []api.Result{api.Success, api.Failure, api.Warning, api.Success}
+	var i int
+	RegisterProbe("scripted", func(ctx context.Context, pb *Prober, target api.Target) (api.Result, string, error) {
+		res := results[i%len(results)]
+		i++
+		return res, string(res) + " output", nil
+	})
+	target := api.Target{Host: "127.0.0.1", Port: 8920}
+
+	prober := NewProber(nil)
+	_, _, _ = prober.RunKind(context.TODO(), "scripted", target)
+	if got := prober.RecentResults(); len(got) != 0 {
+		t.Errorf("Expected history to be disabled by default, Found: %v", got)
+	}
+
+	i = 0
+	prober.HistorySize = 3
+	for range results {
+		_, _, _ = prober.RunKind(context.TODO(), "scripted", target)
+	}
+	got := prober.RecentResults()
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 entries, Found: %v", got)
+	}
+	for j, want := range results[1:] {
+		if got[j].Result != want || got[j].Kind != "scripted" || got[j].Target != "127.0.0.1:8920" {
+			t.Errorf("Entry %d: expected %v result for 127.0.0.1:8920, Found: %+v", j, want, got[j])
+		}
+	}
+	if got[0].Reason != "failure output" || got[2].Reason != "" {
+		t.Errorf("Expected reasons only for unsuccessful probes, Found: %q and %q", got[0].Reason, got[2].Reason)
+	}
+}
+
+func TestPodHost(t *testing.T) {
+	pod := &core.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "demo"},
+		Spec:       core.PodSpec{Hostname: "db-0", Subdomain: "db-pods"},
+		Status:     core.PodStatus{PodIP: "10.0.0.7"},
+	}
+	withoutHostname := pod.DeepCopy()
+	withoutHostname.Spec.Subdomain = ""
+
+	testCases := []struct {
+		name           string
+		usePodHostname bool
+		clusterDomain  string
+		pod            *core.Pod
+		expected       string
+	}{
+		{name: "default is pod IP", pod: pod, expected: "10.0.0.7"},
+		{name: "pod hostname", usePodHostname: true, pod: pod, expected: "db-0.db-pods.demo.svc.cluster.local"},
+		{name: "custom cluster domain", usePodHostname: true, clusterDomain: "example.org", pod: pod, expected: "db-0.db-pods.demo.svc.example.org"},
+		{name: "no subdomain", usePodHostname: true, pod: withoutHostname, expected: "10.0.0.7"},
+	}
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			prober := NewProber(nil)
+			prober.UsePodHostname = test.usePodHostname
+			prober.ClusterDomain = test.clusterDomain
+			if got := prober.podHost(test.pod); got != test.expected {
+				t.Errorf("Expected host: %v, Found: %v", test.expected, got)
+			}
+		})
+	}
+}
+
+type countingGetProber struct {
+	calls   int
+	lastURL *url.URL
+}
+
+func (p *countingGetProber) Probe(u *url.URL, headers http.Header, timeout time.Duration) (api.Result, string, error) {
+	p.calls++
+	p.lastURL = u
+	return api.Success, "", nil
+}
+
+func compiledTestProbe() (*prober_v1.Handler, *core.Pod) {
+	pod := &core.Pod{
+		Spec: core.PodSpec{
+			Containers: []core.Container{{
+				Name:  "foo",
+				Ports: []core.ContainerPort{{Name: "http", ContainerPort: 8080}},
+			}},
+		},
+		Status: core.PodStatus{PodIP: "10.0.0.7"},
+	}
+	handler := &prober_v1.Handler{
+		HTTPGet: &core.HTTPGetAction{
+			Scheme:      "HTTP",
+			Path:        "/healthz",
+			Port:        intstr.FromString("http"),
+			HTTPHeaders: []core.HTTPHeader{{Name: "X-Probe", Value: "1"}},
+		},
+		ContainerName: "foo",
+	}
+	return handler, pod
+}
+
+func TestCompiledProbe(t *testing.T) {
+	handler, pod := compiledTestProbe()
+	getter := &countingGetProber{}
+	prober := NewProber(nil)
+	prober.HttpGet = getter
+
+	cp, err := prober.Compile(handler, pod)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	// changes after Compile are not picked up
+	pod.Status.PodIP = "10.0.0.8"
+	for i := 0; i < 3; i++ {
+		if err := cp.Run(context.TODO(), time.Second); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if getter.calls != 3 || getter.lastURL.String() != "http://10.0.0.7:8080/healthz" {
+		t.Errorf("Expected 3 probes of http://10.0.0.7:8080/healthz, Found: %d probes of %v", getter.calls, getter.lastURL)
+	}
+
+	handler.HTTPGet.Port = intstr.FromString("missing")
+	if _, err := prober.Compile(handler, pod); err == nil {
+		t.Errorf("Expected error for unknown port name")
+	}
+}
+
+func TestCompileExecWithoutPod(t *testing.T) {
+	handler := &prober_v1.Handler{Exec: &core.ExecAction{Command: []string{"true"}}, ContainerName: "foo"}
+	cp, err := NewProber(nil).Compile(handler, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(cp.steps) != 1 || cp.steps[0].kind != KindExec {
+		t.Errorf("Expected a single exec step, Found: %v", cp.steps)
+	}
+}
+
+func BenchmarkExecuteProbe(b *testing.B) {
+	handler, pod := compiledTestProbe()
+	prober := NewProber(nil)
+	prober.HttpGet = &countingGetProber{}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if err := prober.executeProbe(handler, pod, time.Second); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCompiledProbe(b *testing.B) {
+	handler, pod := compiledTestProbe()
+	prober := NewProber(nil)
+	prober.HttpGet = &countingGetProber{}
+	cp, err := prober.Compile(handler, pod)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := cp.Run(context.TODO(), time.Second); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestRunPodProbes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ready" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	_, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	port, _ := strconv.Atoi(portStr)
+
+	containerPorts := []core.ContainerPort{{Name: "http", ContainerPort: int32(port)}}
+	pod := &core.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "demo"},
+		Spec: core.PodSpec{
+			Containers: []core.Container{
+				{Name: "app", Ports: containerPorts},
+				{Name: "sidecar", Ports: containerPorts},
+				{Name: "unprobed"},
+			},
+		},
+		Status: core.PodStatus{PodIP: "127.0.0.1"},
+	}
+	httpGet := func(path string) *prober_v1.Handler {
+		return &prober_v1.Handler{HTTPGet: &core.HTTPGetAction{Scheme: core.URISchemeHTTP, Path: path, Port: intstr.FromString("http")}}
+	}
+
+	results := NewProber(nil).RunPodProbes(pod, map[string]*prober_v1.Handler{
+		"app":     httpGet("/ready"),
+		"sidecar": httpGet("/not-ready"),
+		"missing": httpGet("/ready"),
+	}, time.Second)
+
+	if len(results) != 3 {
+		t.Fatalf("Expected results for 3 containers, Found: %v", results)
+	}
+	if err := results["app"]; err != nil {
+		t.Errorf("Expected app to pass, Found: %v", err)
+	}
+	if err := results["sidecar"]; err == nil || !strings.Contains(err.Error(), "statuscode: 503") {
+		t.Errorf("Expected sidecar to fail with status 503, Found: %v", err)
+	}
+	if err := results["missing"]; err == nil || !strings.Contains(err.Error(), "container missing not found") {
+		t.Errorf("Expected missing container error, Found: %v", err)
+	}
+}
+
+func TestMetricsHookLabels(t *testing.T) {
+	RegisterProbe("labelled", func(ctx context.Context, pb *Prober, target api.Target) (api.Result, string, error) {
+		return api.Failure, "down", nil
+	})
+
+	var events []ProbeEvent
+	prober := NewProber(nil)
+	prober.MetricsHook = func(e ProbeEvent) {
+		events = append(events, e)
+	}
+
+	ctx := WithLabels(context.TODO(), map[string]string{"cluster": "east", "tenant": "a"})
+	ctx = WithLabels(ctx, map[string]string{"tenant": "b"})
+	_, _, _ = prober.RunKind(ctx, "labelled", api.Target{Host: "127.0.0.1", Port: 8920})
+	_, _, _ = prober.RunKind(context.TODO(), "labelled", api.Target{Host: "127.0.0.1", Port: 8921})
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, Found: %v", events)
+	}
+	if e := events[0]; e.Kind != "labelled" || e.Target != "127.0.0.1:8920" || e.Result != api.Failure ||
+		e.Labels["cluster"] != "east" || e.Labels["tenant"] != "b" {
+		t.Errorf("Unexpected event: %+v", e)
+	}
+	if e := events[1]; len(e.Labels) != 0 {
+		t.Errorf("Expected no labels, Found: %v", e.Labels)
+	}
+}
+
+func TestPodWithoutIP(t *testing.T) {
+	pod := &core.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "demo"},
+		Spec:       core.PodSpec{Containers: []core.Container{{Name: "app"}}},
+	}
+	testCases := map[string]*prober_v1.Handler{
+		"httpGet":  {HTTPGet: &core.HTTPGetAction{Scheme: core.URISchemeHTTP, Port: intstr.FromInt(8080)}},
+		"httpPost": {HTTPPost: &prober_v1.HTTPPostAction{Scheme: core.URISchemeHTTP, Port: intstr.FromInt(8080)}},
+		"tcp":      {TCPSocket: &core.TCPSocketAction{Port: intstr.FromInt(8080)}},
+	}
+	prober := NewProber(nil)
+	for kind, handler := range testCases {
+		t.Run(kind, func(t *testing.T) {
+			err := prober.RunProbe(handler, pod, time.Second)
+			if !errors.Is(err, ErrPodHasNoIP) {
+				t.Fatalf("Expected ErrPodHasNoIP, Found: %v", err)
+			}
+			expected := fmt.Sprintf("failed to execute %q probe. Error: pod has no IP yet: web-0_demo()", kind)
+			if err.Error() != expected {
+				t.Errorf("Expected error message: %v, Found: %v", expected, err)
+			}
+		})
+	}
+}
+
+func TestTemplateRequestBody(t *testing.T) {
+	var gotBody string
+	var gotForm url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") == "application/x-www-form-urlencoded" {
+			_ = r.ParseForm()
+			gotForm = r.PostForm
+		} else {
+			b, _ := io.ReadAll(r.Body)
+			gotBody = string(b)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	pod := &core.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "demo"}}
+	post := func(body string, form ...prober_v1.FormEntry) *prober_v1.Handler {
+		return &prober_v1.Handler{HTTPPost: &prober_v1.HTTPPostAction{
+			Scheme: core.URISchemeHTTP, Host: host, Port: intstr.FromInt(port), Body: body, Form: form,
+		}}
+	}
+	prober := NewProber(nil)
+
+	// templating is opt-in
+	if err := prober.RunProbe(post(`{"pod":"{{.Name}}"}`), pod, time.Second); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if gotBody != `{"pod":"{{.Name}}"}` {
+		t.Errorf("Expected the body to be sent as is, Found: %s", gotBody)
+	}
+
+	prober.TemplateRequestBody = true
+	if err := prober.RunProbe(post(`{"pod":"{{.Name}}","namespace":"{{.Namespace}}"}`), pod, time.Second); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if gotBody != `{"pod":"web-0","namespace":"demo"}` {
+		t.Errorf("Unexpected body: %s", gotBody)
+	}
+
+	if err := prober.RunProbe(post("", prober_v1.FormEntry{Key: "pod", Values: []string{"{{.Namespace}}/{{.Name}}"}}), pod, time.Second); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if gotForm.Get("pod") != "demo/web-0" {
+		t.Errorf("Unexpected form: %v", gotForm)
+	}
+
+	err := prober.RunProbe(post(`{"pod":"{{.Nmae}}"}`), pod, time.Second)
+	if err == nil || !strings.Contains(err.Error(), `failed to execute "httpPost" probe. Error: failed to render request body`) {
+		t.Errorf("Expected a rendering error, Found: %v", err)
+	}
+}
+
+func TestExpandHeaderEnv(t *testing.T) {
+	t.Setenv("PROBE_API_KEY", "s3cr3t")
+	var gotHeader http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	get := func(headers ...core.HTTPHeader) *prober_v1.Handler {
+		return &prober_v1.Handler{HTTPGet: &core.HTTPGetAction{
+			Scheme: core.URISchemeHTTP, Host: host, Port: intstr.FromInt(port), HTTPHeaders: headers,
+		}}
+	}
+	var transcript bytes.Buffer
+	prober := NewProber(nil, WithHTTPOptions(httpprobe.WithOptions(httpprobe.Options{Recorder: &transcript})))
+	headers := []core.HTTPHeader{
+		{Name: "X-API-Key", Value: "${PROBE_API_KEY}"},
+		{Name: "X-Price", Value: "$$5 or $6"},
+	}
+
+	// expansion is opt-in
+	if err := prober.RunProbe(get(headers...), nil, time.Second); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := gotHeader.Get("X-API-Key"); got != "${PROBE_API_KEY}" {
+		t.Errorf("Expected the header to be sent as is, Found: %s", got)
+	}
+
+	prober.ExpandHeaderEnv = true
+	transcript.Reset()
+	if err := prober.RunProbe(get(headers...), nil, time.Second); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := gotHeader.Get("X-API-Key"); got != "s3cr3t" {
+		t.Errorf("Expected X-API-Key: s3cr3t, Found: %s", got)
+	}
+	if got := gotHeader.Get("X-Price"); got != "$5 or $6" {
+		t.Errorf("Expected X-Price: $5 or $6, Found: %s", got)
+	}
+	if strings.Contains(transcript.String(), "s3cr3t") || !strings.Contains(transcript.String(), "X-API-Key: <redacted>") {
+		t.Errorf("Expected the expanded header to be redacted, Found: %s", transcript.String())
+	}
+
+	err := prober.RunProbe(get(core.HTTPHeader{Name: "X-Token", Value: "${PROBE_MISSING_TOKEN}"}), nil, time.Second)
+	if err == nil || !strings.Contains(err.Error(), "failed to expand header X-Token: environment variable PROBE_MISSING_TOKEN is not set") {
+		t.Errorf("Expected an expansion error, Found: %v", err)
+	}
+}
+
+func TestRequestIDHeader(t *testing.T) {
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get("X-Request-ID"))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	handler := &prober_v1.Handler{HTTPGet: &core.HTTPGetAction{Scheme: core.URISchemeHTTP, Host: host, Port: intstr.FromInt(port)}}
+
+	if err := NewProber(nil).RunProbe(handler, nil, time.Second); err == nil || strings.Contains(err.Error(), "request id") {
+		t.Errorf("Expected a failure without request id, Found: %v", err)
+	}
+	if ids[0] != "" {
+		t.Errorf("Expected no X-Request-ID by default, Found: %s", ids[0])
+	}
+
+	prober := NewProber(nil, WithRequestIDHeader("X-Request-ID"))
+	for i := 1; i <= 2; i++ {
+		err := prober.RunProbe(handler, nil, time.Second)
+		if ids[i] == "" {
+			t.Fatalf("Expected an X-Request-ID header")
+		}
+		if err == nil || !strings.Contains(err.Error(), "[request id "+ids[i]+"]") {
+			t.Errorf("Expected the error to report request id %s, Found: %v", ids[i], err)
+		}
+	}
+	if ids[1] == ids[2] {
+		t.Errorf("Expected a new request id per probe, Found: %s twice", ids[1])
+	}
+}
+
+func newTestKeyPair(t *testing.T, cn string) (certPEM, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestSecretCertificate(t *testing.T) {
+	var (
+		reads  int
+		secret *core.Secret
+	)
+	getSecret := func(ctx context.Context, namespace, name string) (*core.Secret, error) {
+		reads++
+		if secret == nil || namespace != "demo" || name != "client-cert" {
+			return nil, fmt.Errorf("secrets %q not found", name)
+		}
+		return secret, nil
+	}
+	certs := newSecretCertificate(ClientCertSecret{Namespace: "demo", Name: "client-cert", TTL: 50 * time.Millisecond}, getSecret)
+
+	if _, err := certs.ClientCertificate(); err == nil || !strings.Contains(err.Error(), "failed to get secret demo/client-cert") {
+		t.Errorf("Expected error for missing secret, Found: %v", err)
+	}
+
+	certPEM, keyPEM := newTestKeyPair(t, "first")
+	secret = &core.Secret{Data: map[string][]byte{core.TLSCertKey: certPEM, core.TLSPrivateKeyKey: keyPEM}}
+	for i := 0; i < 3; i++ {
+		cert, err := certs.ClientCertificate()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if leaf, _ := x509.ParseCertificate(cert.Certificate[0]); leaf.Subject.CommonName != "first" {
+			t.Errorf("Expected certificate first, Found: %v", leaf.Subject.CommonName)
+		}
+	}
+	if reads != 2 {
+		t.Errorf("Expected the certificate to be cached, Found %d reads", reads)
+	}
+
+	// the rotated certificate is picked up once the cache expires
+	certPEM, keyPEM = newTestKeyPair(t, "rotated")
+	secret = &core.Secret{Data: map[string][]byte{core.TLSCertKey: certPEM, core.TLSPrivateKeyKey: keyPEM}}
+	time.Sleep(60 * time.Millisecond)
+	cert, err := certs.ClientCertificate()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if leaf, _ := x509.ParseCertificate(cert.Certificate[0]); leaf.Subject.CommonName != "rotated" {
+		t.Errorf("Expected certificate rotated, Found: %v", leaf.Subject.CommonName)
+	}
+}
+
+func TestSecretCertificate_SlowRead(t *testing.T) {
+	certPEM, keyPEM := newTestKeyPair(t, "client")
+	secret := &core.Secret{Data: map[string][]byte{core.TLSCertKey: certPEM, core.TLSPrivateKeyKey: keyPEM}}
+	hang := make(chan struct{})
+	var calls atomic.Int32
+	getSecret := func(ctx context.Context, namespace, name string) (*core.Secret, error) {
+		if calls.Add(1) == 1 {
+			// the first read hangs until its timeout
+			close(hang)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return secret, nil
+	}
+	certs := newSecretCertificate(ClientCertSecret{Namespace: "demo", Name: "client-cert", Timeout: 200 * time.Millisecond}, getSecret)
+
+	errs := make(chan error, 1)
+	start := time.Now()
+	go func() {
+		_, err := certs.ClientCertificate()
+		errs <- err
+	}()
+	<-hang
+	if _, err := certs.ClientCertificate(); err != nil {
+		t.Errorf("Expected a read not to wait for a hanging one, Found: %v", err)
+	}
+	if err := <-errs; !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the hanging read to time out, Found: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected the hanging read to stop after its timeout, Found: %v", elapsed)
+	}
+}
+
+func TestNewProber_Options(t *testing.T) {
+	var userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+	}))
+	defer server.Close()
+	hook := func(ProbeEvent) {}
+
+	pb := NewProber(nil,
+		WithHTTPOptions(httpprobe.WithUserAgent("probe/1.0")),
+		WithTCPOptions(tcpprobe.Options{Invert: true}),
+		WithExecOptions(execprobe.Options{TTY: true}),
+		WithGRPCOptions(grpcprobe.Options{ExpectReflectionService: "demo.Greeter"}),
+		WithMaxConcurrentProbesPerTarget(2, LimitModeFailFast),
+		WithHistorySize(5),
+		WithMetricsHook(hook),
+	)
+
+	target, _ := url.Parse(server.URL)
+	if _, _, err := pb.HttpGet.Probe(target, nil, time.Second); err != nil || userAgent != "probe/1.0" {
+		t.Errorf("Expected the GET prober to send User-Agent probe/1.0, Found: %q, error: %v", userAgent, err)
+	}
+	userAgent = ""
+	if _, _, err := pb.HttpPost.Probe(target, nil, nil, "", time.Second); err != nil || userAgent != "probe/1.0" {
+		t.Errorf("Expected the POST prober to send User-Agent probe/1.0, Found: %q, error: %v", userAgent, err)
+	}
+	if !reflect.DeepEqual(pb.Tcp, tcpprobe.NewWithOptions(tcpprobe.Options{Invert: true})) {
+		t.Errorf("Expected an inverted TCP prober, Found: %#v", pb.Tcp)
+	}
+	if !reflect.DeepEqual(pb.Exec, execprobe.NewWithOptions(execprobe.Options{TTY: true})) {
+		t.Errorf("Expected an exec prober with TTY, Found: %#v", pb.Exec)
+	}
+	if !reflect.DeepEqual(pb.Grpc, grpcprobe.NewWithOptions(grpcprobe.Options{ExpectReflectionService: "demo.Greeter"})) {
+		t.Errorf("Expected a gRPC prober with reflection, Found: %#v", pb.Grpc)
+	}
+	if pb.MaxConcurrentProbesPerTarget != 2 || pb.LimitMode != LimitModeFailFast {
+		t.Errorf("Expected a limit of 2 failing fast, Found: %d %s", pb.MaxConcurrentProbesPerTarget, pb.LimitMode)
+	}
+	if pb.HistorySize != 5 {
+		t.Errorf("Expected history size 5, Found: %d", pb.HistorySize)
+	}
+	if pb.MetricsHook == nil {
+		t.Errorf("Expected the metrics hook to be set")
+	}
+}
+
+type fakeEventRecorder struct {
+	events []string
+}
+
+func (r *fakeEventRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	pod := object.(*core.Pod)
+	r.events = append(r.events, fmt.Sprintf("%s %s %s: %s", pod.Name, eventtype, reason, fmt.Sprintf(messageFmt, args...)))
+}
+
+func TestEventRecorder(t *testing.T) {
+	var results []api.Result
+	RegisterProbe("flapping", func(ctx context.Context, pb *Prober, target api.Target) (api.Result, string, error) {
+		res := results[0]
+		results = results[1:]
+		return res, "status " + string(res), nil
+	})
+
+	recorder := &fakeEventRecorder{}
+	prober := NewProber(nil, WithEventRecorder(recorder))
+	web0 := &core.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-0", UID: "uid-0"}}
+	web1 := &core.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-1", UID: "uid-1"}}
+
+	results = []api.Result{api.Success, api.Failure, api.Failure, api.Warning, api.Unknown, api.Failure}
+	for _, pod := range []*core.Pod{web0, web0, web0, web0, web1, web1} {
+		_, _, _ = prober.RunKind(context.TODO(), "flapping", api.Target{Host: "10.0.0.1", Port: 80, Pod: pod})
+	}
+	// probes without a pod do not record events
+	results = []api.Result{api.Success, api.Failure}
+	_, _, _ = prober.RunKind(context.TODO(), "flapping", api.Target{Host: "10.0.0.1", Port: 80})
+	_, _, _ = prober.RunKind(context.TODO(), "flapping", api.Target{Host: "10.0.0.1", Port: 80})
+
+	expected := []string{
+		"web-0 Warning ProbeFailed: flapping probe of 10.0.0.1:80 failed: status failure",
+		"web-0 Normal ProbeRecovered: flapping probe of 10.0.0.1:80 succeeded",
+		"web-1 Warning ProbeFailed: flapping probe of 10.0.0.1:80 failed: status unknown",
+	}
+	if !reflect.DeepEqual(recorder.events, expected) {
+		t.Errorf("Expected events %q, Found: %q", expected, recorder.events)
+	}
+}
+
+func TestProbeStates(t *testing.T) {
+	states := probeStates{size: 2}
+	steps := []struct {
+		key     string
+		passing bool
+		changed bool
+	}{
+		{"a", true, false},
+		{"b", false, true},
+		{"b", false, false},
+		{"a", false, true},
+		// c evicts b, the least recently run
+		{"c", true, false},
+		{"a", false, false},
+		{"b", false, true},
+	}
+	for i, step := range steps {
+		if changed := states.update(step.key, step.passing); changed != step.changed {
+			t.Errorf("#%d: Expected update(%s, %v) to report %v, Found: %v", i, step.key, step.passing, step.changed, changed)
+		}
+	}
+	if len(states.entries) != 2 || states.lru.Len() != 2 {
+		t.Errorf("Expected the states of 2 probes, Found: %d", len(states.entries))
+	}
+}
+
+func TestGate(t *testing.T) {
+	handler := &prober_v1.Handler{TCPSocket: &core.TCPSocketAction{Port: intstr.FromInt(8080)}}
+	testCases := map[string]struct {
+		annotations map[string]string
+		skipped     bool
+	}{
+		"no annotation":  {nil, true},
+		"other value":    {map[string]string{"probe.example.com/enabled": "false"}, true},
+		"matching value": {map[string]string{"probe.example.com/enabled": "true"}, false},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			// the pod has no IP, so a probe that runs fails with ErrPodHasNoIP
+			pod := &core.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "demo", Annotations: tt.annotations}}
+			prober := NewProber(nil, WithGate("probe.example.com/enabled", "true"), WithHistorySize(1))
+			var events []ProbeEvent
+			prober.MetricsHook = func(ev ProbeEvent) { events = append(events, ev) }
+			err := prober.RunProbe(handler, pod, time.Second)
+			if !tt.skipped {
+				if !errors.Is(err, ErrPodHasNoIP) {
+					t.Errorf("Expected the probe to run and fail with ErrPodHasNoIP, Found: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Expected the skipped probe to pass, Found: %v", err)
+			}
+			got := prober.RecentResults()
+			if len(got) != 1 || got[0].Kind != KindTCP || got[0].Result != api.Success || !got[0].Skipped || got[0].Reason != GateNotMet {
+				t.Errorf("Expected a skipped %s result for the tcp probe, Found: %+v", api.Success, got)
+			}
+			if len(events) != 1 || events[0].Kind != KindTCP || events[0].Result != api.Success || !events[0].Skipped {
+				t.Errorf("Expected a skipped %s event for the tcp probe, Found: %+v", api.Success, events)
+			}
+		})
+	}
+}
+
+func TestHandlerProbeKind(t *testing.T) {
+	testCases := map[string]struct {
+		handler *prober_v1.Handler
+		kind    string
+		kinds   []string
+	}{
+		"none":     {&prober_v1.Handler{}, "", nil},
+		"exec":     {&prober_v1.Handler{Exec: &core.ExecAction{}}, KindExec, []string{KindExec}},
+		"httpGet":  {&prober_v1.Handler{HTTPGet: &core.HTTPGetAction{}}, KindHTTPGet, []string{KindHTTPGet}},
+		"httpPost": {&prober_v1.Handler{HTTPPost: &prober_v1.HTTPPostAction{}}, KindHTTPPost, []string{KindHTTPPost}},
+		"tcp":      {&prober_v1.Handler{TCPSocket: &core.TCPSocketAction{}}, KindTCP, []string{KindTCP}},
+		"ambiguous": {
+			&prober_v1.Handler{HTTPGet: &core.HTTPGetAction{}, TCPSocket: &core.TCPSocketAction{}}, "",
+			[]string{KindHTTPGet, KindTCP},
+		},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			if kind := tt.handler.ProbeKind(); kind != tt.kind {
+				t.Errorf("Expected kind %q, Found: %q", tt.kind, kind)
+			}
+			if kinds := tt.handler.ProbeKinds(); !reflect.DeepEqual(kinds, tt.kinds) {
+				t.Errorf("Expected kinds %q, Found: %q", tt.kinds, kinds)
 			}
 		})
 	}
 }
+
+func TestProbeStability(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		switch {
+		case r.URL.Path == "/flapping" && n%2 == 0:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case r.URL.Path == "/slow":
+			time.Sleep(100 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	port, _ := strconv.Atoi(u.Port())
+	handler := func(path string) *prober_v1.Handler {
+		return &prober_v1.Handler{HTTPGet: &core.HTTPGetAction{Scheme: core.URISchemeHTTP, Host: u.Hostname(), Port: intstr.FromInt(port), Path: path}}
+	}
+
+	testCases := map[string]struct {
+		path    string
+		budget  time.Duration
+		runs    int
+		results map[api.Result]int
+		stable  bool
+	}{
+		"stable":   {"/", 0, 4, map[api.Result]int{api.Success: 4}, true},
+		"flapping": {"/flapping", 0, 4, map[api.Result]int{api.Success: 2, api.Failure: 2}, false},
+		// the budget runs out during the third run, which is left out
+		"budget": {"/slow", 250 * time.Millisecond, 2, map[api.Result]int{api.Success: 2}, true},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			calls.Store(0)
+			summary, err := NewProber(nil).ProbeStability(4, handler(tt.path), nil, time.Second, tt.budget)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if summary.Runs != tt.runs || len(summary.Latencies) != tt.runs {
+				t.Errorf("Expected %d runs, Found: %d runs with %d latencies", tt.runs, summary.Runs, len(summary.Latencies))
+			}
+			if !reflect.DeepEqual(summary.Results, tt.results) || summary.Stable != tt.stable {
+				t.Errorf("Expected results %v, stable %v, Found: %v, stable %v", tt.results, tt.stable, summary.Results, summary.Stable)
+			}
+			if (summary.LastError != nil) == tt.stable {
+				t.Errorf("Expected an error only for flapping probes, Found: %v", summary.LastError)
+			}
+		})
+	}
+}
+
+func TestProbePlan(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		if r.URL.Path == "/ready" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	get := func(path string) *prober_v1.Handler {
+		return &prober_v1.Handler{HTTPGet: &core.HTTPGetAction{
+			Scheme: core.URISchemeHTTP, Host: host, Port: intstr.FromInt(port), Path: path,
+		}}
+	}
+	plan := ProbePlan{Probes: map[string]*prober_v1.Handler{
+		"readiness": get("/ready"),
+		"liveness":  get("/live"),
+		"startup":   nil,
+		"broken":    {HTTPGet: &core.HTTPGetAction{Port: intstr.FromString("http")}},
+	}}
+	prober := NewProber(nil)
+
+	for _, parallel := range []bool{false, true} {
+		atomic.StoreInt32(&maxInFlight, 0)
+		plan.Parallel = parallel
+		results := plan.Run(context.Background(), prober, nil, time.Second)
+		if len(results) != 3 {
+			t.Fatalf("Expected 3 results, Found: %v", results)
+		}
+		if r := results["liveness"]; r.Result != api.Success || r.Err != nil {
+			t.Errorf("Expected liveness to pass, Found: %v, %v", r.Result, r.Err)
+		}
+		if r := results["readiness"]; r.Result != api.Failure || r.Err == nil || !strings.Contains(r.Err.Error(), "statuscode: 503") {
+			t.Errorf("Expected readiness to fail with 503, Found: %v, %v", r.Result, r.Err)
+		}
+		if r := results["broken"]; r.Result != api.Unknown || r.Err == nil {
+			t.Errorf("Expected the unresolvable probe to be Unknown, Found: %v, %v", r.Result, r.Err)
+		}
+		if m := atomic.LoadInt32(&maxInFlight); parallel != (m > 1) {
+			t.Errorf("Expected parallel=%v, Found: %d probes in flight", parallel, m)
+		}
+	}
+}
+
+func TestProbeRunner(t *testing.T) {
+	var status atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer server.Close()
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	handler := &prober_v1.Handler{HTTPGet: &core.HTTPGetAction{Scheme: core.URISchemeHTTP, Host: host, Port: intstr.FromInt(port)}}
+	pb := NewProber(nil)
+
+	type step struct {
+		status int
+		after  time.Duration
+		phase  Phase
+		ready  bool
+		failed bool
+	}
+	tests := []struct {
+		name  string
+		opts  RunnerOptions
+		steps []step
+	}{
+		{
+			"startup ends with the first pass",
+			RunnerOptions{FailureThreshold: 2, StartupFailureThreshold: 5},
+			[]step{
+				{http.StatusServiceUnavailable, 0, PhaseStartup, false, false},
+				{http.StatusServiceUnavailable, time.Second, PhaseStartup, false, false},
+				{http.StatusServiceUnavailable, time.Second, PhaseStartup, false, false},
+				{http.StatusServiceUnavailable, time.Second, PhaseStartup, false, false},
+				{http.StatusOK, time.Second, PhaseSteady, true, false},
+				{http.StatusServiceUnavailable, time.Second, PhaseSteady, true, false},
+				{http.StatusServiceUnavailable, time.Second, PhaseSteady, false, true},
+				{http.StatusOK, time.Second, PhaseSteady, true, false},
+			},
+		},
+		{
+			"startup fails",
+			RunnerOptions{StartupFailureThreshold: 2},
+			[]step{
+				{http.StatusServiceUnavailable, 0, PhaseStartup, false, false},
+				{http.StatusServiceUnavailable, time.Second, PhaseStartup, false, true},
+			},
+		},
+		{
+			"startup window",
+			RunnerOptions{FailureThreshold: 2, StartupFailureThreshold: 10, StartupWindow: 10 * time.Second},
+			[]step{
+				{http.StatusServiceUnavailable, 0, PhaseStartup, false, false},
+				{http.StatusServiceUnavailable, 5 * time.Second, PhaseStartup, false, false},
+				{http.StatusServiceUnavailable, 5 * time.Second, PhaseSteady, false, true},
+			},
+		},
+		{
+			"success threshold",
+			RunnerOptions{SuccessThreshold: 2},
+			[]step{
+				{http.StatusOK, 0, PhaseSteady, false, false},
+				{http.StatusOK, time.Second, PhaseSteady, true, false},
+			},
+		},
+	}
+	for _, tt := range tests {
+		runner, err := pb.NewProbeRunner(handler, nil, tt.opts)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		now := time.Now()
+		runner.now = func() time.Time { return now }
+		for i, s := range tt.steps {
+			status.Store(int32(s.status))
+			now = now.Add(s.after)
+			r := runner.Run(context.TODO(), time.Second)
+			if r.Phase != s.phase || r.Ready != s.ready || r.Failed != s.failed {
+				t.Errorf("%s: run %d: Expected phase=%s ready=%v failed=%v, Found: phase=%s ready=%v failed=%v (%v)",
+					tt.name, i, s.phase, s.ready, s.failed, r.Phase, r.Ready, r.Failed, r.Err)
+			}
+		}
+	}
+}
+
+func TestHTTPPostSignature(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write(body)
+		if r.Header.Get("X-Hub-Signature") != hex.EncodeToString(mac.Sum(nil)) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	pod := &core.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "demo"}}
+	post := func(sig *prober_v1.HMACSignature) *prober_v1.Handler {
+		return &prober_v1.Handler{HTTPPost: &prober_v1.HTTPPostAction{
+			Scheme: core.URISchemeHTTP, Host: host, Port: intstr.FromInt(port), Body: "ping", Signature: sig,
+		}}
+	}
+	signature := func(name, key string, optional bool) *prober_v1.HMACSignature {
+		return &prober_v1.HMACSignature{
+			KeySecretRef: core.SecretKeySelector{LocalObjectReference: core.LocalObjectReference{Name: name}, Key: key, Optional: &optional},
+			Header:       "X-Hub-Signature",
+		}
+	}
+
+	// the signature of the action overrides the signer of the prober
+	prober := NewProber(nil)
+	prober.HttpPost = httpprobe.NewPostWithOptions(nil, false, httpprobe.Options{Signer: &httpprobe.HMACSigner{Key: []byte("other")}})
+	var reads atomic.Int32
+	prober.secrets.get = func(ctx context.Context, namespace, name string) (*core.Secret, error) {
+		reads.Add(1)
+		if namespace != "demo" || name != "hmac" {
+			return nil, apierrors.NewNotFound(core.Resource("secrets"), name)
+		}
+		return &core.Secret{Data: map[string][]byte{"key": []byte("s3cret")}}, nil
+	}
+	for i := 0; i < 2; i++ {
+		if err := prober.RunProbe(post(signature("hmac", "key", false)), pod, time.Second); err != nil {
+			t.Errorf("Expected a signed probe to pass, Found: %v", err)
+		}
+	}
+	if n := reads.Load(); n != 1 {
+		t.Errorf("Expected the secret to be read once, Found: %d reads", n)
+	}
+	if err := prober.RunProbe(post(nil), pod, time.Second); err == nil {
+		t.Errorf("Expected the signer of the prober to be used without a signature")
+	}
+
+	testCases := map[string]struct {
+		signature *prober_v1.HMACSignature
+		pod       *core.Pod
+		err       string
+	}{
+		"missing secret": {signature("missing", "key", false), pod, `failed to get secret demo/missing: secrets "missing" not found`},
+		"missing key":    {signature("hmac", "other", false), pod, "secret demo/hmac has no key other"},
+		"no pod":         {signature("hmac", "key", false), nil, "no pod to read secret hmac from"},
+	}
+	for name, tt := range testCases {
+		t.Run(name, func(t *testing.T) {
+			cp, err := prober.Compile(post(tt.signature), tt.pod)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			res, err := cp.run(context.TODO(), time.Second)
+			if res != api.Unknown || err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("Expected Unknown with %q, Found: %v, %v", tt.err, res, err)
+			}
+		})
+	}
+	// an optional secret that is missing leaves the signer of the prober in place
+	if err := prober.RunProbe(post(signature("missing", "key", true)), pod, time.Second); err == nil || !strings.Contains(err.Error(), "401") {
+		t.Errorf("Expected the signer of the prober to be used for a missing optional secret, Found: %v", err)
+	}
+}
+
+func TestTCPPasswordSecret(t *testing.T) {
+	// a Redis server that requires the password s3cret
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				authenticated := false
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					switch {
+					case scanner.Text() == "s3cret":
+						authenticated = true
+						fmt.Fprint(conn, "+OK\r\n")
+					case scanner.Text() == "PING" && authenticated:
+						fmt.Fprint(conn, "+PONG\r\n")
+					case scanner.Text() == "PING":
+						fmt.Fprint(conn, "-NOAUTH Authentication required.\r\n")
+					}
+				}
+			}()
+		}
+	}()
+	port := ln.Addr().(*net.TCPAddr).Port
+	pod := &core.Pod{ObjectMeta: metav1.ObjectMeta{Name: "redis-0", Namespace: "demo"}}
+	tcp := func(secret string) *prober_v1.Handler {
+		h := &prober_v1.Handler{
+			TCPSocket:        &core.TCPSocketAction{Host: "127.0.0.1", Port: intstr.FromInt(port)},
+			TCPSocketOptions: &prober_v1.TCPSocketOptions{Protocol: "redis"},
+		}
+		if secret != "" {
+			h.TCPSocketOptions.Credentials = &prober_v1.TCPCredentials{
+				PasswordSecretRef: core.SecretKeySelector{LocalObjectReference: core.LocalObjectReference{Name: secret}, Key: "password"},
+			}
+		}
+		return h
+	}
+
+	prober := NewProber(nil)
+	prober.secrets.get = func(ctx context.Context, namespace, name string) (*core.Secret, error) {
+		if namespace != "demo" || name != "redis" {
+			return nil, apierrors.NewNotFound(core.Resource("secrets"), name)
+		}
+		return &core.Secret{Data: map[string][]byte{"password": []byte("s3cret")}}, nil
+	}
+	if err := prober.RunProbe(tcp("redis"), pod, time.Second); err != nil {
+		t.Errorf("Expected the password from the secret to authenticate, Found: %v", err)
+	}
+	if err := prober.RunProbe(tcp(""), pod, time.Second); err == nil || !strings.Contains(err.Error(), "NOAUTH") {
+		t.Errorf("Expected the probe to fail without credentials, Found: %v", err)
+	}
+	cp, err := prober.Compile(tcp("missing"), pod)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	res, err := cp.run(context.TODO(), time.Second)
+	if res != api.Unknown || err == nil || !strings.Contains(err.Error(), `failed to get secret demo/missing: secrets "missing" not found`) {
+		t.Errorf("Expected Unknown for a missing secret, Found: %v, %v", res, err)
+	}
+}
+
+func TestHTTPExpectStatusText(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	prober := NewProber(nil)
+	prober.HttpGet = httpprobe.NewGetWithOptions(nil, false, httpprobe.Options{ExpectStatusText: regexp.MustCompile("WARMING")})
+	get := func(expr string) *prober_v1.Handler {
+		return &prober_v1.Handler{HTTPGet: &core.HTTPGetAction{
+			Scheme: core.URISchemeHTTP, Host: host, Port: intstr.FromInt(port),
+		}, HTTPGetOptions: &prober_v1.HTTPGetOptions{ExpectStatusText: expr}}
+	}
+
+	// the expression of the action overrides the one of the prober
+	if err := prober.RunProbe(get("^200 OK$"), nil, time.Second); err != nil {
+		t.Errorf("Expected the status line to match, Found: %v", err)
+	}
+	if err := prober.RunProbe(get(""), nil, time.Second); err == nil || !strings.Contains(err.Error(), `does not match "WARMING"`) {
+		t.Errorf("Expected the expression of the prober to be used, Found: %v", err)
+	}
+	post := &prober_v1.Handler{HTTPPost: &prober_v1.HTTPPostAction{
+		Scheme: core.URISchemeHTTP, Host: host, Port: intstr.FromInt(port), ExpectStatusText: "WARMING",
+	}}
+	if err := prober.RunProbe(post, nil, time.Second); err == nil || !strings.Contains(err.Error(), `status line "200 OK" does not match`) {
+		t.Errorf("Expected the status line of the POST probe not to match, Found: %v", err)
+	}
+	if _, err := prober.Compile(get("("), nil); err == nil || !strings.Contains(err.Error(), "invalid expectStatusText") {
+		t.Errorf("Expected an invalid expression to fail to compile, Found: %v", err)
+	}
+}
+
+func TestHTTPForbidResponseHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Debug", "1")
+	}))
+	defer server.Close()
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	prober := NewProber(nil)
+	prober.HttpGet = httpprobe.NewGetWithOptions(nil, false, httpprobe.Options{ForbidResponseHeaders: []string{"X-Powered-By"}})
+	get := func(forbid ...string) *prober_v1.Handler {
+		return &prober_v1.Handler{HTTPGet: &core.HTTPGetAction{
+			Scheme: core.URISchemeHTTP, Host: host, Port: intstr.FromInt(port),
+		}, HTTPGetOptions: &prober_v1.HTTPGetOptions{ForbidResponseHeaders: forbid}}
+	}
+
+	if err := prober.RunProbe(get(), nil, time.Second); err != nil {
+		t.Errorf("Expected the probe to pass, Found: %v", err)
+	}
+	if err := prober.RunProbe(get("x-debug"), nil, time.Second); err == nil || !strings.Contains(err.Error(), "forbidden response header X-Debug is present") {
+		t.Errorf("Expected the header forbidden by the action to fail the probe, Found: %v", err)
+	}
+	post := &prober_v1.Handler{HTTPPost: &prober_v1.HTTPPostAction{
+		Scheme: core.URISchemeHTTP, Host: host, Port: intstr.FromInt(port), ForbidResponseHeaders: []string{"X-Debug"},
+	}}
+	if err := prober.RunProbe(post, nil, time.Second); err == nil || !strings.Contains(err.Error(), "forbidden response header X-Debug is present") {
+		t.Errorf("Expected the header forbidden by the action to fail the POST probe, Found: %v", err)
+	}
+}
+
+func TestHTTPExpectBodySHA256(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	sum := sha256.Sum256([]byte("hello"))
+	prober := NewProber(nil)
+	prober.HttpPost = httpprobe.NewPostWithOptions(nil, false, httpprobe.Options{ExpectBodySHA256: strings.Repeat("0", 64)})
+	post := func(hash string) *prober_v1.Handler {
+		return &prober_v1.Handler{HTTPPost: &prober_v1.HTTPPostAction{
+			Scheme: core.URISchemeHTTP, Host: host, Port: intstr.FromInt(port), ExpectBodySHA256: hash,
+		}}
+	}
+
+	// the hash of the action overrides the one of the prober
+	if err := prober.RunProbe(post(hex.EncodeToString(sum[:])), nil, time.Second); err != nil {
+		t.Errorf("Expected the body hash to match, Found: %v", err)
+	}
+	if err := prober.RunProbe(post(""), nil, time.Second); err == nil || !strings.Contains(err.Error(), "expected body SHA-256 0000") {
+		t.Errorf("Expected the hash of the prober to be used, Found: %v", err)
+	}
+	get := &prober_v1.Handler{HTTPGet: &core.HTTPGetAction{
+		Scheme: core.URISchemeHTTP, Host: host, Port: intstr.FromInt(port),
+	}, HTTPGetOptions: &prober_v1.HTTPGetOptions{ExpectBodySHA256: strings.Repeat("f", 64)}}
+	if err := prober.RunProbe(get, nil, time.Second); err == nil || !strings.Contains(err.Error(), "expected body SHA-256 ffff") {
+		t.Errorf("Expected the hash of the GET action to be checked, Found: %v", err)
+	}
+}
+
+func TestHTTPCloseConnection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !r.Close {
+			w.WriteHeader(http.StatusPreconditionFailed)
+		}
+	}))
+	defer server.Close()
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	prober := NewProber(nil)
+	prober.HttpGet = httpprobe.NewGetWithOptions(nil, false, httpprobe.Options{EnableKeepAlives: true})
+	get := func(closeConn bool) *prober_v1.Handler {
+		return &prober_v1.Handler{HTTPGet: &core.HTTPGetAction{
+			Scheme: core.URISchemeHTTP, Host: host, Port: intstr.FromInt(port),
+		}, HTTPGetOptions: &prober_v1.HTTPGetOptions{CloseConnection: closeConn}}
+	}
+
+	if err := prober.RunProbe(get(true), nil, time.Second); err != nil {
+		t.Errorf("Expected the request to close the connection, Found: %v", err)
+	}
+	if err := prober.RunProbe(get(false), nil, time.Second); err == nil {
+		t.Errorf("Expected the request to keep the connection alive")
+	}
+	post := &prober_v1.Handler{HTTPPost: &prober_v1.HTTPPostAction{
+		Scheme: core.URISchemeHTTP, Host: host, Port: intstr.FromInt(port), CloseConnection: true,
+	}}
+	if err := prober.RunProbe(post, nil, time.Second); err != nil {
+		t.Errorf("Expected the POST request to close the connection, Found: %v", err)
+	}
+}
+
+func TestHTTPPostTrailers(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		if r.Trailer.Get("X-Checksum") != "abc" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	prober := NewProber(nil)
+	prober.HttpPost = httpprobe.NewPostWithOptions(nil, false, httpprobe.Options{RequestTrailers: http.Header{"X-Checksum": {"old"}}})
+	post := func(trailers ...core.HTTPHeader) *prober_v1.Handler {
+		return &prober_v1.Handler{HTTPPost: &prober_v1.HTTPPostAction{
+			Scheme: core.URISchemeHTTP, Host: host, Port: intstr.FromInt(port), Body: "ping", Trailers: trailers,
+		}}
+	}
+
+	// the trailers of the action override those of the prober
+	if err := prober.RunProbe(post(core.HTTPHeader{Name: "X-Checksum", Value: "abc"}), nil, time.Second); err != nil {
+		t.Errorf("Expected the trailers of the action to be sent, Found: %v", err)
+	}
+	if err := prober.RunProbe(post(), nil, time.Second); err == nil {
+		t.Errorf("Expected the trailers of the prober to be sent")
+	}
+}
+
+func TestHTTPExpectServerContains(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "envoy/1.28")
+	}))
+	defer server.Close()
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	prober := NewProber(nil)
+	prober.HttpGet = httpprobe.NewGetWithOptions(nil, false, httpprobe.Options{ExpectServerContains: "nginx"})
+	get := func(text string) *prober_v1.Handler {
+		return &prober_v1.Handler{HTTPGet: &core.HTTPGetAction{
+			Scheme: core.URISchemeHTTP, Host: host, Port: intstr.FromInt(port),
+		}, HTTPGetOptions: &prober_v1.HTTPGetOptions{ExpectServerContains: text}}
+	}
+
+	// the text of the action overrides the one of the prober
+	if err := prober.RunProbe(get("Envoy"), nil, time.Second); err != nil {
+		t.Errorf("Expected the Server header to match, Found: %v", err)
+	}
+	if err := prober.RunProbe(get(""), nil, time.Second); err == nil || !strings.Contains(err.Error(), `expected the Server header to contain "nginx"`) {
+		t.Errorf("Expected the text of the prober to be used, Found: %v", err)
+	}
+	post := &prober_v1.Handler{HTTPPost: &prober_v1.HTTPPostAction{
+		Scheme: core.URISchemeHTTP, Host: host, Port: intstr.FromInt(port), ExpectServerContains: "haproxy",
+	}}
+	if err := prober.RunProbe(post, nil, time.Second); err == nil || !strings.Contains(err.Error(), `expected the Server header to contain "haproxy"`) {
+		t.Errorf("Expected the text of the POST action to be checked, Found: %v", err)
+	}
+}
+
+func TestHTTPExpectCharset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=iso-8859-1")
+		_, _ = w.Write([]byte("caf\xe9"))
+	}))
+	defer server.Close()
+	host, portStr, _ := net.SplitHostPort(server.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	prober := NewProber(nil)
+	prober.HttpGet = httpprobe.NewGetWithOptions(nil, false, httpprobe.Options{ExpectCharset: "utf-8"})
+	get := func(charset string) *prober_v1.Handler {
+		return &prober_v1.Handler{HTTPGet: &core.HTTPGetAction{
+			Scheme: core.URISchemeHTTP, Host: host, Port: intstr.FromInt(port),
+		}, HTTPGetOptions: &prober_v1.HTTPGetOptions{ExpectCharset: charset}}
+	}
+
+	// the charset of the action overrides the one of the prober
+	if err := prober.RunProbe(get("latin1"), nil, time.Second); err != nil {
+		t.Errorf("Expected the charset to match, Found: %v", err)
+	}
+	if err := prober.RunProbe(get(""), nil, time.Second); err == nil || !strings.Contains(err.Error(), "response declares charset iso-8859-1, expected utf-8") {
+		t.Errorf("Expected the charset of the prober to be used, Found: %v", err)
+	}
+	post := &prober_v1.Handler{HTTPPost: &prober_v1.HTTPPostAction{
+		Scheme: core.URISchemeHTTP, Host: host, Port: intstr.FromInt(port), RequireValidUTF8: true,
+	}}
+	if err := NewProber(nil).RunProbe(post, nil, time.Second); err == nil || !strings.Contains(err.Error(), "response declares charset iso-8859-1, expected UTF-8") {
+		t.Errorf("Expected the POST action to require UTF-8, Found: %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+	api_v1 "kmodules.xyz/prober/api/v1"
+	dnsprobe "kmodules.xyz/prober/probe/dns"
+	execprobe "kmodules.xyz/prober/probe/exec"
+	grpcprobe "kmodules.xyz/prober/probe/grpc"
+	httpprobe "kmodules.xyz/prober/probe/http"
+	tcpprobe "kmodules.xyz/prober/probe/tcp"
+)
+
+// Kinds of the built-in probes. These are also used in the error messages returned by RunProbe.
+const (
+	KindExec     = api_v1.ProbeKindExec
+	KindHTTPGet  = api_v1.ProbeKindHTTPGet
+	KindHTTPPost = api_v1.ProbeKindHTTPPost
+	KindTCP      = api_v1.ProbeKindTCP
+	KindGRPC     = "grpc"
+	KindDNS      = "dns"
+)
+
+// ProbeFunc runs a single probe of a registered kind against target.
+// pb is the Prober that dispatched the call, so implementations may reuse its configuration.
+type ProbeFunc func(ctx context.Context, pb *Prober, target api.Target) (api.Result, string, error)
+
+var (
+	registryLock sync.RWMutex
+	registry     = map[string]ProbeFunc{}
+)
+
+func init() {
+	RegisterProbe(KindExec, func(ctx context.Context, pb *Prober, target api.Target) (api.Result, string, error) {
+		return execprobe.NewTargetProber(pb.Exec).Probe(ctx, target)
+	})
+	RegisterProbe(KindHTTPGet, func(ctx context.Context, pb *Prober, target api.Target) (api.Result, string, error) {
+		return httpprobe.NewGetTargetProber(pb.HttpGet).Probe(ctx, target)
+	})
+	RegisterProbe(KindHTTPPost, func(ctx context.Context, pb *Prober, target api.Target) (api.Result, string, error) {
+		return httpprobe.NewPostTargetProber(pb.HttpPost).Probe(ctx, target)
+	})
+	RegisterProbe(KindTCP, func(ctx context.Context, pb *Prober, target api.Target) (api.Result, string, error) {
+		return tcpprobe.NewTargetProber(pb.Tcp).Probe(ctx, target)
+	})
+	RegisterProbe(KindGRPC, func(ctx context.Context, pb *Prober, target api.Target) (api.Result, string, error) {
+		return grpcprobe.NewTargetProber(pb.Grpc).Probe(ctx, target)
+	})
+	RegisterProbe(KindDNS, func(ctx context.Context, pb *Prober, target api.Target) (api.Result, string, error) {
+		return dnsprobe.NewTargetProber(pb.DNS).Probe(ctx, target)
+	})
+}
+
+// RegisterProbe registers impl as the handler for probes of the given kind.
+// Registering a kind again replaces the previous handler, which also allows overriding the built-in kinds.
+func RegisterProbe(kind string, impl ProbeFunc) {
+	if impl == nil {
+		panic(fmt.Sprintf("probe: nil ProbeFunc registered for kind %q", kind))
+	}
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	registry[kind] = impl
+}
+
+// RegisteredKinds returns the kinds that currently have a registered handler.
+func RegisteredKinds() []string {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	kinds := make([]string, 0, len(registry))
+	for kind := range registry {
+		kinds = append(kinds, kind)
+	}
+	return kinds
+}
+
+func lookupProbe(kind string) (ProbeFunc, bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	impl, ok := registry[kind]
+	return impl, ok
+}
+
+// RunKind dispatches target to the handler registered for kind.
+// It returns Unknown if no handler has been registered for kind, or if a Secret referenced by target cannot be read.
+// A target without a timeout gets DefaultTimeout.
+func (pb *Prober) RunKind(ctx context.Context, kind string, target api.Target) (api.Result, string, error) {
+	impl, ok := lookupProbe(kind)
+	if !ok {
+		return api.Unknown, "", fmt.Errorf("no probe registered for kind %q", kind)
+	}
+	if target.Config == nil {
+		target.Config = pb.Config
+	}
+	target.Timeout = pb.timeout(target.Timeout)
+	start := time.Now()
+	if err := pb.readSecrets(ctx, &target); err != nil {
+		pb.record(kind, target, api.Unknown, "", err)
+		pb.observe(ctx, kind, target, api.Unknown, err, time.Since(start))
+		return api.Unknown, "", err
+	}
+	// the wait for a slot counts against the timeout of the probe
+	release, timeout, err := pb.acquire(ctx, targetKey(target), target.Timeout)
+	if err != nil {
+		pb.record(kind, target, api.Unknown, "", err)
+		pb.observe(ctx, kind, target, api.Unknown, err, time.Since(start))
+		return api.Unknown, "", err
+	}
+	defer release()
+	target.Timeout = timeout
+	id := pb.withRequestID(&target)
+	res, out, err := impl(ctx, pb, target)
+	out = appendRequestID(out, id)
+	pb.record(kind, target, res, out, err)
+	pb.recordEvent(kind, target, res, out, err)
+	pb.observe(ctx, kind, target, res, err, time.Since(start))
+	return res, out, err
+}
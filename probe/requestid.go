@@ -0,0 +1,53 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"fmt"
+	"net/http"
+
+	api "kmodules.xyz/prober/api"
+
+	"github.com/google/uuid"
+)
+
+// withRequestID sets the RequestIDHeader of an HTTP probe target to a new UUID and returns the ID,
+// or an empty ID if RequestIDHeader is not set or target is not an HTTP probe.
+func (pb *Prober) withRequestID(target *api.Target) string {
+	if pb.RequestIDHeader == "" || target.URL == nil {
+		return ""
+	}
+	id := uuid.NewString()
+	headers := target.Headers.Clone()
+	if headers == nil {
+		headers = make(http.Header)
+	}
+	headers.Set(pb.RequestIDHeader, id)
+	target.Headers = headers
+	return id
+}
+
+// appendRequestID appends the request ID of a probe to its output.
+func appendRequestID(out, id string) string {
+	if id == "" {
+		return out
+	}
+	if out == "" {
+		return fmt.Sprintf("[request id %s]", id)
+	}
+	return fmt.Sprintf("%s\n[request id %s]", out, id)
+}
@@ -0,0 +1,155 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+	api_v1 "kmodules.xyz/prober/api/v1"
+
+	core "k8s.io/api/core/v1"
+)
+
+// Phase is the phase of a ProbeRunner.
+type Phase string
+
+const (
+	// PhaseStartup is the initial phase, in which StartupFailureThreshold applies, e.g. while a slow app warms up.
+	PhaseStartup Phase = "Startup"
+	// PhaseSteady follows the startup phase, with the normal thresholds.
+	PhaseSteady Phase = "Steady"
+)
+
+// RunnerOptions are the thresholds of a ProbeRunner, modeled after the startup, readiness and
+// liveness probes of Kubernetes.
+type RunnerOptions struct {
+	// FailureThreshold is the number of consecutive runs that do not pass after which the probe is failed
+	// in the steady phase. Defaults to 3.
+	FailureThreshold int
+	// SuccessThreshold is the number of consecutive passing runs after which the probe is ready. Defaults to 1.
+	SuccessThreshold int
+	// StartupFailureThreshold is the failure threshold of the startup phase, usually higher than
+	// FailureThreshold to give slow starting apps time. Defaults to FailureThreshold.
+	StartupFailureThreshold int
+	// StartupWindow bounds the startup phase, which otherwise lasts until the first passing run,
+	// like a Kubernetes startup probe. It is measured from the first run.
+	StartupWindow time.Duration
+}
+
+// RunnerResult is the outcome of a run of a ProbeRunner.
+type RunnerResult struct {
+	// Result is the result of this run, and Err describes why it did not pass, as returned by Prober.RunProbe.
+	Result api.Result
+	Err    error
+	// Phase is the phase this run was evaluated in. The run that ends the startup phase with a pass
+	// is evaluated in the steady phase.
+	Phase Phase
+	// Ready is true once SuccessThreshold consecutive runs passed, until the probe fails.
+	Ready bool
+	// Failed is true if the consecutive runs that did not pass reached the failure threshold of the phase.
+	Failed bool
+	// ConsecutiveSuccesses and ConsecutiveFailures count the passing and other runs up to this one.
+	ConsecutiveSuccesses int
+	ConsecutiveFailures  int
+}
+
+// ProbeRunner runs a probe repeatedly and applies thresholds to its results, with a more tolerant
+// failure threshold while the target starts up. It is safe for concurrent use, though runs are
+// meant to be sequential, e.g. once per period.
+type ProbeRunner struct {
+	cp   *CompiledProbe
+	opts RunnerOptions
+	now  func() time.Time
+
+	lock      sync.Mutex
+	started   time.Time
+	phase     Phase
+	ready     bool
+	successes int
+	failures  int
+}
+
+// NewProbeRunner compiles the probe described by probes against pod and returns a ProbeRunner for it
+// in the startup phase.
+func (pb *Prober) NewProbeRunner(probes *api_v1.Handler, pod *core.Pod, opts RunnerOptions) (*ProbeRunner, error) {
+	cp, err := pb.Compile(probes, pod)
+	if err != nil {
+		return nil, err
+	}
+	if opts.FailureThreshold <= 0 {
+		opts.FailureThreshold = 3
+	}
+	if opts.SuccessThreshold <= 0 {
+		opts.SuccessThreshold = 1
+	}
+	if opts.StartupFailureThreshold <= 0 {
+		opts.StartupFailureThreshold = opts.FailureThreshold
+	}
+	return &ProbeRunner{cp: cp, opts: opts, now: time.Now, phase: PhaseStartup}, nil
+}
+
+// Run runs the probe once, bounded by timeout, and returns its result together with the state of the runner.
+func (r *ProbeRunner) Run(ctx context.Context, timeout time.Duration) RunnerResult {
+	result, err := r.cp.run(ctx, timeout)
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	now := r.now()
+	if r.started.IsZero() {
+		r.started = now
+	}
+	passed := result == api.Success || result == api.Warning
+	if r.phase == PhaseStartup && (passed || (r.opts.StartupWindow > 0 && now.Sub(r.started) >= r.opts.StartupWindow)) {
+		r.phase = PhaseSteady
+	}
+	threshold := r.opts.FailureThreshold
+	if r.phase == PhaseStartup {
+		threshold = r.opts.StartupFailureThreshold
+	}
+	if passed {
+		r.successes++
+		r.failures = 0
+		if r.successes >= r.opts.SuccessThreshold {
+			r.ready = true
+		}
+	} else {
+		r.failures++
+		r.successes = 0
+		if r.failures >= threshold {
+			r.ready = false
+		}
+	}
+	return RunnerResult{
+		Result:               result,
+		Err:                  err,
+		Phase:                r.phase,
+		Ready:                r.ready,
+		Failed:               r.failures >= threshold,
+		ConsecutiveSuccesses: r.successes,
+		ConsecutiveFailures:  r.failures,
+	}
+}
+
+// Phase returns the current phase of the runner.
+func (r *ProbeRunner) Phase() Phase {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.phase
+}
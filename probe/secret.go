@@ -0,0 +1,232 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sync"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+
+	core "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// DefaultSecretCacheTTL is how long a certificate loaded from a Secret is used before the Secret is read again.
+	DefaultSecretCacheTTL = time.Minute
+	// DefaultSecretReadTimeout bounds a read of the Secret when ClientCertSecret.Timeout is not set.
+	DefaultSecretReadTimeout = 10 * time.Second
+)
+
+// ClientCertSecret references a Secret holding a TLS client certificate, e.g. one of type kubernetes.io/tls.
+type ClientCertSecret struct {
+	Namespace string
+	Name      string
+	// CertKey and KeyKey are the keys of the PEM encoded certificate and private key.
+	// They default to tls.crt and tls.key.
+	CertKey string
+	KeyKey  string
+	// TTL is how long the loaded certificate is cached. Defaults to DefaultSecretCacheTTL.
+	TTL time.Duration
+	// Timeout bounds a read of the Secret, so that a slow API server fails the TLS handshake of the probe
+	// rather than blocking it. Defaults to DefaultSecretReadTimeout.
+	Timeout time.Duration
+}
+
+// SecretCertificate loads a TLS client certificate from a Secret for mTLS probes. It caches the
+// certificate for the TTL of the reference, so that a rotated certificate is picked up after at most a TTL.
+// Its ClientCertificate method is meant for the ClientCertificate option of the HTTP probers:
+//
+//	certs, err := probe.NewSecretCertificate(config, ref)
+//	...
+//	pb.HttpGet = httpprobe.NewGetWithOptions(tlsConfig, false, httpprobe.Options{ClientCertificate: certs.ClientCertificate})
+type SecretCertificate struct {
+	ref       ClientCertSecret
+	getSecret func(ctx context.Context, namespace, name string) (*core.Secret, error)
+
+	lock    sync.Mutex
+	cert    *tls.Certificate
+	expires time.Time
+}
+
+// NewSecretCertificate returns a SecretCertificate that reads the Secret through the API server of config.
+func NewSecretCertificate(config *rest.Config, ref ClientCertSecret) (*SecretCertificate, error) {
+	kc, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client. Error: %v", err.Error())
+	}
+	return newSecretCertificate(ref, func(ctx context.Context, namespace, name string) (*core.Secret, error) {
+		return kc.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	}), nil
+}
+
+func newSecretCertificate(ref ClientCertSecret, getSecret func(ctx context.Context, namespace, name string) (*core.Secret, error)) *SecretCertificate {
+	if ref.CertKey == "" {
+		ref.CertKey = core.TLSCertKey
+	}
+	if ref.KeyKey == "" {
+		ref.KeyKey = core.TLSPrivateKeyKey
+	}
+	if ref.TTL <= 0 {
+		ref.TTL = DefaultSecretCacheTTL
+	}
+	if ref.Timeout <= 0 {
+		ref.Timeout = DefaultSecretReadTimeout
+	}
+	return &SecretCertificate{ref: ref, getSecret: getSecret}
+}
+
+// ClientCertificate returns the cached certificate, reading the Secret again once the cache has expired.
+// Errors, e.g. for a missing Secret or a read that times out, are not cached.
+func (s *SecretCertificate) ClientCertificate() (*tls.Certificate, error) {
+	s.lock.Lock()
+	cert, expires := s.cert, s.expires
+	s.lock.Unlock()
+	if cert != nil && time.Now().Before(expires) {
+		return cert, nil
+	}
+
+	// The lock is not held while reading, so that a slow read only holds up the probes that need the Secret.
+	ctx, cancel := context.WithTimeout(context.Background(), s.ref.Timeout)
+	defer cancel()
+	secret, err := s.getSecret(ctx, s.ref.Namespace, s.ref.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret %s/%s: %w", s.ref.Namespace, s.ref.Name, err)
+	}
+	loaded, err := tls.X509KeyPair(secret.Data[s.ref.CertKey], secret.Data[s.ref.KeyKey])
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate from secret %s/%s: %w", s.ref.Namespace, s.ref.Name, err)
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.cert, s.expires = &loaded, time.Now().Add(s.ref.TTL)
+	return s.cert, nil
+}
+
+// secretCache caches the Secrets referenced by probes, e.g. for the signing key of an HTTPPostAction.
+type secretCache struct {
+	// get reads a Secret. Nil reads it through the API server of Prober.Config.
+	get func(ctx context.Context, namespace, name string) (*core.Secret, error)
+
+	lock    sync.Mutex
+	secrets map[string]cachedSecret
+}
+
+type cachedSecret struct {
+	data    map[string][]byte
+	expires time.Time
+}
+
+// store caches data for the Secret of key, and forgets the Secrets that have expired.
+func (c *secretCache) store(key string, data map[string][]byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := time.Now()
+	if c.secrets == nil {
+		c.secrets = map[string]cachedSecret{}
+	}
+	for k, cached := range c.secrets {
+		if !now.Before(cached.expires) {
+			delete(c.secrets, k)
+		}
+	}
+	c.secrets[key] = cachedSecret{data: data, expires: now.Add(DefaultSecretCacheTTL)}
+}
+
+func (c *secretCache) load(key string) (map[string][]byte, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	cached, ok := c.secrets[key]
+	if !ok || !time.Now().Before(cached.expires) {
+		return nil, false
+	}
+	return cached.data, true
+}
+
+// readSecrets sets the values that target references in Secrets. The Secrets are read from the namespace
+// of Target.Pod.
+func (pb *Prober) readSecrets(ctx context.Context, target *api.Target) error {
+	refs := []struct {
+		ref *core.SecretKeySelector
+		set func([]byte)
+	}{
+		{target.SigningKeySecret, func(value []byte) { target.SigningKey = value }},
+		{target.PasswordSecret, func(value []byte) { target.Password = string(value) }},
+	}
+	for _, r := range refs {
+		if r.ref == nil {
+			continue
+		}
+		if target.Pod == nil {
+			return fmt.Errorf("no pod to read secret %s from", r.ref.Name)
+		}
+		value, err := pb.secretKey(ctx, target.Pod.Namespace, r.ref)
+		if err != nil {
+			return err
+		}
+		r.set(value)
+	}
+	return nil
+}
+
+// secretKey returns the value of the key of ref in its Secret in namespace. The Secret is cached for
+// DefaultSecretCacheTTL, and read within DefaultSecretReadTimeout. A missing Secret or key is an error,
+// unless ref is optional, in which case the value is nil.
+func (pb *Prober) secretKey(ctx context.Context, namespace string, ref *core.SecretKeySelector) ([]byte, error) {
+	optional := ref.Optional != nil && *ref.Optional
+	cacheKey := namespace + "/" + ref.Name
+	data, ok := pb.secrets.load(cacheKey)
+	if !ok {
+		ctx, cancel := context.WithTimeout(ctx, DefaultSecretReadTimeout)
+		defer cancel()
+		secret, err := pb.getSecret(ctx, namespace, ref.Name)
+		if apierrors.IsNotFound(err) && optional {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get secret %s/%s: %w", namespace, ref.Name, err)
+		}
+		data = secret.Data
+		pb.secrets.store(cacheKey, data)
+	}
+	value, ok := data[ref.Key]
+	if !ok && !optional {
+		return nil, fmt.Errorf("secret %s/%s has no key %s", namespace, ref.Name, ref.Key)
+	}
+	return value, nil
+}
+
+func (pb *Prober) getSecret(ctx context.Context, namespace, name string) (*core.Secret, error) {
+	if pb.secrets.get != nil {
+		return pb.secrets.get(ctx, namespace, name)
+	}
+	if pb.Config == nil {
+		return nil, fmt.Errorf("no kubernetes config to read the secret with")
+	}
+	kc, err := kubernetes.NewForConfig(pb.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kubernetes client. Error: %v", err.Error())
+	}
+	return kc.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+}
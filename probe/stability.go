@@ -0,0 +1,75 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probe
+
+import (
+	"context"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+	api_v1 "kmodules.xyz/prober/api/v1"
+
+	core "k8s.io/api/core/v1"
+)
+
+// StabilitySummary is the outcome of Prober.ProbeStability.
+type StabilitySummary struct {
+	// Runs is the number of times the probe ran, fewer than requested if the budget ran out.
+	Runs int
+	// Results counts the runs by their result.
+	Results map[api.Result]int
+	// Stable is true if the probe ran and every run had the same result, false if it was flapping.
+	Stable bool
+	// Latencies are the durations of the runs, in order.
+	Latencies []time.Duration
+	// LastError is the error of the last run that did not pass, if any.
+	LastError error
+}
+
+// ProbeStability runs the probe described by probes against pod n times in a row, e.g. in CI to tell
+// a flapping probe from a stable one. Each run is bounded by timeout, and all runs together by budget.
+// When the budget runs out, the summary covers the runs completed before. Zero budget is unlimited.
+// It only returns an error if the probe can not be resolved against pod.
+func (pb *Prober) ProbeStability(n int, probes *api_v1.Handler, pod *core.Pod, timeout, budget time.Duration) (StabilitySummary, error) {
+	summary := StabilitySummary{Results: map[api.Result]int{}}
+	cp, err := pb.Compile(probes, pod)
+	if err != nil {
+		return summary, err
+	}
+	ctx := context.Background()
+	if budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
+	}
+	for i := 0; i < n && ctx.Err() == nil; i++ {
+		start := time.Now()
+		res, err := cp.run(ctx, timeout)
+		if ctx.Err() != nil {
+			// the run was cut short by the budget, its result says nothing about the target
+			break
+		}
+		summary.Latencies = append(summary.Latencies, time.Since(start))
+		summary.Results[res]++
+		summary.Runs++
+		if err != nil {
+			summary.LastError = err
+		}
+	}
+	summary.Stable = len(summary.Results) == 1
+	return summary, nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tcp
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+)
+
+// probeAddresses resolves the host of addr and probes each of its addresses in turn, sharing timeout
+// between them. A timeout of zero or less does not limit the probe. The result is the worst of the addresses,
+// and the output has a line per address.
+func probeAddresses(addr string, timeout time.Duration, opts *Options) (TimedResult, error) {
+	start := time.Now()
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return TimedResult{Result: api.Unknown, Output: err.Error()}, err
+	}
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	resolver := opts.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	ips, err := resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		res := TimedResult{Result: opts.failureResult(err), Output: fmt.Sprintf("failed to resolve %s: %v", host, err), ConnectDuration: time.Since(start)}
+		if opts.Invert {
+			res.Result = api.Success
+			if isTimeout(err) {
+				res.Result = api.Unknown
+			}
+		}
+		return res, nil
+	}
+
+	single := *opts
+	single.ProbeAllAddresses = false
+	if opts.TLS {
+		// Verify the certificates against the name rather than the addresses.
+		if single.TLSConfig == nil {
+			single.TLSConfig = &tls.Config{}
+		} else {
+			single.TLSConfig = single.TLSConfig.Clone()
+		}
+		if single.TLSConfig.ServerName == "" {
+			single.TLSConfig.ServerName = host
+		}
+	}
+	result := api.Success
+	lines := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		ipAddr := net.JoinHostPort(ip.String(), port)
+		var remaining time.Duration
+		if timeout > 0 {
+			if remaining = timeout - time.Since(start); remaining <= 0 {
+				skipped := opts.failureResult(context.DeadlineExceeded)
+				if opts.Invert {
+					// an address that was not probed is not known to be closed
+					skipped = api.Unknown
+				}
+				result = worse(result, skipped)
+				lines = append(lines, fmt.Sprintf("%s: not probed, the probe timed out", ipAddr))
+				continue
+			}
+		}
+		res, err := doTCPProbeTimed(ipAddr, remaining, &single)
+		if err != nil {
+			return res, err
+		}
+		result = worse(result, res.Result)
+		line := fmt.Sprintf("%s: %s", ipAddr, res.Result)
+		if res.Output != "" {
+			line += ", " + res.Output
+		}
+		lines = append(lines, line)
+	}
+	return TimedResult{Result: result, Output: strings.Join(lines, "\n"), ConnectDuration: time.Since(start)}, nil
+}
+
+// worse returns the more severe of two results, from Success over Warning and Unknown to Failure.
+func worse(a, b api.Result) api.Result {
+	severity := map[api.Result]int{api.Success: 0, api.Warning: 1, api.Unknown: 2, api.Failure: 3}
+	if severity[b] > severity[a] {
+		return b
+	}
+	return a
+}
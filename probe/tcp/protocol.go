@@ -0,0 +1,162 @@
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tcp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	api "kmodules.xyz/prober/api"
+
+	"k8s.io/klog/v2"
+)
+
+// Protocol selects a check of the application protocol that a TCP probe runs over its connection.
+type Protocol string
+
+const (
+	// ProtocolRedis sends PING, preceded by AUTH if Credentials are set, and expects +PONG.
+	ProtocolRedis Protocol = "redis"
+	// ProtocolMemcached sends the version command of the memcached text protocol and expects a VERSION reply.
+	ProtocolMemcached Protocol = "memcached"
+)
+
+// Credentials authenticate the protocol check of a TCP probe.
+type Credentials struct {
+	// Username selects a Redis 6 ACL user. Empty authenticates with the password only.
+	Username string
+	Password string
+}
+
+// String returns a description of the credentials with the password redacted, for logging.
+func (c *Credentials) String() string {
+	if c.Username == "" {
+		return "password <redacted>"
+	}
+	return fmt.Sprintf("user %s, password <redacted>", c.Username)
+}
+
+// protocolError is a reply of the server that fails the protocol check, as opposed to a network error.
+type protocolError struct {
+	protocol Protocol
+	reply    string
+}
+
+func (e *protocolError) Error() string {
+	return fmt.Sprintf("%s protocol error: %s", e.protocol, e.reply)
+}
+
+// checkProtocol runs the check of opts.Protocol over conn before deadline, if it is not zero, and returns the
+// output of the probe.
+func (opts *Options) checkProtocol(conn net.Conn, deadline time.Time) (api.Result, string) {
+	if err := conn.SetDeadline(deadline); err != nil {
+		return api.Unknown, err.Error()
+	}
+	var (
+		reply string
+		err   error
+	)
+	switch opts.Protocol {
+	case ProtocolRedis:
+		if opts.Credentials != nil {
+			klog.V(5).Infof("Redis probe of %s authenticating with %s", conn.RemoteAddr(), opts.Credentials)
+		}
+		reply, err = redisPing(conn, opts.Credentials)
+	case ProtocolMemcached:
+		if opts.Credentials != nil {
+			return api.Unknown, "memcached authentication is not supported"
+		}
+		reply, err = memcachedVersion(conn)
+	default:
+		return api.Unknown, fmt.Sprintf("unknown protocol %q", opts.Protocol)
+	}
+	var protoErr *protocolError
+	if errors.As(err, &protoErr) {
+		return api.Failure, err.Error()
+	}
+	if err != nil {
+		return opts.failureResult(err), fmt.Sprintf("%s check failed: %v", opts.Protocol, err)
+	}
+	return api.Success, fmt.Sprintf("%s: %s", opts.Protocol, reply)
+}
+
+// redisPing authenticates with creds, if set, and pings the server. It returns the reply to PING.
+func redisPing(conn net.Conn, creds *Credentials) (string, error) {
+	r := bufio.NewReader(conn)
+	if creds != nil {
+		args := []string{"AUTH", creds.Password}
+		if creds.Username != "" {
+			args = []string{"AUTH", creds.Username, creds.Password}
+		}
+		reply, err := redisCommand(conn, r, args...)
+		if err != nil {
+			return "", err
+		}
+		if reply != "+OK" {
+			return "", &protocolError{ProtocolRedis, reply}
+		}
+	}
+	reply, err := redisCommand(conn, r, "PING")
+	if err != nil {
+		return "", err
+	}
+	if reply != "+PONG" {
+		return "", &protocolError{ProtocolRedis, reply}
+	}
+	return "PONG", nil
+}
+
+// redisCommand sends a command as a RESP array of bulk strings and returns the first line of the reply.
+func redisCommand(conn net.Conn, r *bufio.Reader, args ...string) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := conn.Write([]byte(b.String())); err != nil {
+		return "", err
+	}
+	return readLine(r)
+}
+
+// memcachedVersion sends the version command and returns the reply.
+func memcachedVersion(conn net.Conn) (string, error) {
+	if _, err := conn.Write([]byte("version\r\n")); err != nil {
+		return "", err
+	}
+	reply, err := readLine(bufio.NewReader(conn))
+	if err != nil {
+		return "", err
+	}
+	if !strings.HasPrefix(reply, "VERSION ") {
+		return "", &protocolError{ProtocolMemcached, reply}
+	}
+	return reply, nil
+}
+
+// readLine reads a line terminated by CRLF and returns it without the terminator.
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r"), nil
+}
@@ -17,12 +17,18 @@ limitations under the License.
 package tcp
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
 	"net"
 	"strconv"
+	"strings"
 	"time"
 
 	api "kmodules.xyz/prober/api"
 
+	"golang.org/x/net/proxy"
 	"k8s.io/klog/v2"
 )
 
@@ -31,16 +37,158 @@ func New() Prober {
 	return tcpProber{}
 }
 
+// NewWithOptions creates Prober with additional options.
+func NewWithOptions(opts Options) Prober {
+	return tcpProber{opts}
+}
+
+// Options holds the optional settings of the TCP prober.
+// The zero value keeps the default probe behavior.
+type Options struct {
+	// SOCKS5 routes the probe connection through a SOCKS5 proxy. Defaults to dialing directly.
+	// +optional
+	SOCKS5 *api.SOCKS5Proxy
+
+	// Invert flips the probe semantics to assert that the target is down:
+	//   - a successful connect becomes a Failure,
+	//   - a dial error such as "connection refused" or "no such host" becomes a Success,
+	//   - a dial timeout is ambiguous (the port may be filtered rather than closed) and becomes Unknown.
+	// +optional
+	Invert bool
+	// MaxRejectLatency makes an inverted probe assert that the target actively rejects the connection
+	// within this latency, e.g. for a firewall that rejects rather than drops. The output reports the case:
+	//   - "fast reject": rejected within MaxRejectLatency, a Success,
+	//   - "slow reject": rejected after MaxRejectLatency, a Failure,
+	//   - "silent drop": the connect timed out, a Failure.
+	// It is only used with Invert.
+	// +optional
+	MaxRejectLatency time.Duration
+
+	// TLS completes a TLS handshake over the connection within the probe timeout. The probe then only succeeds
+	// if the handshake does, and reports the negotiated version and cipher suite as output.
+	// It is ignored when Invert is set, as a connect alone fails the probe then.
+	// +optional
+	TLS bool
+	// TLSConfig is used for the handshake when TLS is set, e.g. to set ServerName or InsecureSkipVerify.
+	// The ServerName defaults to the probed host.
+	// +optional
+	TLSConfig *tls.Config
+
+	// LocalPorts binds the probe connection to a local port of the range instead of an ephemeral port,
+	// e.g. for firewall rules keyed off the client source port. With a SOCKS5 proxy, the connection to
+	// the proxy is bound. A probe finding no free port is Unknown.
+	// +optional
+	LocalPorts *api.LocalPortRange
+
+	// KeepAlive enables TCP keepalive on the probe connection, so that a dead peer is detected on a
+	// connection held open for a while, e.g. while waiting for a read deadline. It defaults to off,
+	// as a connect check closes the connection right away. With a SOCKS5 proxy, it applies to the
+	// connection to the proxy.
+	// +optional
+	KeepAlive bool
+	// KeepAlivePeriod is the time between keepalive probes when KeepAlive is set.
+	// Zero uses the default of net.Dialer, currently 15s.
+	// +optional
+	KeepAlivePeriod time.Duration
+
+	// TimeoutResult is the result of a probe that times out connecting or completing the TLS handshake.
+	// Defaults to Failure. Set it to Warning so that slow but alive targets are reported as degraded rather than down.
+	// Other errors still fail the probe. It is not used with Invert.
+	// +optional
+	TimeoutResult api.Result
+	// ClassifyError maps the errors of connecting or completing the TLS handshake to the result of the probe,
+	// e.g. to report a refused connection as Warning. By default, they fail the probe as DefaultErrorClassifier
+	// does, and timeouts are TimeoutResult if set. TimeoutResult is not used when it is set. The TCP prober does
+	// not report failure reasons, so the returned reason is ignored. It is not used with Invert.
+	// +optional
+	ClassifyError api.ErrorClassifier
+
+	// ProbeAllAddresses resolves the host and probes each of its addresses in turn instead of dialing the
+	// name once, e.g. to find a single bad endpoint behind a DNS name. The probe succeeds only if every
+	// address does, and the output reports the result of each address on its own line. The addresses share
+	// the probe timeout; those left when it runs out are not probed and count as timed out. The host is
+	// resolved locally, also with a SOCKS5 proxy.
+	// +optional
+	ProbeAllAddresses bool
+	// Resolver resolves the host when ProbeAllAddresses is set. Defaults to net.DefaultResolver.
+	// +optional
+	Resolver *net.Resolver
+
+	// Protocol runs a check of the application protocol over the connection, after the TLS handshake if TLS
+	// is set, within the probe timeout. The probe then only succeeds if the server answers as expected:
+	//   - ProtocolRedis: PONG to PING, after AUTH succeeds if Credentials are set,
+	//   - ProtocolMemcached: a VERSION reply to the version command.
+	// An error reply or an unexpected answer fails the probe as a "<protocol> protocol error", distinct
+	// from network errors, which are handled as connect errors. It is ignored when Invert is set.
+	// +optional
+	Protocol Protocol
+	// Credentials authenticate the Protocol check. Only ProtocolRedis supports them; a memcached probe with
+	// Credentials is Unknown. The password is never logged nor reported in the output.
+	// +optional
+	Credentials *Credentials
+}
+
 // Prober is an interface that defines the Probe function for doing TCP readiness/liveness checks.
 type Prober interface {
 	Probe(host string, port int, timeout time.Duration) (api.Result, string, error)
 }
 
-type tcpProber struct{}
+// TimedProber is a Prober that also reports how long the TCP connect took.
+// The Prober returned by New and NewWithOptions implements it.
+type TimedProber interface {
+	Prober
+	ProbeTimed(host string, port int, timeout time.Duration) (TimedResult, error)
+}
+
+// TimedResult is the outcome of a TCP probe together with the measured connect latency.
+type TimedResult struct {
+	Result api.Result
+	Output string
+	// ConnectDuration is the time spent in the dial only, i.e. the L4 handshake
+	// (including the proxy handshake when a proxy is configured).
+	ConnectDuration time.Duration
+}
+
+type tcpProber struct {
+	opts Options
+}
 
 // Probe returns a ProbeRunner capable of running an TCP check.
 func (pr tcpProber) Probe(host string, port int, timeout time.Duration) (api.Result, string, error) {
-	return DoTCPProbe(net.JoinHostPort(host, strconv.Itoa(port)), timeout)
+	return doTCPProbe(net.JoinHostPort(host, strconv.Itoa(port)), timeout, &pr.opts)
+}
+
+// ProbeTimed runs a TCP check like Probe and also reports the connect latency.
+func (pr tcpProber) ProbeTimed(host string, port int, timeout time.Duration) (TimedResult, error) {
+	return doTCPProbeTimed(net.JoinHostPort(host, strconv.Itoa(port)), timeout, &pr.opts)
+}
+
+// NewTargetProber adapts a Prober to the unified api.Prober interface.
+func NewTargetProber(p Prober) api.Prober {
+	return targetProber{p}
+}
+
+type targetProber struct {
+	Prober
+}
+
+// Probe opens a TCP connection to target.Host:target.Port. The Protocol and credentials of target, if set,
+// override those of the options of a Prober of this package.
+func (pr targetProber) Probe(ctx context.Context, target api.Target) (api.Result, string, error) {
+	if err := ctx.Err(); err != nil {
+		return api.Unknown, "", err
+	}
+	if p, ok := pr.Prober.(tcpProber); ok && (target.Protocol != "" || target.Username != "" || target.Password != "") {
+		opts := p.opts
+		if target.Protocol != "" {
+			opts.Protocol = Protocol(target.Protocol)
+		}
+		if target.Username != "" || target.Password != "" {
+			opts.Credentials = &Credentials{Username: target.Username, Password: target.Password}
+		}
+		return doTCPProbe(net.JoinHostPort(target.Host, strconv.Itoa(target.Port)), api.TimeoutFor(ctx, target), &opts)
+	}
+	return pr.Prober.Probe(target.Host, target.Port, api.TimeoutFor(ctx, target))
 }
 
 // DoTCPProbe checks that a TCP socket to the address can be opened.
@@ -48,14 +196,175 @@ func (pr tcpProber) Probe(host string, port int, timeout time.Duration) (api.Res
 // If the socket fails to open, it returns Failure.
 // This is exported because some other packages may want to do direct TCP probes.
 func DoTCPProbe(addr string, timeout time.Duration) (api.Result, string, error) {
-	conn, err := net.DialTimeout("tcp", addr, timeout)
+	return doTCPProbe(addr, timeout, &Options{})
+}
+
+func doTCPProbe(addr string, timeout time.Duration, opts *Options) (api.Result, string, error) {
+	res, err := doTCPProbeTimed(addr, timeout, opts)
+	return res.Result, res.Output, err
+}
+
+func doTCPProbeTimed(addr string, timeout time.Duration, opts *Options) (TimedResult, error) {
+	if opts.ProbeAllAddresses {
+		return probeAddresses(addr, timeout, opts)
+	}
+	start := time.Now()
+	conn, err := dial(addr, timeout, opts)
+	elapsed := time.Since(start)
+	if errors.Is(err, api.ErrLocalPortsInUse) {
+		return TimedResult{Result: api.Unknown, Output: err.Error(), ConnectDuration: elapsed}, err
+	}
 	if err != nil {
+		if opts.Invert {
+			if opts.MaxRejectLatency > 0 {
+				return rejectResult(err, elapsed, opts.MaxRejectLatency), nil
+			}
+			if isTimeout(err) {
+				return TimedResult{Result: api.Unknown, Output: err.Error(), ConnectDuration: elapsed}, nil
+			}
+			return TimedResult{Result: api.Success, Output: err.Error(), ConnectDuration: elapsed}, nil
+		}
 		// Convert errors to failures to handle timeouts.
-		return api.Failure, err.Error(), nil
+		return TimedResult{Result: opts.failureResult(err), Output: err.Error(), ConnectDuration: elapsed}, nil
 	}
-	err = conn.Close()
+	defer func() {
+		if err := conn.Close(); err != nil {
+			klog.Errorf("Unexpected error closing TCP probe socket: %v (%#v)", err, err)
+		}
+	}()
+	if opts.Invert {
+		return TimedResult{
+			Result:          api.Failure,
+			Output:          fmt.Sprintf("connected to %s, but the target is expected to be down", addr),
+			ConnectDuration: elapsed,
+		}, nil
+	}
+	// The protocol check runs over the TLS connection, but closing conn alone is enough.
+	stream := conn
+	var outputs []string
+	if opts.TLS {
+		tlsConn, err := handshake(conn, addr, probeDeadline(start, timeout), opts.TLSConfig)
+		if err != nil {
+			return TimedResult{Result: opts.failureResult(err), Output: fmt.Sprintf("TLS handshake failed: %v", err), ConnectDuration: elapsed}, nil
+		}
+		state := tlsConn.ConnectionState()
+		outputs = append(outputs, fmt.Sprintf("%s, %s", tls.VersionName(state.Version), tls.CipherSuiteName(state.CipherSuite)))
+		stream = tlsConn
+	}
+	if opts.Protocol != "" {
+		result, output := opts.checkProtocol(stream, probeDeadline(start, timeout))
+		outputs = append(outputs, output)
+		if result != api.Success {
+			return TimedResult{Result: result, Output: strings.Join(outputs, "; "), ConnectDuration: elapsed}, nil
+		}
+	}
+	return TimedResult{Result: api.Success, Output: strings.Join(outputs, "; "), ConnectDuration: elapsed}, nil
+}
+
+// probeDeadline returns the deadline of a probe started at start. A zero timeout does not limit the probe,
+// as with net.DialTimeout, so its deadline is the zero time.
+func probeDeadline(start time.Time, timeout time.Duration) time.Time {
+	if timeout <= 0 {
+		return time.Time{}
+	}
+	return start.Add(timeout)
+}
+
+// handshake completes a TLS client handshake over conn before deadline, if it is not zero.
+// The connection is closed by the caller.
+func handshake(conn net.Conn, addr string, deadline time.Time, config *tls.Config) (*tls.Conn, error) {
+	if config == nil {
+		config = &tls.Config{}
+	} else {
+		config = config.Clone()
+	}
+	if config.ServerName == "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		config.ServerName = host
+	}
+	ctx := context.Background()
+	if !deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+	tlsConn := tls.Client(conn, config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// rejectResult classifies a failed connect of an inverted probe that expects a fast reject.
+func rejectResult(err error, elapsed, max time.Duration) TimedResult {
+	res := TimedResult{Result: api.Failure, ConnectDuration: elapsed}
+	switch {
+	case isTimeout(err):
+		res.Output = fmt.Sprintf("silent drop: no response after %v: %v", elapsed, err)
+	case elapsed > max:
+		res.Output = fmt.Sprintf("slow reject: rejected after %v, max %v: %v", elapsed, max, err)
+	default:
+		res.Result = api.Success
+		res.Output = fmt.Sprintf("fast reject: rejected after %v: %v", elapsed, err)
+	}
+	return res
+}
+
+// failureResult returns the result of a probe that failed with err, by ClassifyError if set,
+// or else TimeoutResult if err is a timeout.
+func (opts *Options) failureResult(err error) api.Result {
+	if opts.ClassifyError != nil {
+		result, _ := opts.ClassifyError(err)
+		return result
+	}
+	if opts.TimeoutResult != "" && isTimeout(err) {
+		return opts.TimeoutResult
+	}
+	result, _ := DefaultErrorClassifier(err)
+	return result
+}
+
+// DefaultErrorClassifier is the default mapping of the errors of TCP probes: all of them fail the probe
+// with ReasonConnectionFailed. It can be called by an Options.ClassifyError that only overrides some errors.
+func DefaultErrorClassifier(err error) (api.Result, api.FailureReason) {
+	return api.Failure, api.ReasonConnectionFailed
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+// keepAlive returns the net.Dialer KeepAlive for opts, negative to disable keepalive.
+func (opts *Options) keepAlive() time.Duration {
+	if !opts.KeepAlive {
+		return -1
+	}
+	return opts.KeepAlivePeriod
+}
+
+func dial(addr string, timeout time.Duration, opts *Options) (net.Conn, error) {
+	d := &net.Dialer{Timeout: timeout, KeepAlive: opts.keepAlive()}
+	var forward proxy.Dialer = d
+	if opts.LocalPorts != nil {
+		forward = opts.LocalPorts.Dialer(d)
+	}
+	if opts.SOCKS5 == nil {
+		return forward.Dial("tcp", addr)
+	}
+	dialer, err := opts.SOCKS5.Dialer(forward)
 	if err != nil {
-		klog.Errorf("Unexpected error closing TCP probe socket: %v (%#v)", err, err)
+		return nil, fmt.Errorf("failed to configure %s: %w", opts.SOCKS5, err)
+	}
+	// like net.Dialer.Timeout, a zero timeout means no timeout
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
-	return api.Success, "", nil
+	return dialer.DialContext(ctx, "tcp", addr)
 }
@@ -0,0 +1,81 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tcp
+
+import (
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestDial_KeepAlive(t *testing.T) {
+	server, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer server.Close()
+	go func() {
+		for {
+			conn, err := server.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	tests := map[string]struct {
+		opts      Options
+		keepAlive int
+		idle      int
+	}{
+		"off by default": {Options{}, 0, 0},
+		"period":         {Options{KeepAlive: true, KeepAlivePeriod: 7 * time.Second}, 1, 7},
+		"default period": {Options{KeepAlive: true}, 1, 15},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			conn, err := dial(server.Addr().String(), 5*time.Second, &tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer conn.Close()
+			raw, err := conn.(*net.TCPConn).SyscallConn()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var keepAlive, idle int
+			var sockErr error
+			err = raw.Control(func(fd uintptr) {
+				keepAlive, sockErr = syscall.GetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_KEEPALIVE)
+				if sockErr == nil && tt.idle > 0 {
+					idle, sockErr = syscall.GetsockoptInt(int(fd), syscall.IPPROTO_TCP, syscall.TCP_KEEPIDLE)
+				}
+			})
+			if err != nil || sockErr != nil {
+				t.Fatalf("unexpected error: %v, %v", err, sockErr)
+			}
+			if keepAlive != tt.keepAlive {
+				t.Errorf("expected SO_KEEPALIVE %d, get %d", tt.keepAlive, keepAlive)
+			}
+			if idle != tt.idle {
+				t.Errorf("expected TCP_KEEPIDLE %d, get %d", tt.idle, idle)
+			}
+		})
+	}
+}
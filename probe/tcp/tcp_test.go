@@ -17,14 +17,25 @@ limitations under the License.
 package tcp
 
 import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
+	"strings"
+	"syscall"
 	"testing"
 	"time"
 
 	api "kmodules.xyz/prober/api"
+
+	"golang.org/x/net/dns/dnsmessage"
 )
 
 func TestTcpHealthChecker(t *testing.T) {
@@ -66,3 +77,671 @@ func TestTcpHealthChecker(t *testing.T) {
 		}
 	}
 }
+
+func TestTcpTargetProber(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	tHost, tPortStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	tPort, err := strconv.Atoi(tPortStr)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	prober := NewTargetProber(New())
+	status, _, err := prober.Probe(context.Background(), api.Target{Host: tHost, Port: tPort, Timeout: time.Second})
+	if status != api.Success || err != nil {
+		t.Errorf("expected success, got status=%v err=%v", status, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	status, _, err = prober.Probe(ctx, api.Target{Host: tHost, Port: tPort, Timeout: time.Second})
+	if status != api.Unknown || err == nil {
+		t.Errorf("expected unknown for cancelled context, got status=%v err=%v", status, err)
+	}
+}
+
+// startSOCKS5Server starts a minimal SOCKS5 proxy supporting CONNECT to IPv4 addresses without authentication.
+func startSOCKS5Server(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	handle := func(c net.Conn) error {
+		defer c.Close()
+		buf := make([]byte, 256)
+		// greeting: VER NMETHODS METHODS...
+		if _, err := io.ReadFull(c, buf[:2]); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(c, buf[:buf[1]]); err != nil {
+			return err
+		}
+		if _, err := c.Write([]byte{5, 0}); err != nil {
+			return err
+		}
+		// request: VER CMD RSV ATYP DST.ADDR DST.PORT
+		if _, err := io.ReadFull(c, buf[:10]); err != nil {
+			return err
+		}
+		if buf[3] != 1 {
+			return fmt.Errorf("unsupported address type %d", buf[3])
+		}
+		addr := net.JoinHostPort(net.IP(buf[4:8]).String(), strconv.Itoa(int(buf[8])<<8|int(buf[9])))
+		upstream, err := net.Dial("tcp", addr)
+		if err != nil {
+			_, err = c.Write([]byte{5, 5, 0, 1, 0, 0, 0, 0, 0, 0})
+			return err
+		}
+		defer upstream.Close()
+		_, err = c.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+		return err
+	}
+	go func() {
+		for {
+			c, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() { _ = handle(c) }()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestTcpHealthChecker_SOCKS5(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	tHost, tPortStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	tPort, err := strconv.Atoi(tPortStr)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// nothing listens on the proxy address, so the probe must fail even though the target is up
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	proxyAddr := l.Addr().String()
+	l.Close()
+
+	prober := NewWithOptions(Options{SOCKS5: &api.SOCKS5Proxy{Address: proxyAddr}})
+	status, output, err := prober.Probe(tHost, tPort, 1*time.Second)
+	if status != api.Failure || err != nil {
+		t.Errorf("expected failure through an unreachable proxy, got status=%v err=%v", status, err)
+	}
+	if !strings.Contains(output, proxyAddr) {
+		t.Errorf("expected output to mention the proxy %s, got %q", proxyAddr, output)
+	}
+
+	// a zero timeout means no timeout, also when dialing through the proxy
+	prober = NewWithOptions(Options{SOCKS5: &api.SOCKS5Proxy{Address: startSOCKS5Server(t)}})
+	status, output, err = prober.Probe(tHost, tPort, 0)
+	if status != api.Success || err != nil {
+		t.Errorf("expected success through the proxy without a timeout, got status=%v output=%q err=%v", status, output, err)
+	}
+}
+
+func TestTcpHealthChecker_ProbeTimed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	tHost, tPortStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	tPort, err := strconv.Atoi(tPortStr)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	prober, ok := New().(TimedProber)
+	if !ok {
+		t.Fatalf("expected the default prober to implement TimedProber")
+	}
+	res, err := prober.ProbeTimed(tHost, tPort, 1*time.Second)
+	if res.Result != api.Success || err != nil {
+		t.Errorf("expected success, got status=%v err=%v", res.Result, err)
+	}
+	if res.ConnectDuration <= 0 || res.ConnectDuration > time.Second {
+		t.Errorf("expected a connect duration within the timeout, got %v", res.ConnectDuration)
+	}
+
+	res, err = prober.ProbeTimed(tHost, -1, 1*time.Second)
+	if res.Result != api.Failure || err != nil {
+		t.Errorf("expected failure, got status=%v err=%v", res.Result, err)
+	}
+}
+
+func TestTcpHealthChecker_Invert(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	tHost, tPortStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	tPort, err := strconv.Atoi(tPortStr)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, closedPortStr, _ := net.SplitHostPort(l.Addr().String())
+	closedPort, _ := strconv.Atoi(closedPortStr)
+	l.Close()
+
+	tests := []struct {
+		host           string
+		port           int
+		expectedStatus api.Result
+	}{
+		// A connection is made, but the target is expected to be down
+		{tHost, tPort, api.Failure},
+		// The connection is refused, as expected
+		{"127.0.0.1", closedPort, api.Success},
+	}
+
+	prober := NewWithOptions(Options{Invert: true})
+	for i, tt := range tests {
+		status, _, err := prober.Probe(tt.host, tt.port, 1*time.Second)
+		if status != tt.expectedStatus {
+			t.Errorf("#%d: expected status=%v, get=%v", i, tt.expectedStatus, status)
+		}
+		if err != nil {
+			t.Errorf("#%d: unexpected error: %v", i, err)
+		}
+	}
+}
+
+func TestTcpHealthChecker_TLS(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	tlsServer := httptest.NewTLSServer(handler)
+	defer tlsServer.Close()
+	plainServer := httptest.NewServer(handler)
+	defer plainServer.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(tlsServer.Certificate())
+
+	tests := []struct {
+		name           string
+		addr           string
+		config         *tls.Config
+		expectedStatus api.Result
+		expectedOutput string
+	}{
+		{"insecure", tlsServer.Listener.Addr().String(), &tls.Config{InsecureSkipVerify: true}, api.Success, "TLS 1.3, TLS_"},
+		{"verified server name", tlsServer.Listener.Addr().String(), &tls.Config{RootCAs: roots, ServerName: "example.com"}, api.Success, "TLS 1.3, TLS_"},
+		{"unknown authority", tlsServer.Listener.Addr().String(), nil, api.Failure, "TLS handshake failed"},
+		{"wrong server name", tlsServer.Listener.Addr().String(), &tls.Config{RootCAs: roots, ServerName: "example.org"}, api.Failure, "TLS handshake failed"},
+		{"plain server", plainServer.Listener.Addr().String(), &tls.Config{InsecureSkipVerify: true}, api.Failure, "TLS handshake failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, portStr, err := net.SplitHostPort(tt.addr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			port, _ := strconv.Atoi(portStr)
+			prober := NewWithOptions(Options{TLS: true, TLSConfig: tt.config})
+			status, output, err := prober.Probe(host, port, 5*time.Second)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if status != tt.expectedStatus {
+				t.Errorf("expected status=%v, get=%v", tt.expectedStatus, status)
+			}
+			if !strings.HasPrefix(output, tt.expectedOutput) {
+				t.Errorf("expected output to start with %q, got %q", tt.expectedOutput, output)
+			}
+		})
+	}
+
+	// a zero timeout does not limit the handshake, as it does not limit the connect
+	host, portStr, _ := net.SplitHostPort(tlsServer.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	status, output, err := NewWithOptions(Options{TLS: true, TLSConfig: &tls.Config{InsecureSkipVerify: true}}).Probe(host, port, 0)
+	if err != nil || status != api.Success {
+		t.Errorf("expected success without a timeout, get status=%v, output=%q, err=%v", status, output, err)
+	}
+}
+
+func TestTcpHealthChecker_MaxRejectLatency(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, closedPortStr, _ := net.SplitHostPort(l.Addr().String())
+	closedPort, _ := strconv.Atoi(closedPortStr)
+	l.Close()
+
+	open := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer open.Close()
+	_, openPortStr, _ := net.SplitHostPort(open.Listener.Addr().String())
+	openPort, _ := strconv.Atoi(openPortStr)
+
+	tests := []struct {
+		name           string
+		host           string
+		port           int
+		maxLatency     time.Duration
+		expectedStatus api.Result
+		expectedOutput string
+	}{
+		{"fast reject", "127.0.0.1", closedPort, time.Second, api.Success, "fast reject: rejected after"},
+		{"slow reject", "127.0.0.1", closedPort, time.Nanosecond, api.Failure, "slow reject: rejected after"},
+		{"open", "127.0.0.1", openPort, time.Second, api.Failure, "but the target is expected to be down"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prober := NewWithOptions(Options{Invert: true, MaxRejectLatency: tt.maxLatency})
+			status, output, err := prober.Probe(tt.host, tt.port, 200*time.Millisecond)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if status != tt.expectedStatus {
+				t.Errorf("expected status=%v, get=%v (%s)", tt.expectedStatus, status, output)
+			}
+			if !strings.Contains(output, tt.expectedOutput) {
+				t.Errorf("expected output to contain %q, got %q", tt.expectedOutput, output)
+			}
+		})
+	}
+}
+
+func TestRejectResult_SilentDrop(t *testing.T) {
+	// A silent drop can not be reproduced reliably against a real network, so feed the dial timeout directly.
+	err := &net.OpError{Op: "dial", Net: "tcp", Err: context.DeadlineExceeded}
+	res := rejectResult(err, time.Second, 100*time.Millisecond)
+	if res.Result != api.Failure || !strings.HasPrefix(res.Output, "silent drop: no response after 1s") {
+		t.Errorf("expected silent drop failure, got %v %q", res.Result, res.Output)
+	}
+}
+
+func TestTcpHealthChecker_TimeoutResult(t *testing.T) {
+	// a listener that accepts connections but never speaks TLS makes the handshake time out
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+	refused, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	refusedAddr := refused.Addr().String()
+	refused.Close()
+
+	tests := []struct {
+		name           string
+		addr           string
+		timeoutResult  api.Result
+		expectedStatus api.Result
+	}{
+		{"timeout defaults to failure", ln.Addr().String(), "", api.Failure},
+		{"timeout as warning", ln.Addr().String(), api.Warning, api.Warning},
+		{"refused still fails", refusedAddr, api.Warning, api.Failure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host, portStr, err := net.SplitHostPort(tt.addr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			port, _ := strconv.Atoi(portStr)
+			prober := NewWithOptions(Options{TLS: true, TimeoutResult: tt.timeoutResult})
+			status, _, err := prober.Probe(host, port, 100*time.Millisecond)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if status != tt.expectedStatus {
+				t.Errorf("expected status=%v, get=%v", tt.expectedStatus, status)
+			}
+		})
+	}
+}
+
+func TestTcpHealthChecker_ClassifyError(t *testing.T) {
+	refused, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	host, portStr, _ := net.SplitHostPort(refused.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+	refused.Close()
+
+	var classified error
+	prober := NewWithOptions(Options{ClassifyError: func(err error) (api.Result, api.FailureReason) {
+		classified = err
+		return api.Warning, "ConnectionRefused"
+	}})
+	status, output, err := prober.Probe(host, port, time.Second)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if status != api.Warning {
+		t.Errorf("expected status=%v, get=%v", api.Warning, status)
+	}
+	if !errors.Is(classified, syscall.ECONNREFUSED) || !strings.Contains(output, "connection refused") {
+		t.Errorf("expected the classifier to get the connect error, get %v, output=%q", classified, output)
+	}
+}
+
+func TestTcpHealthChecker_LocalPorts(t *testing.T) {
+	remotePorts := make(chan int, 10)
+	server, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer server.Close()
+	go func() {
+		for {
+			conn, err := server.Accept()
+			if err != nil {
+				return
+			}
+			remotePorts <- conn.RemoteAddr().(*net.TCPAddr).Port
+			conn.Close()
+		}
+	}()
+	host, portStr, _ := net.SplitHostPort(server.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	// a listener occupies the first port of the range
+	busy, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer busy.Close()
+	busyPort := busy.Addr().(*net.TCPAddr).Port
+	freePort := busyPort + 1
+	free, err := net.Listen("tcp", ":"+strconv.Itoa(freePort))
+	if err != nil {
+		t.Skipf("port %d next to the busy port is not free: %v", freePort, err)
+	}
+	free.Close()
+
+	status, output, err := NewWithOptions(Options{LocalPorts: &api.LocalPortRange{First: busyPort}}).Probe(host, port, 5*time.Second)
+	if !errors.Is(err, api.ErrLocalPortsInUse) || status != api.Unknown {
+		t.Errorf("expected Unknown with ErrLocalPortsInUse, get status=%v, err=%v, output=%q", status, err, output)
+	}
+
+	ports := &api.LocalPortRange{First: busyPort, Last: freePort}
+	status, output, err = NewWithOptions(Options{LocalPorts: ports}).Probe(host, port, 5*time.Second)
+	if err != nil || status != api.Success {
+		t.Fatalf("expected success, get status=%v, err=%v, output=%q", status, err, output)
+	}
+	if remote := <-remotePorts; remote != freePort {
+		t.Errorf("expected the connection from local port %d, get %d", freePort, remote)
+	}
+}
+
+// startResolver serves A records of name over UDP and returns a resolver that queries it.
+// Other names do not exist.
+func startResolver(t *testing.T, name string, addrs ...[4]byte) *net.Resolver {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	go func() {
+		buf := make([]byte, 512)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var msg dnsmessage.Message
+			if err := msg.Unpack(buf[:n]); err != nil {
+				continue
+			}
+			msg.Header.Response = true
+			for _, q := range msg.Questions {
+				if q.Name.String() != name+"." {
+					msg.Header.RCode = dnsmessage.RCodeNameError
+					continue
+				}
+				if q.Type != dnsmessage.TypeA {
+					continue
+				}
+				for _, a := range addrs {
+					msg.Answers = append(msg.Answers, dnsmessage.Resource{
+						Header: dnsmessage.ResourceHeader{Name: q.Name, Type: q.Type, Class: dnsmessage.ClassINET},
+						Body:   &dnsmessage.AResource{A: a},
+					})
+				}
+			}
+			if resp, err := msg.Pack(); err == nil {
+				_, _ = conn.WriteTo(resp, addr)
+			}
+		}
+	}()
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "udp", conn.LocalAddr().String())
+		},
+	}
+}
+
+func TestTcpHealthChecker_ProbeAllAddresses(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	resolver := startResolver(t, "healthy.test", [4]byte{127, 0, 0, 1})
+	status, output, err := NewWithOptions(Options{ProbeAllAddresses: true, Resolver: resolver}).Probe("healthy.test", port, 5*time.Second)
+	if err != nil || status != api.Success {
+		t.Errorf("expected success, get status=%v, err=%v, output=%q", status, err, output)
+	}
+	if expected := "127.0.0.1:" + strconv.Itoa(port) + ": success"; output != expected {
+		t.Errorf("expected output=%q, get=%q", expected, output)
+	}
+
+	// nothing listens on 127.0.0.2
+	resolver = startResolver(t, "partial.test", [4]byte{127, 0, 0, 1}, [4]byte{127, 0, 0, 2})
+	status, output, err = NewWithOptions(Options{ProbeAllAddresses: true, Resolver: resolver}).Probe("partial.test", port, 5*time.Second)
+	if err != nil || status != api.Failure {
+		t.Errorf("expected failure, get status=%v, err=%v, output=%q", status, err, output)
+	}
+	lines := strings.Split(output, "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a line per address, get output=%q", output)
+	}
+	if !strings.HasPrefix(lines[1], "127.0.0.2:"+strconv.Itoa(port)+": failure, dial tcp") {
+		t.Errorf("expected the second address to fail, get output=%q", output)
+	}
+
+	status, output, err = NewWithOptions(Options{ProbeAllAddresses: true, Resolver: resolver}).Probe("missing.test", port, 5*time.Second)
+	if err != nil || status != api.Failure || !strings.HasPrefix(output, "failed to resolve missing.test") {
+		t.Errorf("expected a resolve failure, get status=%v, err=%v, output=%q", status, err, output)
+	}
+
+	// a zero timeout does not limit the addresses
+	resolver = startResolver(t, "healthy.test", [4]byte{127, 0, 0, 1})
+	status, output, err = NewWithOptions(Options{ProbeAllAddresses: true, Resolver: resolver}).Probe("healthy.test", port, 0)
+	if err != nil || status != api.Success {
+		t.Errorf("expected success without a timeout, get status=%v, err=%v, output=%q", status, err, output)
+	}
+
+	// a proxy that never answers uses up the timeout on the first address, so the second one is not probed,
+	// which an inverted probe cannot count as closed
+	silent, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer silent.Close()
+	go func() {
+		for {
+			c, err := silent.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				_, _ = io.Copy(io.Discard, c)
+			}()
+		}
+	}()
+	resolver = startResolver(t, "partial.test", [4]byte{127, 0, 0, 1}, [4]byte{127, 0, 0, 2})
+	opts := Options{ProbeAllAddresses: true, Resolver: resolver, Invert: true, SOCKS5: &api.SOCKS5Proxy{Address: silent.Addr().String()}}
+	status, output, err = NewWithOptions(opts).Probe("partial.test", port, 200*time.Millisecond)
+	if err != nil || status != api.Unknown {
+		t.Errorf("expected unknown, get status=%v, err=%v, output=%q", status, err, output)
+	}
+	if expected := "127.0.0.2:" + strconv.Itoa(port) + ": not probed, the probe timed out"; !strings.HasSuffix(output, expected) {
+		t.Errorf("expected output to end with %q, get %q", expected, output)
+	}
+}
+
+// startRedis serves the AUTH and PING commands of the Redis protocol, requiring password if it is not empty.
+func startRedis(t *testing.T, password string) int {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				authenticated := password == ""
+				for {
+					var n int
+					if _, err := fmt.Fscanf(r, "*%d\r\n", &n); err != nil {
+						return
+					}
+					args := make([]string, n)
+					for i := range args {
+						var size int
+						if _, err := fmt.Fscanf(r, "$%d\r\n", &size); err != nil {
+							return
+						}
+						buf := make([]byte, size+2)
+						if _, err := io.ReadFull(r, buf); err != nil {
+							return
+						}
+						args[i] = string(buf[:size])
+					}
+					switch {
+					case args[0] == "AUTH" && args[len(args)-1] == password:
+						authenticated = true
+						fmt.Fprint(conn, "+OK\r\n")
+					case args[0] == "AUTH":
+						fmt.Fprint(conn, "-WRONGPASS invalid username-password pair or user is disabled.\r\n")
+					case !authenticated:
+						fmt.Fprint(conn, "-NOAUTH Authentication required.\r\n")
+					default:
+						fmt.Fprint(conn, "+PONG\r\n")
+					}
+				}
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestTcpHealthChecker_Redis(t *testing.T) {
+	port := startRedis(t, "s3cret")
+
+	tests := map[string]struct {
+		credentials *Credentials
+		status      api.Result
+		output      string
+	}{
+		"authenticated": {&Credentials{Password: "s3cret"}, api.Success, "redis: PONG"},
+		"acl user":      {&Credentials{Username: "default", Password: "s3cret"}, api.Success, "redis: PONG"},
+		"wrong password": {&Credentials{Password: "wrong"}, api.Failure,
+			"redis protocol error: -WRONGPASS invalid username-password pair or user is disabled."},
+		"no credentials": {nil, api.Failure, "redis protocol error: -NOAUTH Authentication required."},
+	}
+	for name, tt := range tests {
+		status, output, err := NewWithOptions(Options{Protocol: ProtocolRedis, Credentials: tt.credentials}).Probe("127.0.0.1", port, 5*time.Second)
+		if err != nil || status != tt.status || output != tt.output {
+			t.Errorf("%s: expected status=%v, output=%q, get status=%v, output=%q, err=%v", name, tt.status, tt.output, status, output, err)
+		}
+		if strings.Contains(output, "wrong") || strings.Contains(output, "s3cret") {
+			t.Errorf("%s: expected the password to be redacted, get output=%q", name, output)
+		}
+	}
+
+	// a server that accepts but never answers times out
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer ln.Close()
+	status, output, err := NewWithOptions(Options{Protocol: ProtocolRedis, TimeoutResult: api.Warning}).Probe("127.0.0.1", ln.Addr().(*net.TCPAddr).Port, 200*time.Millisecond)
+	if err != nil || status != api.Warning || !strings.HasPrefix(output, "redis check failed: ") {
+		t.Errorf("expected a timeout warning, get status=%v, output=%q, err=%v", status, output, err)
+	}
+
+	if s := (&Credentials{Username: "probe", Password: "s3cret"}).String(); strings.Contains(s, "s3cret") {
+		t.Errorf("expected the password to be redacted, get %q", s)
+	}
+}
+
+func TestTcpTargetProber_Protocol(t *testing.T) {
+	port := startRedis(t, "s3cret")
+
+	tests := map[string]struct {
+		opts    Options
+		target  api.Target
+		timeout time.Duration
+		status  api.Result
+		output  string
+	}{
+		"protocol of the target": {Options{}, api.Target{Protocol: "redis", Password: "s3cret"}, 5 * time.Second, api.Success, "redis: PONG"},
+		"credentials of the target": {Options{Protocol: ProtocolRedis, Credentials: &Credentials{Password: "wrong"}},
+			api.Target{Password: "s3cret"}, 5 * time.Second, api.Success, "redis: PONG"},
+		"credentials of the options": {Options{Credentials: &Credentials{Password: "s3cret"}},
+			api.Target{Protocol: "redis"}, 5 * time.Second, api.Success, "redis: PONG"},
+		"no protocol": {Options{}, api.Target{}, 5 * time.Second, api.Success, ""},
+		// a zero timeout does not limit the protocol check
+		"no timeout": {Options{}, api.Target{Protocol: "redis", Password: "s3cret"}, 0, api.Success, "redis: PONG"},
+	}
+	for name, tt := range tests {
+		tt.target.Host, tt.target.Port, tt.target.Timeout = "127.0.0.1", port, tt.timeout
+		status, output, err := NewTargetProber(NewWithOptions(tt.opts)).Probe(context.Background(), tt.target)
+		if err != nil || status != tt.status || output != tt.output {
+			t.Errorf("%s: expected status=%v, output=%q, get status=%v, output=%q, err=%v", name, tt.status, tt.output, status, output, err)
+		}
+	}
+}